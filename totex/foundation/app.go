@@ -1,14 +1,29 @@
 package foundation
 
 import (
+	"cmp"
 	"context"
+	"crypto/subtle"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
 	"sync"
+	"time"
 
 	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/health"
+	"github.com/yourusername/foundation/leader"
 	"github.com/yourusername/foundation/logging"
 	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/redact"
+	"github.com/yourusername/foundation/slo"
 	"github.com/yourusername/foundation/tracing"
+	"github.com/yourusername/foundation/upgrade"
 )
 
 type Span = tracing.Span
@@ -17,6 +32,12 @@ type Logger = logging.Logger
 type Tracer = tracing.Tracer
 type Metrics = metrics.Metrics
 
+// healthCheckTimeout bounds a single run of all health checks
+const healthCheckTimeout = 5 * time.Second
+
+// defaultRollbackTimeout bounds how long a failed Start spends stopping what it started
+const defaultRollbackTimeout = 10 * time.Second
+
 // Server represents a lifecycle-managed server component
 // (e.g., HTTP servers, gRPC servers) that can be started and stopped.
 type Server interface {
@@ -25,6 +46,12 @@ type Server interface {
 	Name() string
 }
 
+// listenerServer is implemented by servers whose listening socket can be
+// supplied by the App, which is required for zero-downtime upgrades
+type listenerServer interface {
+	SetListenFunc(listen func(network, address string) (net.Listener, error))
+}
+
 // App represents the main application with cross-cutting concerns
 type App struct {
 	name       string
@@ -33,28 +60,117 @@ type App struct {
 	tracer     tracing.Tracer
 	metrics    metrics.Metrics
 	connectRPC *connectrpc.Server
+	health     *health.Registry
+	levels     *logging.Levels
+	slo        *slo.Tracker
+	redactor   *redact.Redactor
+	logOutput  io.Closer
+
+	clientConfig    ClientConfig
+	clientDefaults  clientOptions
+	clientTransport *http.Transport
 
-	servers []Server
-	ctx     context.Context
-	cancel  context.CancelFunc
-	mu      sync.Mutex
+	servers           []Server
+	startedServers    []Server
+	components        []Component
+	startedComponents [][]Component
+	rollbackTimeout   time.Duration
+	upgrader          *upgrade.Upgrader
+	elector           *leader.Elector
+	leaderTTL         time.Duration
+	signals           chan os.Signal
+
+	workersMu sync.Mutex
+	workers   []*worker
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	mu     sync.Mutex
+
+	stateMu        sync.Mutex
+	state          State
+	done           chan struct{}
+	subscribers    map[int]chan StateEvent
+	nextSubscriber int
 }
 
 // NewWithConfig returns an App with logger, metrics, tracing, and servers using AppConfig
-func NewWithConfig(name, version string, cfg AppConfig) *App {
+func NewWithConfig(name, version string, cfg AppConfig, opts ...Option) *App {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
-	logger := NewLoggerFromConfig(cfg.Logger)
-	metrics := NewMetricsFromConfig(cfg.Metrics)
-	tracer := NewTracerFromConfig(cfg.Tracer)
+	redactor := newRedactorFromConfig(cfg.Redact)
+	var logOutput io.Closer
+	if o.logger == nil {
+		loggerCfg := cfg.Logger
+		loggerCfg.NoSignalReopen = loggerCfg.NoSignalReopen || cfg.Upgrade.Enabled
+		o.logger, logOutput = newLoggerFromConfig(loggerCfg, logging.WithRedactor(redactor))
+	}
+	if o.metrics == nil {
+		o.metrics = NewMetricsFromConfig(cfg.Metrics)
+	}
+	logger := o.logger.With("service", name, "version", version)
+	var ownTracer *tracing.StandardTracer
+	if o.tracer == nil {
+		o.tracer = newTracerFromConfig(cfg.Tracer, name, logger)
+		ownTracer, _ = o.tracer.(*tracing.StandardTracer)
+	}
+	tracer := tracing.NewRedactingTracer(o.tracer, redactor)
 
 	app := &App{
-		name:    name,
-		version: version,
-		logger:  logger,
-		tracer:  tracer,
-		metrics: metrics,
-		ctx:     ctx,
-		cancel:  cancel,
+		name:      name,
+		version:   version,
+		logger:    logger,
+		tracer:    tracer,
+		metrics:   o.metrics,
+		health:    health.NewRegistry(healthCheckTimeout),
+		redactor:  redactor,
+		ctx:       ctx,
+		logOutput: logOutput,
+		cancel:    cancel,
+
+		rollbackTimeout: cfg.RollbackTimeout,
+		leaderTTL:       cfg.Leader.TTL,
+
+		clientConfig:    cfg.Client,
+		clientDefaults:  newClientDefaults(cfg.Client, logger),
+		clientTransport: newClientTransport(cfg.Client),
+
+		state:       StateCreated,
+		done:        make(chan struct{}),
+		subscribers: make(map[int]chan StateEvent),
+	}
+	app.exportState(StateCreated)
+	if controller, ok := logger.(logging.LevelController); ok {
+		app.levels = controller.Levels()
+	}
+	if app.rollbackTimeout <= 0 {
+		app.rollbackTimeout = defaultRollbackTimeout
+	}
+
+	if cfg.Upgrade.Enabled {
+		upgrader, err := upgrade.New(logger, cfg.Upgrade.Timeout)
+		if err != nil {
+			logger.Error("Failed to initialise upgrader, upgrades disabled", "error", err)
+		} else {
+			app.upgrader = upgrader
+		}
+	}
+
+	if registry, ok := o.metrics.(*metrics.Registry); ok && cfg.Metrics.Runtime {
+		registry.RegisterCollector("runtime", metrics.NewRuntimeCollector(registry))
+		registry.RegisterCollector("process", metrics.NewProcessCollector(registry))
+		metrics.RegisterBuildInfo(registry, name, version)
+	}
+	if registry, ok := o.metrics.(*metrics.Registry); ok && cfg.Metrics.Type == "otlp" {
+		app.AddComponent(newOTLPExporterFromConfig(cfg.Metrics.OTLP, registry, name, version, logger))
+	}
+
+	if backend := createLeaderBackendFromConfig(cfg.Leader, logger); backend != nil {
+		app.SetLeaderBackend(backend)
 	}
 
 	for _, serverCfg := range cfg.Servers {
@@ -64,60 +180,238 @@ func NewWithConfig(name, version string, cfg AppConfig) *App {
 			if serverCfg.Type == "connectrpc" {
 				if connectServer, ok := server.(*connectrpc.Server); ok {
 					app.connectRPC = connectServer
+					connectServer.RegisterHandler("/healthz", app.health.Handler())
+					if serverCfg.AccessLog {
+						connectServer.EnableAccessLog(redactor)
+					}
+					connectServer.EnableInstrumentation(tracer, o.metrics)
+					if app.levels != nil && cfg.Logger.AdminToken != "" {
+						connectServer.RegisterHandler("/admin/loggers", requireToken(cfg.Logger.AdminToken, app.levels.Handler()))
+					}
 				}
 			}
 		}
 	}
 
+	if registry, ok := o.metrics.(*metrics.Registry); ok && cfg.Metrics.Type != "otlp" && cfg.Metrics.Port != "" {
+		// Metrics have a listener of their own so they are not exposed on the public port
+		metricsServer := connectrpc.NewServer("metrics", net.JoinHostPort("", cfg.Metrics.Port), logger)
+		metricsServer.RegisterHandler("/metrics", registry.Handler())
+		app.AddServer(metricsServer)
+	}
+
+	if ownTracer != nil {
+		// A component stops after the servers, so the spans of drained requests are sent
+		app.AddComponent(&tracerComponent{ownTracer})
+	}
+	if len(cfg.SLO.Objectives) > 0 {
+		app.slo = newSLOTrackerFromConfig(cfg.SLO, o.metrics, logger)
+	}
+	if app.slo != nil {
+		app.AddComponent(app.slo)
+		if app.connectRPC != nil {
+			app.connectRPC.AddRPCObserver(app.slo)
+			if cfg.Logger.AdminToken != "" {
+				app.connectRPC.RegisterHandler("/admin/slo", requireToken(cfg.Logger.AdminToken, app.slo.Handler()))
+			}
+		} else {
+			logger.Warn("SLOs configured without a ConnectRPC server, no events will be counted")
+		}
+	}
+
 	return app
 }
 
 // New returns an App with default configuration
-func New(name, version string) *App {
-	return NewWithConfig(name, version, LoadConfigFromEnv())
+func New(name, version string, opts ...Option) *App {
+	return NewWithConfig(name, version, LoadConfigFromEnv(), opts...)
 }
 
-// AddServer adds a server to the app's lifecycle management
-func (a *App) AddServer(server Server) {
+// AddServer adds a server to the app's lifecycle management. Servers can only
+// be added before the app is started.
+func (a *App) AddServer(server Server) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
+	if err := a.requireState("add server to", StateCreated); err != nil {
+		return err
+	}
 	a.servers = append(a.servers, server)
+	return nil
 }
 
-// Start starts all servers
+// Start starts all components in dependency order, then all servers. Start is
+// transactional: if anything fails, whatever was already started is stopped
+// again in reverse order within the rollback timeout.
 func (a *App) Start(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if err := a.guard("start", StateStarting, StateCreated); err != nil {
+		return err
+	}
 	a.logger.Info("Starting app", "name", a.name, "version", a.version)
+	levels, err := sortComponents(a.components)
+	if err != nil {
+		return a.fail(fmt.Errorf("invalid components: %w", err))
+	}
+	started, err := a.startComponents(ctx, levels)
+	if err != nil {
+		return a.fail(err)
+	}
+	a.startedComponents = started
+
 	for _, server := range a.servers {
+		if ls, ok := server.(listenerServer); ok && a.upgrader != nil {
+			ls.SetListenFunc(a.upgrader.Listen)
+		}
 		if err := server.Start(ctx); err != nil {
 			a.logger.Error("Failed to start server", "server", server.Name(), "error", err)
-			return fmt.Errorf("failed to start %s: %w", server.Name(), err)
+			return a.fail(errors.Join(fmt.Errorf("failed to start %s: %w", server.Name(), err), a.rollback(ctx)))
 		}
+		a.startedServers = append(a.startedServers, server)
 		a.logger.Info("Started server", "server", server.Name())
 	}
 	a.logger.Info("All servers started successfully")
+
+	if a.upgrader != nil {
+		if err := a.upgrader.Ready(); err != nil {
+			a.logger.Error("Failed to signal readiness to parent process", "error", err)
+		}
+		a.watchUpgradeSignals()
+	}
+	a.markRunning()
 	return nil
 }
 
-// Stop stops all servers gracefully
+// Stop stops all servers gracefully, then components in reverse dependency
+// order, and finally closes the log file opened from LoggerConfig.Output.
+// The returned error joins every stop failure.
 func (a *App) Stop(ctx context.Context) error {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	if err := a.guard("stop", StateStopping, StateRunning); err != nil {
+		return err
+	}
 	a.logger.Info("Stopping app", "name", a.name, "version", a.version)
 	a.cancel()
-	for i := len(a.servers) - 1; i >= 0; i-- {
-		server := a.servers[i]
+	if a.signals != nil {
+		signal.Stop(a.signals)
+	}
+	if a.upgrader != nil {
+		a.upgrader.Stop()
+	}
+	err := a.stopStarted(ctx)
+	if err != nil {
+		a.logger.Error("App stopped uncleanly", "error", err)
+		a.fail(err)
+	} else {
+		a.logger.Info("All servers stopped")
+		a.transition(StateStopped, nil)
+	}
+	return errors.Join(err, a.closeLogOutput())
+}
+
+// closeLogOutput closes the app's log file, stopping its SIGHUP handler and
+// waiting for pending compression. It runs last so shutdown lines reach the file.
+func (a *App) closeLogOutput() error {
+	if a.logOutput == nil {
+		return nil
+	}
+	if err := a.logOutput.Close(); err != nil {
+		return fmt.Errorf("close log output: %w", err)
+	}
+	return nil
+}
+
+// fail moves the app to StateFailed, releasing its context, and returns err
+func (a *App) fail(err error) error {
+	a.cancel()
+	a.transition(StateFailed, err)
+	return err
+}
+
+// rollback stops everything started so far after a failed Start. It uses its
+// own deadline so a cancelled start context doesn't skip the cleanup.
+func (a *App) rollback(ctx context.Context) error {
+	a.logger.Warn("Rolling back app start", "timeout", a.rollbackTimeout)
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.rollbackTimeout)
+	defer cancel()
+	if err := a.stopStarted(ctx); err != nil {
+		return fmt.Errorf("rollback: %w", err)
+	}
+	return nil
+}
+
+// stopStarted stops started servers in reverse order, waits for workers, then
+// stops started components and closes the idle connections of clients
+func (a *App) stopStarted(ctx context.Context) error {
+	var errs []error
+	for i := len(a.startedServers) - 1; i >= 0; i-- {
+		server := a.startedServers[i]
 		if err := server.Stop(ctx); err != nil {
 			a.logger.Error("Failed to stop server", "server", server.Name(), "error", err)
+			errs = append(errs, fmt.Errorf("failed to stop %s: %w", server.Name(), err))
 		} else {
 			a.logger.Info("Stopped server", "server", server.Name())
 		}
 	}
-	a.logger.Info("All servers stopped")
-	return nil
+	a.startedServers = nil
+
+	if err := a.waitWorkers(ctx); err != nil {
+		a.logger.Error("Failed to stop workers", "error", err)
+		errs = append(errs, err)
+	}
+
+	if err := a.stopComponents(ctx, a.startedComponents); err != nil {
+		errs = append(errs, err)
+	}
+	a.startedComponents = nil
+
+	// Components may still have called other services while stopping
+	a.clientTransport.CloseIdleConnections()
+	return errors.Join(errs...)
+}
+
+// Upgrade hands the listening sockets to a new copy of the binary and waits
+// for it to become ready. Once it succeeds, Upgraded is closed and the caller
+// should Stop the app so in-flight requests drain before exiting.
+func (a *App) Upgrade() error {
+	if a.upgrader == nil {
+		return fmt.Errorf("upgrades are not enabled")
+	}
+	return a.upgrader.Upgrade()
+}
+
+// Upgraded is closed once a new process has taken over the listeners.
+// It is nil, and so blocks forever, when upgrades are not enabled.
+func (a *App) Upgraded() <-chan struct{} {
+	if a.upgrader == nil {
+		return nil
+	}
+	return a.upgrader.Exit()
+}
+
+// watchUpgradeSignals triggers an upgrade whenever one of upgrade.Signals arrives
+func (a *App) watchUpgradeSignals() {
+	if len(upgrade.Signals) == 0 {
+		return
+	}
+	a.signals = make(chan os.Signal, 1)
+	signal.Notify(a.signals, upgrade.Signals...)
+	go func() {
+		for {
+			select {
+			case <-a.ctx.Done():
+				return
+			case sig := <-a.signals:
+				a.logger.Info("Upgrade signal received", "signal", sig)
+				if err := a.upgrader.Upgrade(); err != nil {
+					a.logger.Error("Upgrade failed, continuing to serve", "error", err)
+				}
+			}
+		}
+	}()
 }
 
 // Logger returns the logger
@@ -129,6 +423,22 @@ func (a *App) Metrics() metrics.Metrics { return a.metrics }
 // Tracer returns the tracer
 func (a *App) Tracer() tracing.Tracer { return a.tracer }
 
+// LogLevels returns the registry of runtime logger levels, served at
+// /admin/loggers on the ConnectRPC server when LoggerConfig.AdminToken is set;
+// nil if the logger does not support it
+func (a *App) LogLevels() *logging.Levels { return a.levels }
+
+// Redactor returns the redactor masking sensitive values in logs, spans and access logs
+func (a *App) Redactor() *redact.Redactor { return a.redactor }
+
+// Health returns the health check registry, served at /healthz on the ConnectRPC server
+func (a *App) Health() *health.Registry { return a.health }
+
+// SLO returns the tracker of the configured service level objectives, served
+// at /admin/slo on the ConnectRPC server when LoggerConfig.AdminToken is set;
+// nil if none are configured
+func (a *App) SLO() *slo.Tracker { return a.slo }
+
 // Name returns the app name
 func (a *App) Name() string { return a.name }
 
@@ -169,19 +479,266 @@ func createServerFromConfig(cfg ServerConfig, logger logging.Logger) Server {
 	}
 }
 
-// NewLoggerFromConfig creates a logger using LoggerConfig
-func NewLoggerFromConfig(cfg LoggerConfig) logging.Logger {
-	return logging.NewSlogLogger("configured-logger", cfg.Level, cfg.Format, cfg.Output)
+// createLeaderBackendFromConfig creates the leader election backend based on the configuration
+func createLeaderBackendFromConfig(cfg LeaderConfig, logger logging.Logger) leader.Backend {
+	switch cfg.Backend {
+	case "":
+		return nil
+	case "file":
+		backend, err := leader.NewFileBackend(cfg.Dir)
+		if err != nil {
+			logger.Error("Failed to create file leader backend", "dir", cfg.Dir, "error", err)
+			return nil
+		}
+		return backend
+	default:
+		logger.Error("Unknown leader backend", "backend", cfg.Backend)
+		return nil
+	}
 }
 
-// NewMetricsFromConfig creates metrics using MetricsConfig
+// NewLoggerFromConfig creates a logger using LoggerConfig. A log file it
+// opens stays open for the life of the process.
+func NewLoggerFromConfig(cfg LoggerConfig, opts ...logging.Option) logging.Logger {
+	logger, _ := newLoggerFromConfig(cfg, opts...)
+	return logger
+}
+
+// newLoggerFromConfig creates a logger using LoggerConfig, returning the log
+// file it writes to, or nil for stdout and stderr
+func newLoggerFromConfig(cfg LoggerConfig, opts ...logging.Option) (logging.Logger, io.Closer) {
+	output, err := openLoggerOutput(cfg)
+	if err != nil {
+		logger := createLogger(cfg, os.Stderr, opts)
+		logger.Error("Failed to open log output, using stderr", "output", cfg.Output, "error", err)
+		return logger, nil
+	}
+	if file, ok := output.(*logging.RotatingFile); ok {
+		return createLogger(cfg, output, opts), file
+	}
+	return createLogger(cfg, output, opts), nil
+}
+
+// createLogger creates the logger backend selected by LoggerConfig.Type
+func createLogger(cfg LoggerConfig, output io.Writer, opts []logging.Option) logging.Logger {
+	opts = append([]logging.Option{logging.WithSampling(logging.SamplingOptions{
+		Interval:   cfg.SamplingInterval,
+		First:      cfg.SamplingFirst,
+		Thereafter: cfg.SamplingThereafter,
+	}), logging.WithBaggageKeys(cfg.BaggageKeys...)}, opts...)
+	switch cfg.Type {
+	case "logrus":
+		return logging.NewLogrusLogger("configured-logger", cfg.Level, cfg.Format, output, opts...)
+	case "zap":
+		return logging.NewZapLogger("configured-logger", cfg.Level, cfg.Format, output, opts...)
+	case "", "slog":
+		return logging.NewSlogLoggerWithWriter("configured-logger", cfg.Level, cfg.Format, output, opts...)
+	default:
+		logger := logging.NewSlogLoggerWithWriter("configured-logger", cfg.Level, cfg.Format, output, opts...)
+		logger.Error("Unknown logger type, using slog", "type", cfg.Type)
+		return logger
+	}
+}
+
+// openLoggerOutput opens the configured log output; files rotate and, unless
+// cfg.NoSignalReopen is set, are reopened on SIGHUP so external logrotate
+// setups work too
+func openLoggerOutput(cfg LoggerConfig) (io.Writer, error) {
+	output, err := logging.OpenOutput(logging.OutputOptions{
+		Path:       cfg.Output,
+		MaxSize:    int64(cfg.MaxSizeMB) * 1024 * 1024,
+		MaxAge:     cfg.MaxAge,
+		MaxBackups: cfg.MaxBackups,
+		Compress:   cfg.Compress,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if file, ok := output.(*logging.RotatingFile); ok && !cfg.NoSignalReopen {
+		file.ReopenOnSignal()
+	}
+	return output, nil
+}
+
+// requireToken serves only requests carrying token as their bearer token
+func requireToken(token string, next http.Handler) http.Handler {
+	want := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// newRedactorFromConfig creates the redactor for RedactConfig, using redact.DefaultKeys if no keys are set
+func newRedactorFromConfig(cfg RedactConfig) *redact.Redactor {
+	if len(cfg.Keys) == 0 {
+		return redact.Default()
+	}
+	return redact.New(cfg.Keys...)
+}
+
+// NewMetricsFromConfig creates metrics using MetricsConfig. Registries are
+// new for every call, so Apps in one process do not share series; pass
+// metrics.DefaultRegistry to WithMetrics to use the package-level instruments.
 func NewMetricsFromConfig(cfg MetricsConfig) metrics.Metrics {
-	// For now, return default metrics. Extend for prometheus/statsd as needed.
-	return metrics.NewDefaultMetrics()
+	switch cfg.Type {
+	case "prometheus", "otlp":
+		registry := metrics.NewRegistry()
+		registry.SetMaxSeries(cfg.MaxSeries)
+		return registry
+	default:
+		return metrics.NewDefaultMetrics()
+	}
+}
+
+// newOTLPExporterFromConfig creates the component pushing registry for METRICS_TYPE=otlp
+func newOTLPExporterFromConfig(cfg OTLPConfig, registry *metrics.Registry, name, version string, logger logging.Logger) *metrics.OTLPExporter {
+	opts := []metrics.OTLPOption{
+		metrics.WithOTLPProtocol(metrics.OTLPProtocol(cfg.Protocol)),
+		metrics.WithOTLPHeaders(cfg.Headers),
+		metrics.WithResource(map[string]string{"service.name": name, "service.version": version}),
+		metrics.WithOTLPErrorHandler(func(err error) {
+			logger.Error("Failed to export metrics", "endpoint", cfg.Endpoint, "error", err)
+		}),
+	}
+	if cfg.Interval > 0 {
+		opts = append(opts, metrics.WithOTLPInterval(cfg.Interval))
+	}
+	temporality, err := metrics.ParseTemporality(cfg.Temporality)
+	if err != nil {
+		logger.Error("Invalid OTLP temporality, using cumulative", "error", err)
+	}
+	opts = append(opts, metrics.WithTemporality(temporality))
+	if cfg.Histogram == "exponential" {
+		opts = append(opts, metrics.WithExponentialHistograms())
+	}
+	return metrics.NewOTLPExporter(registry, cfg.Endpoint, opts...)
+}
+
+// newSLOTrackerFromConfig creates the tracker of the configured objectives,
+// skipping invalid ones; nil if none are valid
+func newSLOTrackerFromConfig(cfg SLOConfig, m metrics.Metrics, logger logging.Logger) *slo.Tracker {
+	var objectives []slo.Objective
+	seen := make(map[string]bool)
+	for _, s := range cfg.Objectives {
+		objective, err := slo.ParseObjective(s)
+		if err != nil {
+			logger.Error("Invalid SLO objective, ignoring", "objective", s, "error", err)
+			continue
+		}
+		if seen[objective.Procedure] {
+			logger.Error("Duplicate SLO objective, ignoring", "objective", s)
+			continue
+		}
+		seen[objective.Procedure] = true
+		objectives = append(objectives, objective)
+	}
+	if len(objectives) == 0 {
+		return nil
+	}
+	var opts []slo.Option
+	if cfg.Interval > 0 {
+		opts = append(opts, slo.WithInterval(cfg.Interval))
+	}
+	tracker, err := slo.NewTracker(objectives, m, opts...)
+	if err != nil {
+		logger.Error("Failed to create SLO tracker", "error", err)
+		return nil
+	}
+	return tracker
 }
 
 // NewTracerFromConfig creates tracer using TracerConfig
 func NewTracerFromConfig(cfg TracerConfig) tracing.Tracer {
-	// For now, return default tracer. Extend for jaeger/zipkin as needed.
-	return tracing.NewDefaultTracer()
+	return newTracerFromConfig(cfg, "unknown_service", logging.NewDefaultSlogLogger())
+}
+
+// newTracerFromConfig creates the tracer of service, logging export failures
+// to logger. Unknown types and exporters that cannot be created fall back to
+// the noop tracer.
+func newTracerFromConfig(cfg TracerConfig, service string, logger logging.Logger) tracing.Tracer {
+	exporterOpts := []tracing.ExporterOption{
+		tracing.WithQueueSize(cfg.QueueSize),
+		tracing.WithBatchSize(cfg.BatchSize),
+		tracing.WithBatchInterval(cfg.BatchInterval),
+		tracing.WithExportErrorHandler(func(err error) {
+			logger.Error("Failed to export spans", "tracer", cfg.Type, "endpoint", cfg.Endpoint, "error", err)
+		}),
+	}
+	var exporter tracing.SpanExporter
+	switch cfg.Type {
+	case "", "noop":
+		return tracing.NewDefaultTracer()
+	case "zipkin":
+		endpoint := cmp.Or(cfg.Endpoint, "http://localhost:9411")
+		exporter = tracing.NewZipkinExporter(endpoint, service, exporterOpts...)
+	case "jaeger":
+		endpoint := cmp.Or(cfg.Endpoint, "localhost:6831")
+		jaeger, err := tracing.NewJaegerExporter(endpoint, service, exporterOpts...)
+		if err != nil {
+			logger.Error("Failed to create Jaeger exporter, tracing disabled", "error", err)
+			return tracing.NewDefaultTracer()
+		}
+		exporter = jaeger
+	default:
+		logger.Error("Unknown tracer type, tracing disabled", "type", cfg.Type)
+		return tracing.NewDefaultTracer()
+	}
+
+	opts := []tracing.TracerOption{
+		tracing.WithSampler(newSamplerFromConfig(cfg.Sampler)),
+		tracing.WithSpanExporter(exporter),
+		tracing.WithBaggageTags(cfg.BaggageTags...),
+	}
+	if tail := cfg.Sampler.Tail; tail.Enabled {
+		opts = append(opts, tracing.WithTailSampling(tracing.TailPolicy{
+			Errors:    tail.Errors,
+			Latency:   tail.Latency,
+			MaxTraces: tail.MaxTraces,
+		}))
+	}
+	return tracing.NewTracer(cfg.Type, opts...)
+}
+
+// tracerComponent sends the spans still queued by the app's tracer on Stop
+type tracerComponent struct {
+	tracer *tracing.StandardTracer
+}
+
+func (c *tracerComponent) Name() string                      { return "tracer" }
+func (c *tracerComponent) DependsOn() []string               { return nil }
+func (c *tracerComponent) OnStart(ctx context.Context) error { return nil }
+func (c *tracerComponent) OnStop(ctx context.Context) error  { return c.tracer.Shutdown(ctx) }
+
+// newSamplerFromConfig composes the sampler described by cfg: per-procedure
+// ratios over the sampler of Type, then the rate limit, then the caller's
+// decision when ParentBased is set
+func newSamplerFromConfig(cfg SamplerConfig) tracing.Sampler {
+	var sampler tracing.Sampler
+	switch cfg.Type {
+	case "never":
+		sampler = tracing.NeverSample()
+	case "ratio":
+		sampler = tracing.TraceIDRatio(cfg.Ratio)
+	default:
+		sampler = tracing.AlwaysSample()
+	}
+	if len(cfg.Procedures) > 0 {
+		rules := make(map[string]tracing.Sampler, len(cfg.Procedures))
+		for procedure, ratio := range cfg.Procedures {
+			rules[procedure] = tracing.TraceIDRatio(ratio)
+		}
+		sampler = tracing.PerProcedure(rules, sampler)
+	}
+	if cfg.RateLimit > 0 {
+		sampler = tracing.RateLimited(cfg.RateLimit, int(math.Ceil(cfg.RateLimit)), sampler)
+	}
+	if cfg.ParentBased {
+		sampler = tracing.ParentBased(sampler)
+	}
+	return sampler
 }
@@ -0,0 +1,209 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// newTestApp returns an App without servers that logs nowhere
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+	app := NewWithConfig("test", "1.0.0", AppConfig{},
+		WithLogger(logging.NewSlogLoggerWithWriter("test", "debug", "text", io.Discard)),
+		WithMetrics(metrics.NewDefaultMetrics()),
+		WithTracer(tracing.NewDefaultTracer()),
+	)
+	t.Cleanup(func() {
+		if app.State() == StateRunning {
+			app.Stop(context.Background())
+		}
+	})
+	return app
+}
+
+// events records lifecycle calls in the order they happen
+type events struct {
+	mu   sync.Mutex
+	list []string
+}
+
+func (e *events) add(event string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.list = append(e.list, event)
+}
+
+func (e *events) get() []string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return slices.Clone(e.list)
+}
+
+// before reports whether a was recorded and b either was not or came later
+func (e *events) before(a, b string) bool {
+	list := e.get()
+	i, j := slices.Index(list, a), slices.Index(list, b)
+	return i >= 0 && (j < 0 || i < j)
+}
+
+// fakeServer records its starts and stops in ev and fails them with startErr and stopErr
+type fakeServer struct {
+	name     string
+	ev       *events
+	startErr error
+	stopErr  error
+}
+
+func (s *fakeServer) Name() string { return s.name }
+
+func (s *fakeServer) Start(ctx context.Context) error {
+	s.ev.add("start " + s.name)
+	return s.startErr
+}
+
+func (s *fakeServer) Stop(ctx context.Context) error {
+	s.ev.add("stop " + s.name)
+	return s.stopErr
+}
+
+func TestStartRollsBackServers(t *testing.T) {
+	startErr := errors.New("address in use")
+	stopErr := errors.New("drain timed out")
+	app := newTestApp(t)
+	var ev events
+	app.AddComponent(recordingComponent(&ev, "db", nil, nil))
+	app.AddServer(&fakeServer{name: "public", ev: &ev})
+	app.AddServer(&fakeServer{name: "admin", ev: &ev, stopErr: stopErr})
+	app.AddServer(&fakeServer{name: "grpc", ev: &ev, startErr: startErr})
+	app.AddServer(&fakeServer{name: "debug", ev: &ev})
+
+	err := app.Start(context.Background())
+	if !errors.Is(err, startErr) || !errors.Is(err, stopErr) {
+		t.Fatalf("Start = %v, want the start failure joined with the rollback failure", err)
+	}
+	want := []string{"start db", "start public", "start admin", "start grpc", "stop admin", "stop public", "stop db"}
+	if got := ev.get(); !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if app.State() != StateFailed {
+		t.Errorf("state = %s", app.State())
+	}
+}
+
+func TestStartRollbackOutlivesStartContext(t *testing.T) {
+	// A server failing because the start context was cancelled still has the
+	// servers before it stopped with a live context
+	ctx, cancel := context.WithCancel(context.Background())
+	app := newTestApp(t)
+	var ev events
+	var stopCtxErr error
+	app.AddComponent(NewComponent("db", nil, func(ctx context.Context) error {
+		stopCtxErr = ctx.Err()
+		return nil
+	}))
+	app.AddServer(&fakeServer{name: "public", ev: &ev})
+	app.AddServer(&cancellingServer{fakeServer{name: "admin", ev: &ev}, cancel})
+
+	if err := app.Start(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Start = %v", err)
+	}
+	if !slices.Contains(ev.get(), "stop public") || stopCtxErr != nil {
+		t.Errorf("rollback ran with a cancelled context: events %v, component saw %v", ev.get(), stopCtxErr)
+	}
+}
+
+// cancellingServer cancels the start context and fails with its error
+type cancellingServer struct {
+	fakeServer
+	cancel context.CancelFunc
+}
+
+func (s *cancellingServer) Start(ctx context.Context) error {
+	s.cancel()
+	return ctx.Err()
+}
+
+func TestStopJoinsErrors(t *testing.T) {
+	serverErr := errors.New("drain timed out")
+	componentErr := errors.New("flush failed")
+	app := newTestApp(t)
+	var ev events
+	app.AddComponent(recordingComponent(&ev, "db", nil, componentErr))
+	app.AddComponent(recordingComponent(&ev, "cache", nil, nil))
+	app.AddServer(&fakeServer{name: "public", ev: &ev, stopErr: serverErr})
+	app.AddServer(&fakeServer{name: "admin", ev: &ev})
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	err := app.Stop(context.Background())
+	if !errors.Is(err, serverErr) || !errors.Is(err, componentErr) {
+		t.Fatalf("Stop = %v, want both failures", err)
+	}
+	// A failure does not keep the rest from stopping
+	for _, stopped := range []string{"stop public", "stop admin", "stop db", "stop cache"} {
+		if !slices.Contains(ev.get(), stopped) {
+			t.Errorf("%s missing from %v", stopped, ev.get())
+		}
+	}
+	if !ev.before("stop admin", "stop public") || !ev.before("stop public", "stop db") {
+		t.Errorf("servers were not stopped in reverse order before the components: %v", ev.get())
+	}
+	if app.State() != StateFailed {
+		t.Errorf("state after an unclean stop = %s", app.State())
+	}
+}
+
+func TestMetricsListener(t *testing.T) {
+	cfg := AppConfig{
+		Servers: []ServerConfig{{Type: "connectrpc", Name: "public", Addr: "127.0.0.1:0"}},
+		Metrics: MetricsConfig{Type: "prometheus", Port: "0"},
+	}
+	app := NewWithConfig("test", "1.0.0", cfg,
+		WithLogger(logging.NewSlogLoggerWithWriter("test", "info", "text", io.Discard)),
+		WithTracer(tracing.NewDefaultTracer()),
+	)
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer app.Stop(context.Background())
+	app.Metrics().Counter("orders_total", 1)
+
+	get := func(server string) (int, string) {
+		t.Helper()
+		_, port, _ := net.SplitHostPort(app.GetServerByName(server).(*connectrpc.Server).Addr())
+		resp, err := http.Get("http://127.0.0.1:" + port + "/metrics")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	if status, body := get("metrics"); status != http.StatusOK || !strings.Contains(body, "orders_total 1") {
+		t.Errorf("metrics listener answered %d: %s", status, body)
+	}
+	if status, _ := get("public"); status != http.StatusNotFound {
+		t.Errorf("public server answered /metrics with %d", status)
+	}
+
+	// Without a port, or when pushing over OTLP, there is no listener
+	for _, m := range []MetricsConfig{{Type: "prometheus"}, {Type: "otlp", Port: "9090"}} {
+		app := NewWithConfig("test", "1.0.0", AppConfig{Metrics: m}, WithLogger(logging.NewSlogLoggerWithWriter("test", "info", "text", io.Discard)))
+		if app.GetServerByName("metrics") != nil {
+			t.Errorf("%+v added a metrics listener", m)
+		}
+	}
+}
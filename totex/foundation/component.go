@@ -0,0 +1,210 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Component represents a non-server resource (e.g., DB pools, caches,
+// consumers) whose lifecycle is managed by the App. Components are started
+// before servers, after the components they depend on, and stopped in
+// reverse dependency order.
+type Component interface {
+	Name() string
+	OnStart(ctx context.Context) error
+	OnStop(ctx context.Context) error
+	DependsOn() []string
+}
+
+// funcComponent adapts plain functions to the Component interface
+type funcComponent struct {
+	name      string
+	onStart   func(ctx context.Context) error
+	onStop    func(ctx context.Context) error
+	dependsOn []string
+}
+
+// NewComponent returns a Component from start and stop hooks; either hook may be nil
+func NewComponent(name string, onStart, onStop func(ctx context.Context) error, dependsOn ...string) Component {
+	return &funcComponent{name: name, onStart: onStart, onStop: onStop, dependsOn: dependsOn}
+}
+
+func (c *funcComponent) Name() string        { return c.name }
+func (c *funcComponent) DependsOn() []string { return c.dependsOn }
+
+func (c *funcComponent) OnStart(ctx context.Context) error {
+	if c.onStart == nil {
+		return nil
+	}
+	return c.onStart(ctx)
+}
+
+func (c *funcComponent) OnStop(ctx context.Context) error {
+	if c.onStop == nil {
+		return nil
+	}
+	return c.onStop(ctx)
+}
+
+// AddComponent adds a component to the app's lifecycle management. Components
+// can only be added before the app is started.
+func (a *App) AddComponent(component Component) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if err := a.requireState("add component to", StateCreated); err != nil {
+		return err
+	}
+	a.components = append(a.components, component)
+	return nil
+}
+
+// GetComponents returns all registered components
+func (a *App) GetComponents() []Component {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.components
+}
+
+// sortComponents orders components into levels; every component only depends
+// on components in earlier levels, so each level can be started in parallel
+func sortComponents(components []Component) ([][]Component, error) {
+	byName := make(map[string]Component, len(components))
+	for _, c := range components {
+		if _, dup := byName[c.Name()]; dup {
+			return nil, fmt.Errorf("duplicate component %q", c.Name())
+		}
+		byName[c.Name()] = c
+	}
+
+	pending := make(map[string]int, len(components))
+	dependents := make(map[string][]string)
+	for _, c := range components {
+		for _, dep := range c.DependsOn() {
+			if _, ok := byName[dep]; !ok {
+				return nil, fmt.Errorf("component %q depends on unknown component %q", c.Name(), dep)
+			}
+			pending[c.Name()]++
+			dependents[dep] = append(dependents[dep], c.Name())
+		}
+	}
+
+	var (
+		levels [][]Component
+		sorted int
+	)
+	// Preserve insertion order within a level so startup is deterministic
+	current := make([]Component, 0)
+	for _, c := range components {
+		if pending[c.Name()] == 0 {
+			current = append(current, c)
+		}
+	}
+	for len(current) > 0 {
+		levels = append(levels, current)
+		sorted += len(current)
+
+		ready := make(map[string]bool)
+		for _, c := range current {
+			for _, name := range dependents[c.Name()] {
+				pending[name]--
+				if pending[name] == 0 {
+					ready[name] = true
+				}
+			}
+		}
+		next := make([]Component, 0, len(ready))
+		for _, c := range components {
+			if ready[c.Name()] {
+				next = append(next, c)
+			}
+		}
+		current = next
+	}
+
+	if sorted != len(components) {
+		var cyclic []string
+		for _, c := range components {
+			if pending[c.Name()] > 0 {
+				cyclic = append(cyclic, c.Name())
+			}
+		}
+		return nil, fmt.Errorf("dependency cycle between components: %s", strings.Join(cyclic, ", "))
+	}
+	return levels, nil
+}
+
+// startComponents starts each level in parallel. If any component fails, the
+// components already started are stopped in reverse order within the
+// rollback timeout before returning.
+func (a *App) startComponents(ctx context.Context, levels [][]Component) ([][]Component, error) {
+	var started [][]Component
+	for _, level := range levels {
+		var (
+			wg   sync.WaitGroup
+			mu   sync.Mutex
+			ok   []Component
+			errs []error
+		)
+		for _, c := range level {
+			wg.Add(1)
+			go func(c Component) {
+				defer wg.Done()
+				if err := c.OnStart(ctx); err != nil {
+					a.logger.Error("Failed to start component", "component", c.Name(), "error", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("failed to start %s: %w", c.Name(), err))
+					mu.Unlock()
+					return
+				}
+				a.logger.Info("Started component", "component", c.Name())
+				mu.Lock()
+				ok = append(ok, c)
+				mu.Unlock()
+			}(c)
+		}
+		wg.Wait()
+
+		if len(ok) > 0 {
+			started = append(started, ok)
+		}
+		if len(errs) > 0 {
+			stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), a.rollbackTimeout)
+			if err := a.stopComponents(stopCtx, started); err != nil {
+				errs = append(errs, fmt.Errorf("rollback: %w", err))
+			}
+			cancel()
+			return nil, errors.Join(errs...)
+		}
+	}
+	return started, nil
+}
+
+// stopComponents stops the levels in reverse order, each level in parallel
+func (a *App) stopComponents(ctx context.Context, levels [][]Component) error {
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	for i := len(levels) - 1; i >= 0; i-- {
+		var wg sync.WaitGroup
+		for _, c := range levels[i] {
+			wg.Add(1)
+			go func(c Component) {
+				defer wg.Done()
+				if err := c.OnStop(ctx); err != nil {
+					a.logger.Error("Failed to stop component", "component", c.Name(), "error", err)
+					mu.Lock()
+					errs = append(errs, fmt.Errorf("failed to stop %s: %w", c.Name(), err))
+					mu.Unlock()
+					return
+				}
+				a.logger.Info("Stopped component", "component", c.Name())
+			}(c)
+		}
+		wg.Wait()
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,153 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// recordingComponent records its starts and stops in ev and fails them with
+// startErr and stopErr
+func recordingComponent(ev *events, name string, startErr, stopErr error, dependsOn ...string) Component {
+	return NewComponent(name,
+		func(ctx context.Context) error { ev.add("start " + name); return startErr },
+		func(ctx context.Context) error { ev.add("stop " + name); return stopErr },
+		dependsOn...)
+}
+
+func levelNames(levels [][]Component) string {
+	names := make([][]string, len(levels))
+	for i, level := range levels {
+		for _, c := range level {
+			names[i] = append(names[i], c.Name())
+		}
+	}
+	return fmt.Sprint(names)
+}
+
+func TestSortComponents(t *testing.T) {
+	components := []Component{
+		NewComponent("api", nil, nil, "repo", "cache"),
+		NewComponent("db", nil, nil),
+		NewComponent("repo", nil, nil, "db"),
+		NewComponent("cache", nil, nil),
+		NewComponent("metrics", nil, nil),
+		NewComponent("consumer", nil, nil, "repo"),
+	}
+	levels, err := sortComponents(components)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Each level keeps the order the components were added in
+	if got, want := levelNames(levels), "[[db cache metrics] [repo] [api consumer]]"; got != want {
+		t.Errorf("levels = %s, want %s", got, want)
+	}
+
+	if levels, err := sortComponents(nil); err != nil || len(levels) != 0 {
+		t.Errorf("sortComponents(nil) = %s, %v", levelNames(levels), err)
+	}
+}
+
+func TestSortComponentsErrors(t *testing.T) {
+	tests := []struct {
+		components []Component
+		want       string
+	}{
+		{
+			[]Component{NewComponent("db", nil, nil), NewComponent("db", nil, nil)},
+			`duplicate component "db"`,
+		},
+		{
+			[]Component{NewComponent("repo", nil, nil, "db")},
+			`component "repo" depends on unknown component "db"`,
+		},
+		{
+			[]Component{
+				NewComponent("db", nil, nil),
+				NewComponent("a", nil, nil, "b", "db"),
+				NewComponent("b", nil, nil, "a"),
+				NewComponent("c", nil, nil, "a"),
+			},
+			"dependency cycle between components: a, b, c",
+		},
+		{
+			[]Component{NewComponent("self", nil, nil, "self")},
+			"dependency cycle between components: self",
+		},
+	}
+	for _, tt := range tests {
+		if _, err := sortComponents(tt.components); err == nil || err.Error() != tt.want {
+			t.Errorf("sortComponents error = %v, want %s", err, tt.want)
+		}
+	}
+
+	// Start refuses invalid components without starting any
+	app := newTestApp(t)
+	var ev events
+	app.AddComponent(recordingComponent(&ev, "db", nil, nil))
+	app.AddComponent(recordingComponent(&ev, "repo", nil, nil, "cache"))
+	if err := app.Start(context.Background()); err == nil || !strings.Contains(err.Error(), "invalid components") {
+		t.Errorf("Start = %v", err)
+	}
+	if got := ev.get(); len(got) != 0 {
+		t.Errorf("started %v", got)
+	}
+}
+
+func TestComponentsStartInDependencyOrder(t *testing.T) {
+	app := newTestApp(t)
+	var ev events
+	app.AddComponent(recordingComponent(&ev, "api", nil, nil, "repo"))
+	app.AddComponent(recordingComponent(&ev, "repo", nil, nil, "db", "cache"))
+	app.AddComponent(recordingComponent(&ev, "db", nil, nil))
+	app.AddComponent(recordingComponent(&ev, "cache", nil, nil))
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, order := range [][2]string{
+		{"start db", "start repo"}, {"start cache", "start repo"}, {"start repo", "start api"},
+		{"stop api", "stop repo"}, {"stop repo", "stop db"}, {"stop repo", "stop cache"},
+	} {
+		if !ev.before(order[0], order[1]) {
+			t.Errorf("%s did not happen before %s: %v", order[0], order[1], ev.get())
+		}
+	}
+}
+
+func TestComponentStartRollback(t *testing.T) {
+	startErr := errors.New("queue unreachable")
+	stopErr := errors.New("db close failed")
+	app := newTestApp(t)
+	var ev events
+	app.AddComponent(recordingComponent(&ev, "db", nil, stopErr))
+	app.AddComponent(recordingComponent(&ev, "cache", nil, nil, "db"))
+	app.AddComponent(recordingComponent(&ev, "queue", startErr, nil, "db"))
+	app.AddComponent(recordingComponent(&ev, "api", nil, nil, "cache", "queue"))
+
+	err := app.Start(context.Background())
+	if !errors.Is(err, startErr) || !errors.Is(err, stopErr) {
+		t.Fatalf("Start = %v, want the start failure joined with the rollback failure", err)
+	}
+	if !strings.Contains(err.Error(), "failed to start queue") || !strings.Contains(err.Error(), "rollback: failed to stop db") {
+		t.Errorf("Start = %v", err)
+	}
+
+	got := ev.get()
+	// The failed component is not stopped, and nothing after it started
+	if slices.Contains(got, "stop queue") || slices.Contains(got, "start api") {
+		t.Errorf("events %v", got)
+	}
+	if !ev.before("stop cache", "stop db") {
+		t.Errorf("started components were not stopped in reverse order: %v", got)
+	}
+	if app.State() != StateFailed {
+		t.Errorf("state = %s", app.State())
+	}
+}
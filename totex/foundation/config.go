@@ -2,6 +2,13 @@ package foundation
 
 import (
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/redact"
 )
 
 // AppConfig holds all configuration for the application
@@ -10,6 +17,14 @@ type AppConfig struct {
 	Tracer  TracerConfig
 	Metrics MetricsConfig
 	Servers []ServerConfig
+	Upgrade UpgradeConfig
+	Leader  LeaderConfig
+	Redact  RedactConfig
+	SLO     SLOConfig
+	Client  ClientConfig
+
+	// RollbackTimeout bounds how long a failed Start spends stopping what it already started
+	RollbackTimeout time.Duration
 }
 
 // LoggerConfig configuration for the logger
@@ -18,25 +33,137 @@ type LoggerConfig struct {
 	Level  string
 	Format string
 	Output string
+
+	// Rotation settings, used when Output is a file path
+	MaxSizeMB  int
+	MaxAge     time.Duration
+	MaxBackups int
+	Compress   bool
+	// NoSignalReopen stops a file from being reopened on SIGHUP. NewWithConfig
+	// sets it when upgrades are enabled, as SIGHUP then triggers an upgrade and
+	// the new process opens the file afresh.
+	NoSignalReopen bool
+
+	// Sampling of repeated lines; disabled when SamplingInterval is 0
+	SamplingInterval   time.Duration
+	SamplingFirst      int
+	SamplingThereafter int
+
+	BaggageKeys []string // baggage members added to context-aware log lines
+
+	// AdminToken is the bearer token /admin/loggers and /admin/slo require;
+	// the endpoints are not served while it is empty
+	AdminToken string
 }
 
 // TracerConfig configuration for the tracer
 type TracerConfig struct {
-	Type     string
-	Endpoint string
+	Type        string // "noop", "zipkin" or "jaeger"
+	Endpoint    string
+	Sampler     SamplerConfig
+	BaggageTags []string // baggage members copied into span tags
+
+	QueueSize     int           // finished spans waiting to be sent before new ones are dropped
+	BatchSize     int           // spans sent at once
+	BatchInterval time.Duration // how long spans wait for a batch to fill
+}
+
+// SamplerConfig configures which traces are sampled
+type SamplerConfig struct {
+	Type        string             // "always", "never" or "ratio"
+	Ratio       float64            // share of traces sampled by the "ratio" sampler
+	ParentBased bool               // follow the caller's decision when there is one
+	RateLimit   float64            // traces sampled per second at most; 0 disables the limit
+	Procedures  map[string]float64 // sampling ratio per procedure, overriding Type
+	Tail        TailSamplingConfig
+}
+
+// TailSamplingConfig configures keeping whole unsampled traces that turn out
+// to be interesting once their local root span finishes
+type TailSamplingConfig struct {
+	Enabled   bool
+	Errors    bool          // keep traces with an error on any span
+	Latency   time.Duration // keep traces whose root ran longer; 0 disables
+	MaxTraces int           // traces buffered at once
 }
 
 // MetricsConfig configuration for the metrics
 type MetricsConfig struct {
-	Type string
-	Port string
+	Type      string // "noop", "prometheus" or "otlp"
+	Port      string // port serving /metrics, except with "otlp"; empty disables it
+	MaxSeries int    // label sets per metric family before folding into an overflow series
+	Runtime   bool   // collect Go runtime, process and build info metrics
+	OTLP      OTLPConfig
+}
+
+// OTLPConfig configures the OTLP metrics exporter used with METRICS_TYPE=otlp
+type OTLPConfig struct {
+	Endpoint    string            // collector URL, e.g. http://localhost:4318
+	Protocol    string            // "http/protobuf" or "grpc"
+	Interval    time.Duration     // time between pushes
+	Temporality string            // "cumulative" or "delta"
+	Histogram   string            // "explicit" or "exponential"
+	Headers     map[string]string // sent with every push
 }
 
 // ServerConfig configuration for servers
 type ServerConfig struct {
-	Type string // "connectrpc", "http", etc.
-	Name string
-	Addr string
+	Type      string // "connectrpc", "http", etc.
+	Name      string
+	Addr      string
+	AccessLog bool // log every request once it completes
+}
+
+// UpgradeConfig configuration for zero-downtime restarts via listener handoff
+type UpgradeConfig struct {
+	Enabled bool          // hand listeners to a new process on SIGHUP/SIGUSR2; log files are then not reopened on SIGHUP
+	Timeout time.Duration // how long to wait for the new process to become ready
+}
+
+// LeaderConfig configuration for leader election
+type LeaderConfig struct {
+	Backend string        // "" (disabled) or "file" for replicas on one host; other backends are set with App.SetLeaderBackend
+	Dir     string        // lock file directory for the file backend
+	TTL     time.Duration // lease duration without renewal
+}
+
+// RedactConfig configuration for masking sensitive values in logs, spans and access logs
+type RedactConfig struct {
+	Keys []string // sensitive attribute keys; defaults to redact.DefaultKeys
+}
+
+// SLOConfig configures service level objectives of ConnectRPC procedures
+type SLOConfig struct {
+	// Objectives are "procedure=availability[:latency]", e.g.
+	// "/user.v1.UserService/CreateUser=99.9%:250ms"; see slo.ParseObjective
+	Objectives []string
+	Interval   time.Duration // time between burn-rate updates
+}
+
+// ClientConfig configures the ConnectRPC clients created with NewClient
+type ClientConfig struct {
+	Timeout             time.Duration // bound on every unary call, retries included; 0 disables
+	MaxIdleConnsPerHost int           // idle connections kept open to each target
+	IdleConnTimeout     time.Duration // how long an idle connection is kept open
+
+	RetryMaxAttempts        int           // attempts per call, including the first; 1 disables retries
+	RetryInitialBackoff     time.Duration // longest wait before the first retry, doubling with every retry
+	RetryMaxBackoff         time.Duration // longest wait between attempts
+	RetryCodes              []string      // codes retried for every procedure, e.g. "unavailable"
+	RetryIdempotentCodes    []string      // codes also retried for idempotent procedures
+	RetryBudgetRatio        float64       // retries and hedged requests allowed per call
+	RetryBudgetMinPerSecond float64       // retries allowed per second whatever the number of calls
+
+	HedgeDelay       time.Duration // wait before hedging a call; 0 disables hedging
+	HedgeMaxAttempts int           // attempts per hedged call, including the first
+	HedgeProcedures  []string      // procedures hedged in addition to idempotent ones
+
+	BreakerWindow         time.Duration // period the failure ratio of a procedure is computed over
+	BreakerMinRequests    int           // calls in the window before a breaker may open
+	BreakerFailureRatio   float64       // share of failed calls that opens a breaker; 0 disables breakers
+	BreakerOpenDuration   time.Duration // how long an open breaker fails calls fast
+	BreakerHalfOpenProbes int           // calls that must succeed to close a breaker again
+	BreakerCodes          []string      // codes counted as failures
 }
 
 // LoadConfigFromEnv loads configuration from environment variables
@@ -46,12 +173,63 @@ func LoadConfigFromEnv() AppConfig {
 	setDefaultEnv("LOGGER_LEVEL", "info")
 	setDefaultEnv("LOGGER_FORMAT", "text")
 	setDefaultEnv("LOGGER_OUTPUT", "stdout")
+	setDefaultEnv("LOGGER_MAX_SIZE_MB", "100")
+	setDefaultEnv("LOGGER_MAX_AGE", "0s")
+	setDefaultEnv("LOGGER_MAX_BACKUPS", "7")
+	setDefaultEnv("LOGGER_COMPRESS", "false")
+	setDefaultEnv("LOGGER_SAMPLING_INTERVAL", "0s")
+	setDefaultEnv("LOGGER_SAMPLING_FIRST", "100")
+	setDefaultEnv("LOGGER_SAMPLING_THEREAFTER", "100")
 	setDefaultEnv("TRACER_TYPE", "noop")
 	setDefaultEnv("TRACER_ENDPOINT", "")
+	setDefaultEnv("TRACER_QUEUE_SIZE", "2048")
+	setDefaultEnv("TRACER_BATCH_SIZE", "512")
+	setDefaultEnv("TRACER_BATCH_INTERVAL", "5s")
+	setDefaultEnv("TRACER_SAMPLER", "always")
+	setDefaultEnv("TRACER_SAMPLER_RATIO", "1")
+	setDefaultEnv("TRACER_SAMPLER_PARENT_BASED", "true")
+	setDefaultEnv("TRACER_SAMPLER_RATE_LIMIT", "0")
+	setDefaultEnv("TRACER_TAIL_ENABLED", "false")
+	setDefaultEnv("TRACER_TAIL_ERRORS", "true")
+	setDefaultEnv("TRACER_TAIL_LATENCY", "0s")
+	setDefaultEnv("TRACER_TAIL_MAX_TRACES", "1000")
 	setDefaultEnv("METRICS_TYPE", "noop")
 	setDefaultEnv("METRICS_PORT", "9090")
+	setDefaultEnv("METRICS_MAX_SERIES", "1000")
+	setDefaultEnv("METRICS_RUNTIME", "true")
+	setDefaultEnv("METRICS_OTLP_ENDPOINT", "http://localhost:4318")
+	setDefaultEnv("METRICS_OTLP_PROTOCOL", "http/protobuf")
+	setDefaultEnv("METRICS_OTLP_INTERVAL", "60s")
+	setDefaultEnv("METRICS_OTLP_TEMPORALITY", "cumulative")
+	setDefaultEnv("METRICS_OTLP_HISTOGRAM", "explicit")
 	setDefaultEnv("SERVER_NAME", "server")
 	setDefaultEnv("SERVER_ADDR", ":8080")
+	setDefaultEnv("SERVER_ACCESS_LOG", "false")
+	setDefaultEnv("UPGRADE_ENABLED", "false")
+	setDefaultEnv("UPGRADE_TIMEOUT", "30s")
+	setDefaultEnv("APP_ROLLBACK_TIMEOUT", "10s")
+	setDefaultEnv("LEADER_DIR", filepath.Join(os.TempDir(), "foundation-leader"))
+	setDefaultEnv("LEADER_TTL", "15s")
+	setDefaultEnv("REDACT_KEYS", strings.Join(redact.DefaultKeys, ","))
+	setDefaultEnv("SLO_INTERVAL", "15s")
+	setDefaultEnv("CLIENT_TIMEOUT", "10s")
+	setDefaultEnv("CLIENT_MAX_IDLE_CONNS_PER_HOST", "100")
+	setDefaultEnv("CLIENT_IDLE_CONN_TIMEOUT", "90s")
+	setDefaultEnv("CLIENT_RETRY_MAX_ATTEMPTS", "3")
+	setDefaultEnv("CLIENT_RETRY_INITIAL_BACKOFF", "50ms")
+	setDefaultEnv("CLIENT_RETRY_MAX_BACKOFF", "1s")
+	setDefaultEnv("CLIENT_RETRY_CODES", "unavailable")
+	setDefaultEnv("CLIENT_RETRY_IDEMPOTENT_CODES", "deadline_exceeded,aborted")
+	setDefaultEnv("CLIENT_RETRY_BUDGET_RATIO", "0.2")
+	setDefaultEnv("CLIENT_RETRY_BUDGET_MIN_PER_SECOND", "10")
+	setDefaultEnv("CLIENT_HEDGE_DELAY", "0s")
+	setDefaultEnv("CLIENT_HEDGE_MAX_ATTEMPTS", "2")
+	setDefaultEnv("CLIENT_BREAKER_WINDOW", "10s")
+	setDefaultEnv("CLIENT_BREAKER_MIN_REQUESTS", "20")
+	setDefaultEnv("CLIENT_BREAKER_FAILURE_RATIO", "0.5")
+	setDefaultEnv("CLIENT_BREAKER_OPEN_DURATION", "10s")
+	setDefaultEnv("CLIENT_BREAKER_HALF_OPEN_PROBES", "3")
+	setDefaultEnv("CLIENT_BREAKER_CODES", "unavailable,deadline_exceeded,internal,unknown,resource_exhausted,data_loss")
 
 	// Parse server configuration
 	servers := parseServerConfig()
@@ -62,16 +240,96 @@ func LoadConfigFromEnv() AppConfig {
 			Level:  os.Getenv("LOGGER_LEVEL"),
 			Format: os.Getenv("LOGGER_FORMAT"),
 			Output: os.Getenv("LOGGER_OUTPUT"),
+
+			MaxSizeMB:  getEnvInt("LOGGER_MAX_SIZE_MB", 100),
+			MaxAge:     getEnvDuration("LOGGER_MAX_AGE", 0),
+			MaxBackups: getEnvInt("LOGGER_MAX_BACKUPS", 7),
+			Compress:   getEnvBool("LOGGER_COMPRESS"),
+
+			SamplingInterval:   getEnvDuration("LOGGER_SAMPLING_INTERVAL", 0),
+			SamplingFirst:      getEnvInt("LOGGER_SAMPLING_FIRST", 100),
+			SamplingThereafter: getEnvInt("LOGGER_SAMPLING_THEREAFTER", 100),
+
+			BaggageKeys: getEnvList("LOGGER_BAGGAGE_KEYS"),
+			AdminToken:  os.Getenv("LOGGER_ADMIN_TOKEN"),
 		},
 		Tracer: TracerConfig{
-			Type:     os.Getenv("TRACER_TYPE"),
-			Endpoint: os.Getenv("TRACER_ENDPOINT"),
+			Type:          os.Getenv("TRACER_TYPE"),
+			Endpoint:      os.Getenv("TRACER_ENDPOINT"),
+			QueueSize:     getEnvInt("TRACER_QUEUE_SIZE", 2048),
+			BatchSize:     getEnvInt("TRACER_BATCH_SIZE", 512),
+			BatchInterval: getEnvDuration("TRACER_BATCH_INTERVAL", 5*time.Second),
+			BaggageTags:   getEnvList("TRACER_BAGGAGE_TAGS"),
+			Sampler: SamplerConfig{
+				Type:        os.Getenv("TRACER_SAMPLER"),
+				Ratio:       getEnvFloat("TRACER_SAMPLER_RATIO", 1),
+				ParentBased: getEnvBool("TRACER_SAMPLER_PARENT_BASED"),
+				RateLimit:   getEnvFloat("TRACER_SAMPLER_RATE_LIMIT", 0),
+				Procedures:  getEnvFloatMap("TRACER_SAMPLER_PROCEDURES"),
+				Tail: TailSamplingConfig{
+					Enabled:   getEnvBool("TRACER_TAIL_ENABLED"),
+					Errors:    getEnvBool("TRACER_TAIL_ERRORS"),
+					Latency:   getEnvDuration("TRACER_TAIL_LATENCY", 0),
+					MaxTraces: getEnvInt("TRACER_TAIL_MAX_TRACES", 1000),
+				},
+			},
 		},
 		Metrics: MetricsConfig{
-			Type: os.Getenv("METRICS_TYPE"),
-			Port: os.Getenv("METRICS_PORT"),
+			Type:      os.Getenv("METRICS_TYPE"),
+			Port:      os.Getenv("METRICS_PORT"),
+			MaxSeries: getEnvInt("METRICS_MAX_SERIES", metrics.DefaultMaxSeries),
+			Runtime:   getEnvBool("METRICS_RUNTIME"),
+			OTLP: OTLPConfig{
+				Endpoint:    os.Getenv("METRICS_OTLP_ENDPOINT"),
+				Protocol:    os.Getenv("METRICS_OTLP_PROTOCOL"),
+				Interval:    getEnvDuration("METRICS_OTLP_INTERVAL", time.Minute),
+				Temporality: os.Getenv("METRICS_OTLP_TEMPORALITY"),
+				Histogram:   os.Getenv("METRICS_OTLP_HISTOGRAM"),
+				Headers:     getEnvMap("METRICS_OTLP_HEADERS"),
+			},
 		},
 		Servers: servers,
+		Upgrade: UpgradeConfig{
+			Enabled: getEnvBool("UPGRADE_ENABLED"),
+			Timeout: getEnvDuration("UPGRADE_TIMEOUT", 30*time.Second),
+		},
+		Leader: LeaderConfig{
+			Backend: os.Getenv("LEADER_BACKEND"),
+			Dir:     os.Getenv("LEADER_DIR"),
+			TTL:     getEnvDuration("LEADER_TTL", 15*time.Second),
+		},
+		Redact: RedactConfig{
+			Keys: getEnvList("REDACT_KEYS"),
+		},
+		SLO: SLOConfig{
+			Objectives: getEnvList("SLO_OBJECTIVES"),
+			Interval:   getEnvDuration("SLO_INTERVAL", 15*time.Second),
+		},
+		Client: ClientConfig{
+			Timeout:             getEnvDuration("CLIENT_TIMEOUT", 10*time.Second),
+			MaxIdleConnsPerHost: getEnvInt("CLIENT_MAX_IDLE_CONNS_PER_HOST", 100),
+			IdleConnTimeout:     getEnvDuration("CLIENT_IDLE_CONN_TIMEOUT", 90*time.Second),
+
+			RetryMaxAttempts:        getEnvInt("CLIENT_RETRY_MAX_ATTEMPTS", 3),
+			RetryInitialBackoff:     getEnvDuration("CLIENT_RETRY_INITIAL_BACKOFF", 50*time.Millisecond),
+			RetryMaxBackoff:         getEnvDuration("CLIENT_RETRY_MAX_BACKOFF", time.Second),
+			RetryCodes:              getEnvList("CLIENT_RETRY_CODES"),
+			RetryIdempotentCodes:    getEnvList("CLIENT_RETRY_IDEMPOTENT_CODES"),
+			RetryBudgetRatio:        getEnvFloat("CLIENT_RETRY_BUDGET_RATIO", 0.2),
+			RetryBudgetMinPerSecond: getEnvFloat("CLIENT_RETRY_BUDGET_MIN_PER_SECOND", 10),
+
+			HedgeDelay:       getEnvDuration("CLIENT_HEDGE_DELAY", 0),
+			HedgeMaxAttempts: getEnvInt("CLIENT_HEDGE_MAX_ATTEMPTS", 2),
+			HedgeProcedures:  getEnvList("CLIENT_HEDGE_PROCEDURES"),
+
+			BreakerWindow:         getEnvDuration("CLIENT_BREAKER_WINDOW", 10*time.Second),
+			BreakerMinRequests:    getEnvInt("CLIENT_BREAKER_MIN_REQUESTS", 20),
+			BreakerFailureRatio:   getEnvFloat("CLIENT_BREAKER_FAILURE_RATIO", 0.5),
+			BreakerOpenDuration:   getEnvDuration("CLIENT_BREAKER_OPEN_DURATION", 10*time.Second),
+			BreakerHalfOpenProbes: getEnvInt("CLIENT_BREAKER_HALF_OPEN_PROBES", 3),
+			BreakerCodes:          getEnvList("CLIENT_BREAKER_CODES"),
+		},
+		RollbackTimeout: getEnvDuration("APP_ROLLBACK_TIMEOUT", 10*time.Second),
 	}
 }
 
@@ -82,6 +340,72 @@ func setDefaultEnv(key, value string) {
 	}
 }
 
+// getEnvBool parses a boolean environment variable, treating invalid values as false
+func getEnvBool(key string) bool {
+	v, err := strconv.ParseBool(os.Getenv(key))
+	return err == nil && v
+}
+
+// getEnvInt parses an integer environment variable, falling back to def when unset or invalid
+func getEnvInt(key string, def int) int {
+	v, err := strconv.Atoi(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvFloat parses a float environment variable, falling back to def when unset or invalid
+func getEnvFloat(key string, def float64) float64 {
+	v, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvDuration parses a duration environment variable, falling back to def when unset or invalid
+func getEnvDuration(key string, def time.Duration) time.Duration {
+	v, err := time.ParseDuration(os.Getenv(key))
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// getEnvList parses a comma-separated environment variable, skipping empty items
+func getEnvList(key string) []string {
+	var items []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// getEnvMap parses a comma-separated list of key=value pairs, skipping items without "="
+func getEnvMap(key string) map[string]string {
+	m := make(map[string]string)
+	for _, item := range getEnvList(key) {
+		if k, v, ok := strings.Cut(item, "="); ok {
+			m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+		}
+	}
+	return m
+}
+
+// getEnvFloatMap parses a comma-separated list of key=float pairs, skipping invalid items
+func getEnvFloatMap(key string) map[string]float64 {
+	m := make(map[string]float64)
+	for k, v := range getEnvMap(key) {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			m[k] = f
+		}
+	}
+	return m
+}
+
 // parseServerConfig parses server configuration from environment variables
 func parseServerConfig() []ServerConfig {
 	// For now, we support a single server configuration
@@ -93,9 +417,10 @@ func parseServerConfig() []ServerConfig {
 
 	return []ServerConfig{
 		{
-			Type: serverType,
-			Name: os.Getenv("SERVER_NAME"),
-			Addr: os.Getenv("SERVER_ADDR"),
+			Type:      serverType,
+			Name:      os.Getenv("SERVER_NAME"),
+			Addr:      os.Getenv("SERVER_ADDR"),
+			AccessLog: getEnvBool("SERVER_ACCESS_LOG"),
 		},
 	}
 }
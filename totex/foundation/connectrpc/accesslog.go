@@ -0,0 +1,92 @@
+package connectrpc
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/yourusername/foundation/redact"
+)
+
+// EnableAccessLog logs every request once it completes. Query parameters
+// under sensitive keys, and sensitive pairs in the path, are masked by r.
+func (s *Server) EnableAccessLog(r *redact.Redactor) {
+	s.accessLog = true
+	s.redactor = r
+}
+
+// withAccessLog logs method, path, status, size and duration of each request
+func (s *Server) withAccessLog(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		args := []any{
+			"method", r.Method,
+			"path", s.redactor.String(r.URL.Path),
+			"status", rec.status,
+			"bytes", rec.bytes,
+			"duration", time.Since(start),
+			"remote_addr", r.RemoteAddr,
+			"user_agent", r.UserAgent(),
+		}
+		if r.URL.RawQuery != "" {
+			args = append(args, "query", redactQuery(s.redactor, r.URL.RawQuery))
+		}
+		s.logger.InfoContext(r.Context(), "Handled request", args...)
+	})
+}
+
+// redactQuery masks the values of sensitive parameters in a raw query string
+func redactQuery(r *redact.Redactor, query string) string {
+	params := strings.Split(query, "&")
+	for i, param := range params {
+		key, _, _ := strings.Cut(param, "=")
+		if name, err := url.QueryUnescape(key); err == nil && r.Sensitive(name) {
+			params[i] = key + "=" + redact.Mask
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// statusRecorder captures the status code and body size of a response, and
+// the start of the body of an error response if captureErrors is set
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int
+	wroteHeader bool
+
+	captureErrors bool
+	errorBody     []byte
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status = status
+		r.wroteHeader = true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	r.wroteHeader = true
+	if r.captureErrors && r.status != http.StatusOK && len(r.errorBody) < maxErrorBody {
+		r.errorBody = append(r.errorBody, p[:min(len(p), maxErrorBody-len(r.errorBody))]...)
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.bytes += n
+	return n, err
+}
+
+// Flush supports streaming responses
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
@@ -0,0 +1,34 @@
+package connectrpc_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/foundationtest"
+	"github.com/yourusername/foundation/redact"
+)
+
+func TestAccessLogRedacts(t *testing.T) {
+	logger := foundationtest.NewLogger()
+	server := connectrpc.NewServer("test", "127.0.0.1:0", logger)
+	server.EnableAccessLog(redact.New("password", "token"))
+	server.RegisterHandler("/reset/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer server.Stop(context.Background())
+
+	resp, err := http.Get("http://" + server.Addr() + "/reset/password=hunter2?user=ada&access_token=abc123&new%5Fpassword=x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	logger.AssertLogged(t, "Handled request",
+		"path", "/reset/password="+redact.Mask,
+		"query", "user=ada&access_token="+redact.Mask+"&new%5Fpassword="+redact.Mask,
+		"status", 200,
+	)
+}
@@ -0,0 +1,19 @@
+package connectrpc
+
+import (
+	"net/http"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+// withBaggage puts the baggage sent by the caller on the request context, so
+// handlers can read it and outgoing calls made with Transport pass it on
+func withBaggage(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if len(r.Header.Values(tracing.BaggageHeader)) == 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+		next.ServeHTTP(w, r.WithContext(tracing.ExtractBaggage(r.Context(), r.Header)))
+	})
+}
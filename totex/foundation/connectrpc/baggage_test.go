@@ -0,0 +1,99 @@
+package connectrpc_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	connect "github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/foundationtest"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// seen is what a handler saw of its caller's trace and baggage
+type seen struct {
+	tenant string
+	sc     tracing.SpanContext
+}
+
+// startPropagationServer starts an instrumented server whose handler reports
+// the baggage and span context of each call
+func startPropagationServer(t *testing.T, tracer tracing.Tracer) (string, chan seen) {
+	t.Helper()
+	calls := make(chan seen, 1)
+	handler := func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+		calls <- seen{
+			tenant: tracing.BaggageValue(ctx, "tenant_id"),
+			sc:     tracing.SpanContextFromContext(ctx),
+		}
+		return connect.NewResponse(req.Msg), nil
+	}
+	server := connectrpc.NewServer("test", "127.0.0.1:0", foundationtest.NewLogger())
+	server.EnableInstrumentation(tracer, foundationtest.NewMetrics())
+	mux := http.NewServeMux()
+	mux.Handle(unaryProcedure, connect.NewUnaryHandler(unaryProcedure, handler))
+	server.RegisterHandler("/test.v1.TestService/", mux)
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop(context.Background()) })
+	return "http://" + server.Addr(), calls
+}
+
+func TestPropagation(t *testing.T) {
+	clients := []struct {
+		name      string
+		newClient func(tracer tracing.Tracer, url string) *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue]
+	}{
+		{"transport", func(tracer tracing.Tracer, url string) *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue] {
+			httpClient := &http.Client{Transport: &connectrpc.Transport{Tracer: tracer}}
+			return connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](httpClient, url+unaryProcedure)
+		}},
+		{"interceptor", func(tracer tracing.Tracer, url string) *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue] {
+			return connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](http.DefaultClient, url+unaryProcedure,
+				connect.WithInterceptors(connectrpc.NewClientInterceptor(tracer, foundationtest.NewMetrics())))
+		}},
+	}
+	for _, c := range clients {
+		t.Run(c.name, func(t *testing.T) {
+			// Each side has its own tracer, as two services would
+			url, calls := startPropagationServer(t, tracing.NewTracer("users"))
+			clientTracer := tracing.NewTracer("orders")
+			client := c.newClient(clientTracer, url)
+
+			span, ctx := tracing.StartSpanFromContext(
+				tracing.ContextWithBaggageValue(context.Background(), "tenant_id", "acme corp"), clientTracer, "handler")
+			defer span.Finish()
+			if _, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("hi"))); err != nil {
+				t.Fatal(err)
+			}
+
+			got := <-calls
+			if got.tenant != "acme corp" {
+				t.Errorf("handler saw tenant_id %q", got.tenant)
+			}
+			want := tracing.SpanContextFromContext(ctx)
+			if got.sc.TraceID != want.TraceID || got.sc.SpanID == want.SpanID || !got.sc.Sampled {
+				t.Errorf("handler span %+v does not continue the caller's trace %+v", got.sc, want)
+			}
+		})
+	}
+}
+
+func TestBaggageWithoutTracing(t *testing.T) {
+	// Baggage travels without a span or a tracer on either side
+	url, calls := startPropagationServer(t, foundationtest.NewTracer())
+	httpClient := &http.Client{Transport: &connectrpc.Transport{}}
+	client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](httpClient, url+unaryProcedure)
+
+	ctx := tracing.ContextWithBaggageValue(context.Background(), "tenant_id", "acme")
+	if _, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("hi"))); err != nil {
+		t.Fatal(err)
+	}
+	if got := <-calls; got.tenant != "acme" {
+		t.Errorf("handler saw tenant_id %q", got.tenant)
+	}
+}
@@ -0,0 +1,286 @@
+package connectrpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+)
+
+const (
+	// CircuitStateMetric is 1 for the current state of each circuit breaker
+	// and 0 for the others, labelled by target, procedure and state
+	CircuitStateMetric = "rpc_client_circuit_state"
+	// CircuitRejectedMetric counts calls failed fast by an open circuit
+	// breaker, labelled by target and procedure
+	CircuitRejectedMetric = "rpc_client_circuit_rejected_total"
+)
+
+// ErrCircuitOpen is wrapped in the unavailable errors of calls a circuit
+// breaker rejects; they are never retried
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// breakerBuckets is the number of buckets a breaker's window is split into
+const breakerBuckets = 10
+
+// BreakerPolicy configures circuit breakers. A breaker is closed while the
+// calls it sees succeed; it opens when at least FailureRatio of the last
+// Window's calls failed, and rejects every call for OpenDuration. It then
+// turns half-open and lets HalfOpenProbes calls through: it closes again once
+// they all succeed, and opens again as soon as one fails.
+type BreakerPolicy struct {
+	Window         time.Duration  // period the failure ratio is computed over; 10s if not positive
+	MinRequests    int            // calls in the window before the breaker may open; at least 1
+	FailureRatio   float64        // share of failed calls that opens the breaker; 0 disables it
+	OpenDuration   time.Duration  // how long the breaker rejects calls before probing
+	HalfOpenProbes int            // calls that must succeed to close the breaker again; at least 1
+	Codes          []connect.Code // codes counted as failures; others show the server is up
+}
+
+// clamped returns p with the settings a breaker cannot work without raised
+// to their minimum: without a window it never opens, and without probes it
+// never closes again
+func (p BreakerPolicy) clamped() BreakerPolicy {
+	if p.Window <= 0 {
+		p.Window = DefaultBreakerPolicy().Window
+	}
+	p.MinRequests = max(p.MinRequests, 1)
+	p.HalfOpenProbes = max(p.HalfOpenProbes, 1)
+	return p
+}
+
+// DefaultBreakerPolicy opens when half of at least 20 calls over 10 seconds
+// failed with a code pointing at the server or the network, and probes with
+// 3 calls after 10 seconds
+func DefaultBreakerPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		Window:         10 * time.Second,
+		MinRequests:    20,
+		FailureRatio:   0.5,
+		OpenDuration:   10 * time.Second,
+		HalfOpenProbes: 3,
+		Codes: []connect.Code{
+			connect.CodeUnavailable, connect.CodeDeadlineExceeded, connect.CodeInternal,
+			connect.CodeUnknown, connect.CodeResourceExhausted, connect.CodeDataLoss,
+		},
+	}
+}
+
+type breakerState int
+
+const (
+	stateClosed breakerState = iota
+	stateOpen
+	stateHalfOpen
+)
+
+var breakerStateNames = []string{"closed", "open", "half_open"}
+
+// NewCircuitBreakerInterceptor returns a client interceptor that keeps a
+// circuit breaker per target and procedure, failing unary calls fast with
+// connect.CodeUnavailable while their breaker is open. State changes are
+// logged and exported in CircuitStateMetric.
+func NewCircuitBreakerInterceptor(p BreakerPolicy, m metrics.Metrics, logger logging.Logger) connect.Interceptor {
+	cb := newCircuitBreakers(p, m, logger)
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if cb.policy.FailureRatio <= 0 || !req.Spec().IsClient {
+				return next(ctx, req)
+			}
+			key := breakerKey{target: req.Peer().Addr, procedure: req.Spec().Procedure}
+			probe, ok := cb.allow(key)
+			if !ok {
+				m.Counter(CircuitRejectedMetric, 1, "target", key.target, "procedure", key.procedure)
+				return nil, connect.NewError(connect.CodeUnavailable, fmt.Errorf("%w for %s%s", ErrCircuitOpen, key.target, key.procedure))
+			}
+			resp, err := next(ctx, req)
+			cb.record(key, probe, cb.outcome(ctx, err))
+			return resp, err
+		}
+	})
+}
+
+type breakerKey struct {
+	target    string
+	procedure string
+}
+
+// circuitBreakers holds the breakers of the targets and procedures an
+// interceptor has seen
+type circuitBreakers struct {
+	policy  BreakerPolicy
+	metrics metrics.Metrics
+	logger  logging.Logger
+	now     func() time.Time
+
+	mu       sync.Mutex
+	breakers map[breakerKey]*breaker
+}
+
+func newCircuitBreakers(p BreakerPolicy, m metrics.Metrics, logger logging.Logger) *circuitBreakers {
+	return &circuitBreakers{
+		policy:   p.clamped(),
+		metrics:  m,
+		logger:   logger,
+		breakers: make(map[breakerKey]*breaker),
+		now:      time.Now,
+	}
+}
+
+type breaker struct {
+	state     breakerState
+	buckets   [breakerBuckets]bucket
+	openedAt  time.Time
+	probes    int // calls let through since the breaker turned half-open
+	successes int // of those, calls that succeeded
+}
+
+// bucket counts the calls of one slice of a breaker's window
+type bucket struct {
+	start    time.Time
+	total    int
+	failures int
+}
+
+type outcome int
+
+const (
+	outcomeSuccess outcome = iota
+	outcomeFailure
+	outcomeIgnored // the caller gave up; says nothing about the server
+)
+
+func (cb *circuitBreakers) outcome(ctx context.Context, err error) outcome {
+	switch {
+	case err == nil:
+		return outcomeSuccess
+	case connect.CodeOf(err) == connect.CodeCanceled || errors.Is(ctx.Err(), context.Canceled):
+		return outcomeIgnored
+	case slices.Contains(cb.policy.Codes, connect.CodeOf(err)):
+		return outcomeFailure
+	}
+	return outcomeSuccess
+}
+
+// allow reports whether a call may go through, and whether it is a probe of a
+// half-open breaker
+func (cb *circuitBreakers) allow(key breakerKey) (probe, ok bool) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	b := cb.breaker(key)
+	if b.state == stateOpen && cb.now().Sub(b.openedAt) >= cb.policy.OpenDuration {
+		cb.logger.Info("Circuit breaker half-open, probing", "target", key.target, "procedure", key.procedure)
+		cb.transition(key, b, stateHalfOpen)
+	}
+	switch b.state {
+	case stateClosed:
+		return false, true
+	case stateHalfOpen:
+		if b.probes < cb.policy.HalfOpenProbes {
+			b.probes++
+			return true, true
+		}
+	}
+	return false, false
+}
+
+// record counts the outcome of a call let through by allow
+func (cb *circuitBreakers) record(key breakerKey, probe bool, o outcome) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	b := cb.breaker(key)
+	switch {
+	case b.state == stateClosed && o != outcomeIgnored:
+		bk := b.bucket(cb.now(), cb.policy.Window)
+		bk.total++
+		if o == outcomeFailure {
+			bk.failures++
+		}
+		total, failures := b.counts(cb.now(), cb.policy.Window)
+		if total >= cb.policy.MinRequests && float64(failures) >= cb.policy.FailureRatio*float64(total) {
+			cb.logger.Warn("Circuit breaker opened", "target", key.target, "procedure", key.procedure,
+				"calls", total, "failures", failures, "open_duration", cb.policy.OpenDuration)
+			cb.transition(key, b, stateOpen)
+		}
+	case b.state == stateHalfOpen && probe:
+		switch o {
+		case outcomeIgnored:
+			// Let another call probe in its place
+			b.probes--
+		case outcomeFailure:
+			cb.logger.Warn("Circuit breaker probe failed, reopening", "target", key.target, "procedure", key.procedure)
+			cb.transition(key, b, stateOpen)
+		case outcomeSuccess:
+			b.successes++
+			if b.successes >= cb.policy.HalfOpenProbes {
+				cb.logger.Info("Circuit breaker closed", "target", key.target, "procedure", key.procedure)
+				cb.transition(key, b, stateClosed)
+			}
+		}
+	}
+}
+
+// breaker returns the breaker of key, creating a closed one if needed
+func (cb *circuitBreakers) breaker(key breakerKey) *breaker {
+	b, ok := cb.breakers[key]
+	if !ok {
+		b = &breaker{}
+		cb.breakers[key] = b
+		cb.export(key, stateClosed)
+	}
+	return b
+}
+
+func (cb *circuitBreakers) transition(key breakerKey, b *breaker, state breakerState) {
+	b.state = state
+	b.probes, b.successes = 0, 0
+	switch state {
+	case stateOpen:
+		b.openedAt = cb.now()
+	case stateClosed:
+		b.buckets = [breakerBuckets]bucket{}
+	}
+	cb.export(key, state)
+}
+
+func (cb *circuitBreakers) export(key breakerKey, state breakerState) {
+	for s, name := range breakerStateNames {
+		v := 0.0
+		if breakerState(s) == state {
+			v = 1
+		}
+		cb.metrics.Gauge(CircuitStateMetric, v, "target", key.target, "procedure", key.procedure, "state", name)
+	}
+}
+
+// bucket returns the bucket counting calls at now, emptying it if it last
+// counted an earlier slice of the window
+func (b *breaker) bucket(now time.Time, window time.Duration) *bucket {
+	width := window / breakerBuckets
+	if width <= 0 {
+		width = 1
+	}
+	start := now.Truncate(width)
+	bk := &b.buckets[(start.UnixNano()/int64(width))%breakerBuckets]
+	if !bk.start.Equal(start) {
+		*bk = bucket{start: start}
+	}
+	return bk
+}
+
+// counts sums the calls and failures of the buckets within the window
+func (b *breaker) counts(now time.Time, window time.Duration) (total, failures int) {
+	for _, bk := range b.buckets {
+		if now.Sub(bk.start) < window {
+			total += bk.total
+			failures += bk.failures
+		}
+	}
+	return total, failures
+}
@@ -0,0 +1,207 @@
+package connectrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+)
+
+var (
+	errUnavailable = connect.NewError(connect.CodeUnavailable, errors.New("down"))
+	errInvalid     = connect.NewError(connect.CodeInvalidArgument, errors.New("bad request"))
+	errCanceled    = connect.NewError(connect.CodeCanceled, errors.New("caller gave up"))
+)
+
+// testBreakers is a set of circuit breakers with a settable clock
+type testBreakers struct {
+	*circuitBreakers
+	now time.Time
+	key breakerKey
+}
+
+func newTestBreakers(p BreakerPolicy) *testBreakers {
+	logger := logging.NewSlogLoggerWithWriter("test", "debug", "json", io.Discard)
+	tb := &testBreakers{
+		circuitBreakers: newCircuitBreakers(p, metrics.NewNoopMetrics("test"), logger),
+		now:             time.Unix(1000, 0),
+		key:             breakerKey{target: "users:8080", procedure: "/test.v1.TestService/Get"},
+	}
+	tb.circuitBreakers.now = func() time.Time { return tb.now }
+	return tb
+}
+
+// call makes a call ending with err through the breaker, reporting whether it was let through
+func (tb *testBreakers) call(err error) bool {
+	probe, ok := tb.allow(tb.key)
+	if ok {
+		tb.record(tb.key, probe, tb.outcome(context.Background(), err))
+	}
+	return ok
+}
+
+func (tb *testBreakers) state() string {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	return breakerStateNames[tb.breaker(tb.key).state]
+}
+
+func (tb *testBreakers) assertState(t *testing.T, want string) {
+	t.Helper()
+	if got := tb.state(); got != want {
+		t.Fatalf("breaker is %s, want %s", got, want)
+	}
+}
+
+func testPolicy() BreakerPolicy {
+	return BreakerPolicy{
+		Window:         10 * time.Second,
+		MinRequests:    4,
+		FailureRatio:   0.5,
+		OpenDuration:   5 * time.Second,
+		HalfOpenProbes: 2,
+		Codes:          []connect.Code{connect.CodeUnavailable},
+	}
+}
+
+func TestBreakerStates(t *testing.T) {
+	tb := newTestBreakers(testPolicy())
+
+	// Closed until the window holds enough calls
+	for range 3 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "closed")
+	tb.call(errUnavailable)
+	tb.assertState(t, "open")
+
+	// Open: calls are rejected until OpenDuration has passed
+	tb.now = tb.now.Add(5*time.Second - time.Millisecond)
+	if tb.call(nil) {
+		t.Fatal("an open breaker let a call through")
+	}
+
+	// Half-open: only the probes go through, and one failure reopens
+	tb.now = tb.now.Add(time.Millisecond)
+	probe, ok := tb.allow(tb.key)
+	if !ok || !probe {
+		t.Fatal("a half-open breaker rejected the first probe")
+	}
+	tb.assertState(t, "half_open")
+	if !tb.call(nil) {
+		t.Fatal("a half-open breaker rejected the second probe")
+	}
+	if tb.call(nil) {
+		t.Fatal("a half-open breaker let more calls through than its probes")
+	}
+	tb.record(tb.key, probe, outcomeFailure)
+	tb.assertState(t, "open")
+
+	// Reopening restarts OpenDuration
+	tb.now = tb.now.Add(time.Second)
+	if tb.call(nil) {
+		t.Fatal("a reopened breaker let a call through")
+	}
+
+	// Half-open again: once every probe succeeded the breaker closes
+	tb.now = tb.now.Add(5 * time.Second)
+	tb.call(nil)
+	tb.assertState(t, "half_open")
+	tb.call(nil)
+	tb.assertState(t, "closed")
+
+	// Closing forgets the failures that opened it
+	for range 3 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "closed")
+}
+
+func TestBreakerFailureRatio(t *testing.T) {
+	tb := newTestBreakers(testPolicy())
+	// Codes outside the policy show the server is up
+	for range 5 {
+		tb.call(errInvalid)
+	}
+	for range 4 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "closed") // 4 of 9 failed
+	tb.call(errUnavailable)
+	tb.assertState(t, "open") // 5 of 10
+}
+
+func TestBreakerWindowExpires(t *testing.T) {
+	tb := newTestBreakers(testPolicy())
+	for range 3 {
+		tb.call(errUnavailable)
+	}
+	// Failures older than the window no longer count
+	tb.now = tb.now.Add(10 * time.Second)
+	tb.call(errUnavailable)
+	tb.assertState(t, "closed")
+	for range 2 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "closed")
+	tb.call(errUnavailable)
+	tb.assertState(t, "open")
+}
+
+func TestBreakerIgnoresCanceledCalls(t *testing.T) {
+	tb := newTestBreakers(testPolicy())
+	for range 10 {
+		tb.call(errCanceled)
+	}
+	tb.assertState(t, "closed")
+	for range 4 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "open")
+
+	// A canceled probe lets another call probe in its place
+	tb.now = tb.now.Add(5 * time.Second)
+	tb.call(errCanceled)
+	tb.call(errCanceled)
+	tb.assertState(t, "half_open")
+	tb.call(nil)
+	tb.call(nil)
+	tb.assertState(t, "closed")
+}
+
+func TestBreakerPolicyClamped(t *testing.T) {
+	// Without clamping, no call would ever count in a zero window, and a
+	// half-open breaker without probes would reject calls forever
+	tb := newTestBreakers(BreakerPolicy{
+		FailureRatio: 0.5,
+		OpenDuration: time.Second,
+		Codes:        []connect.Code{connect.CodeUnavailable},
+	})
+	if p := tb.policy; p.Window != 10*time.Second || p.MinRequests != 1 || p.HalfOpenProbes != 1 {
+		t.Fatalf("policy = %+v", p)
+	}
+	tb.call(errUnavailable)
+	tb.assertState(t, "open")
+	tb.now = tb.now.Add(time.Second)
+	if !tb.call(nil) {
+		t.Fatal("the half-open breaker rejected its probe")
+	}
+	tb.assertState(t, "closed")
+}
+
+func TestBreakersPerProcedure(t *testing.T) {
+	tb := newTestBreakers(testPolicy())
+	for range 4 {
+		tb.call(errUnavailable)
+	}
+	tb.assertState(t, "open")
+	tb.key.procedure = "/test.v1.TestService/List"
+	if !tb.call(nil) {
+		t.Error("a breaker of another procedure rejected a call")
+	}
+}
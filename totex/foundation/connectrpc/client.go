@@ -0,0 +1,162 @@
+package connectrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// ClientDurationMetric is the histogram of calls made by clients, labelled by procedure and code
+const ClientDurationMetric = "rpc_client_duration_seconds"
+
+// Transport is an http.RoundTripper for ConnectRPC clients that passes the
+// baggage and current span of each request's context on to the server, so
+// a handler's calls to other services continue its trace and baggage:
+//
+//	httpClient := &http.Client{Transport: &connectrpc.Transport{Tracer: app.Tracer()}}
+//	orders := orderv1connect.NewOrderServiceClient(httpClient, "http://order-service:8080")
+type Transport struct {
+	// Base sends the requests; http.DefaultTransport if nil
+	Base http.RoundTripper
+	// Tracer injects the current span; baggage is passed on without one
+	Tracer tracing.Tracer
+}
+
+func (t *Transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx := r.Context()
+	span := tracing.SpanFromContext(ctx)
+	baggage := tracing.BaggageFromContext(ctx)
+	if (span != nil && t.Tracer != nil) || baggage.Len() > 0 {
+		// A RoundTripper must not modify the caller's request
+		r = r.Clone(ctx)
+		if span != nil && t.Tracer != nil {
+			t.Tracer.Inject(span, tracing.HTTPHeaders, r.Header)
+		}
+		// Set after Inject, as the context's baggage is newer than the span's
+		tracing.InjectBaggage(ctx, r.Header)
+	}
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(r)
+}
+
+// NewClientInterceptor returns a client interceptor that traces every call
+// with a client span, passing its trace context and the context's baggage on
+// to the server, and records its duration in ClientDurationMetric
+func NewClientInterceptor(t tracing.Tracer, m metrics.Metrics) connect.Interceptor {
+	return &clientInterceptor{tracer: t, metrics: m}
+}
+
+type clientInterceptor struct {
+	tracer  tracing.Tracer
+	metrics metrics.Metrics
+}
+
+func (i *clientInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		if !req.Spec().IsClient {
+			return next(ctx, req)
+		}
+		span, ctx, finish := i.start(ctx, req.Spec(), req.Header())
+		defer span.Finish()
+		resp, err := next(ctx, req)
+		finish(err)
+		return resp, err
+	}
+}
+
+func (i *clientInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return func(ctx context.Context, spec connect.Spec) connect.StreamingClientConn {
+		// Headers are only sent with the first message, so they can be set
+		// once the stream is created
+		header := make(http.Header)
+		span, ctx, finish := i.start(ctx, spec, header)
+		conn := next(ctx, spec)
+		for k, v := range header {
+			conn.RequestHeader()[k] = v
+		}
+		return &instrumentedClientConn{StreamingClientConn: conn, finish: func(err error) {
+			finish(err)
+			span.Finish()
+		}}
+	}
+}
+
+func (i *clientInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return next
+}
+
+// start starts the client span of a call and writes its trace context and
+// baggage to header. finish records the outcome of the call.
+func (i *clientInterceptor) start(ctx context.Context, spec connect.Spec, header http.Header) (tracing.Span, context.Context, func(error)) {
+	start := time.Now()
+	span, ctx := tracing.StartSpanFromContext(ctx, i.tracer, strings.TrimPrefix(spec.Procedure, "/"))
+	span.SetTag(tracing.SpanKindTag, "client")
+	span.SetTag("rpc.procedure", spec.Procedure)
+	i.tracer.Inject(span, tracing.HTTPHeaders, header)
+	tracing.InjectBaggage(ctx, header)
+	return span, ctx, func(err error) {
+		code := codeOf(err)
+		span.SetTag("rpc.code", code)
+		if err != nil {
+			span.SetError(err)
+		}
+		i.metrics.HistogramContext(ctx, ClientDurationMetric, time.Since(start).Seconds(), "procedure", spec.Procedure, "code", code)
+	}
+}
+
+// instrumentedClientConn reports the outcome of a stream once its response is closed
+type instrumentedClientConn struct {
+	connect.StreamingClientConn
+	finish func(error)
+
+	once sync.Once
+	err  error
+}
+
+func (c *instrumentedClientConn) Receive(msg any) error {
+	err := c.StreamingClientConn.Receive(msg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		c.err = err
+	}
+	return err
+}
+
+func (c *instrumentedClientConn) CloseResponse() error {
+	err := c.StreamingClientConn.CloseResponse()
+	c.once.Do(func() { c.finish(c.err) })
+	return err
+}
+
+// NewTimeoutInterceptor returns a client interceptor that bounds unary calls,
+// retries included, to timeout unless the caller's context has an earlier deadline
+func NewTimeoutInterceptor(timeout time.Duration) connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if timeout <= 0 || !req.Spec().IsClient {
+				return next(ctx, req)
+			}
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return next(ctx, req)
+		}
+	})
+}
+
+// codeOf returns the Connect code name of a call's error, "ok" for nil
+func codeOf(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return connect.CodeOf(err).String()
+}
@@ -0,0 +1,255 @@
+package connectrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/foundationtest"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// flakyServer fails the first failures calls to unaryProcedure with code;
+// the call after them takes slow to answer
+type flakyServer struct {
+	url      string
+	calls    atomic.Int64
+	failures int64
+	code     connect.Code
+	slow     time.Duration
+	// cancelled counts calls whose request was cancelled before they answered
+	cancelled atomic.Int64
+}
+
+func startFlaky(t *testing.T, failures int64, code connect.Code, slow time.Duration) *flakyServer {
+	t.Helper()
+	s := &flakyServer{failures: failures, code: code, slow: slow}
+	handler := func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+		n := s.calls.Add(1)
+		if n <= s.failures {
+			return nil, connect.NewError(s.code, errors.New("try again"))
+		}
+		if s.slow > 0 && n == s.failures+1 {
+			select {
+			case <-time.After(s.slow):
+			case <-ctx.Done():
+				s.cancelled.Add(1)
+				return nil, ctx.Err()
+			}
+		}
+		return connect.NewResponse(req.Msg), nil
+	}
+	mux := http.NewServeMux()
+	mux.Handle(unaryProcedure, connect.NewUnaryHandler(unaryProcedure, handler))
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	s.url = srv.URL
+	return s
+}
+
+// fastRetries retries unavailable, and deadline_exceeded for idempotent
+// procedures, three times without waiting long
+var fastRetries = connectrpc.RetryPolicy{
+	MaxAttempts:     4,
+	InitialBackoff:  time.Millisecond,
+	MaxBackoff:      2 * time.Millisecond,
+	Codes:           []connect.Code{connect.CodeUnavailable},
+	IdempotentCodes: []connect.Code{connect.CodeDeadlineExceeded},
+}
+
+func newTestClient(s *flakyServer, httpClient connect.HTTPClient, opts ...connect.ClientOption) *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue] {
+	return connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](httpClient, s.url+unaryProcedure, opts...)
+}
+
+func TestRetryInterceptor(t *testing.T) {
+	tests := []struct {
+		name      string
+		failures  int64
+		code      connect.Code
+		opts      []connect.ClientOption
+		wantCalls int64
+		wantCode  string
+	}{
+		{"recovers", 2, connect.CodeUnavailable, nil, 3, "ok"},
+		{"gives up after max attempts", 10, connect.CodeUnavailable, nil, 4, "unavailable"},
+		{"does not retry other codes", 10, connect.CodeInvalidArgument, nil, 1, "invalid_argument"},
+		{"does not retry a non-idempotent procedure that may have run", 10, connect.CodeDeadlineExceeded, nil, 1, "deadline_exceeded"},
+		{"retries an idempotent procedure", 1, connect.CodeDeadlineExceeded,
+			[]connect.ClientOption{connect.WithIdempotency(connect.IdempotencyIdempotent)}, 2, "ok"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := startFlaky(t, tt.failures, tt.code, 0)
+			m := foundationtest.NewMetrics()
+			opts := append([]connect.ClientOption{connect.WithInterceptors(connectrpc.NewRetryInterceptor(fastRetries, nil, m))}, tt.opts...)
+			_, err := newTestClient(s, http.DefaultClient, opts...).CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi")))
+
+			if got := codeName(err); got != tt.wantCode {
+				t.Errorf("call ended with %s, want %s", got, tt.wantCode)
+			}
+			if got := s.calls.Load(); got != tt.wantCalls {
+				t.Errorf("%d calls reached the server, want %d", got, tt.wantCalls)
+			}
+			if got := m.CounterValue(connectrpc.ClientRetriesMetric, "procedure", unaryProcedure); got != float64(tt.wantCalls-1) {
+				t.Errorf("%s = %v, want %d", connectrpc.ClientRetriesMetric, got, tt.wantCalls-1)
+			}
+		})
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	s := startFlaky(t, 100, connect.CodeUnavailable, 0)
+	m := foundationtest.NewMetrics()
+	// A full budget without refills holds 10 retries; each call adds none
+	budget := connectrpc.NewRetryBudget(0, 0)
+	policy := fastRetries
+	policy.MaxAttempts = 100
+	client := newTestClient(s, http.DefaultClient, connect.WithInterceptors(connectrpc.NewRetryInterceptor(policy, budget, m)))
+
+	client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi")))
+	if got := s.calls.Load(); got != 11 {
+		t.Errorf("%d calls reached the server, want the first and 10 retries", got)
+	}
+	client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi")))
+	if got := s.calls.Load(); got != 12 {
+		t.Errorf("%d calls reached the server, want no retries once the budget is spent", got)
+	}
+	if got := m.CounterValue(connectrpc.ClientRetryBudgetExhaustedMetric, "procedure", unaryProcedure); got != 2 {
+		t.Errorf("%s = %v, want 2", connectrpc.ClientRetryBudgetExhaustedMetric, got)
+	}
+}
+
+func TestRetryStopsWithContext(t *testing.T) {
+	s := startFlaky(t, 100, connect.CodeUnavailable, 0)
+	policy := fastRetries
+	policy.MaxAttempts = 100
+	policy.InitialBackoff, policy.MaxBackoff = time.Hour, time.Hour
+	client := newTestClient(s, http.DefaultClient,
+		connect.WithInterceptors(connectrpc.NewRetryInterceptor(policy, nil, foundationtest.NewMetrics())))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	_, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("hi")))
+	if codeName(err) != "unavailable" || s.calls.Load() != 1 {
+		t.Errorf("call ended with %v after %d calls, want the first error", err, s.calls.Load())
+	}
+}
+
+func TestHedgedCall(t *testing.T) {
+	// The first call hangs; the hedge sent after the delay answers
+	s := startFlaky(t, 0, connect.CodeUnavailable, time.Second)
+	m := foundationtest.NewMetrics()
+	policy := connectrpc.HedgingPolicy{Delay: 10 * time.Millisecond, MaxAttempts: 2, Procedures: []string{unaryProcedure}}
+	httpClient := &http.Client{Transport: &connectrpc.HedgingTransport{Metrics: m}}
+	client := newTestClient(s, httpClient, connect.WithInterceptors(connectrpc.NewHedgingInterceptor(policy)))
+
+	start := time.Now()
+	if _, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi"))); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > s.slow/2 {
+		t.Errorf("call took %v, want the hedge's answer", elapsed)
+	}
+	if got := m.CounterValue(connectrpc.ClientHedgesMetric, "procedure", unaryProcedure); got != 1 {
+		t.Errorf("%s = %v, want 1", connectrpc.ClientHedgesMetric, got)
+	}
+	// The losing attempt is cancelled on the server
+	deadline := time.Now().Add(time.Second)
+	for s.cancelled.Load() == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the slow attempt was not cancelled")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestHedgingOnlyMarksSafeProcedures(t *testing.T) {
+	s := startFlaky(t, 0, connect.CodeUnavailable, 30*time.Millisecond)
+	m := foundationtest.NewMetrics()
+	policy := connectrpc.HedgingPolicy{Delay: time.Millisecond, MaxAttempts: 3}
+	httpClient := &http.Client{Transport: &connectrpc.HedgingTransport{Metrics: m}}
+	client := newTestClient(s, httpClient, connect.WithInterceptors(connectrpc.NewHedgingInterceptor(policy)))
+
+	if _, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi"))); err != nil {
+		t.Fatal(err)
+	}
+	if s.calls.Load() != 1 || m.CounterValue(connectrpc.ClientHedgesMetric) != 0 {
+		t.Errorf("a procedure with side effects was hedged: %d calls", s.calls.Load())
+	}
+}
+
+func TestClientInterceptor(t *testing.T) {
+	s := startFlaky(t, 1, connect.CodeUnavailable, 0)
+	tracer := foundationtest.NewTracer()
+	m := foundationtest.NewMetrics()
+	client := newTestClient(s, http.DefaultClient, connect.WithInterceptors(
+		connectrpc.NewClientInterceptor(tracer, m),
+		connectrpc.NewRetryInterceptor(fastRetries, nil, m),
+	))
+
+	parent := tracer.StartSpan("handler")
+	ctx := tracing.ContextWithSpan(context.Background(), parent)
+	if _, err := client.CallUnary(ctx, connect.NewRequest(wrapperspb.String("hi"))); err != nil {
+		t.Fatal(err)
+	}
+	parent.Finish()
+
+	// One span and one observation cover the call and its retry
+	tracer.AssertSpan(t, unaryProcedure[1:], tracing.SpanKindTag, "client", "rpc.code", "ok", "rpc.retries", "1")
+	tracer.AssertPath(t, "handler", unaryProcedure[1:])
+	m.AssertObserved(t, connectrpc.ClientDurationMetric, "procedure", unaryProcedure, "code", "ok")
+	if n := len(m.Observations(connectrpc.ClientDurationMetric)); n != 1 {
+		t.Errorf("%d duration observations, want 1", n)
+	}
+}
+
+func TestTimeoutInterceptor(t *testing.T) {
+	s := startFlaky(t, 0, connect.CodeUnavailable, time.Second)
+	client := newTestClient(s, http.DefaultClient, connect.WithInterceptors(connectrpc.NewTimeoutInterceptor(20*time.Millisecond)))
+
+	start := time.Now()
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi")))
+	if codeName(err) != "deadline_exceeded" || time.Since(start) > s.slow/2 {
+		t.Errorf("call ended with %v after %v, want the client timeout", err, time.Since(start))
+	}
+}
+
+func codeName(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return connect.CodeOf(err).String()
+}
+
+func TestCircuitBreakerInterceptor(t *testing.T) {
+	s := startFlaky(t, 100, connect.CodeUnavailable, 0)
+	m := foundationtest.NewMetrics()
+	logger := foundationtest.NewLogger()
+	policy := connectrpc.DefaultBreakerPolicy()
+	policy.MinRequests = 2
+	// The breaker sees every attempt, and rejections are not retried
+	client := newTestClient(s, http.DefaultClient, connect.WithInterceptors(
+		connectrpc.NewRetryInterceptor(fastRetries, nil, m),
+		connectrpc.NewCircuitBreakerInterceptor(policy, m, logger),
+	))
+
+	_, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hi")))
+	if !errors.Is(err, connectrpc.ErrCircuitOpen) || codeName(err) != "unavailable" {
+		t.Fatalf("call ended with %v, want the breaker to open on the second attempt", err)
+	}
+	if got := s.calls.Load(); got != 2 {
+		t.Errorf("%d calls reached the server, want 2", got)
+	}
+	logger.AssertLogged(t, "Circuit breaker opened", "procedure", unaryProcedure, "failures", 2)
+	m.AssertGauge(t, connectrpc.CircuitStateMetric, 1, "procedure", unaryProcedure, "state", "open")
+	m.AssertGauge(t, connectrpc.CircuitStateMetric, 0, "procedure", unaryProcedure, "state", "closed")
+	m.AssertCounter(t, connectrpc.CircuitRejectedMetric, 1, "procedure", unaryProcedure)
+}
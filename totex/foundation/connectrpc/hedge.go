@@ -0,0 +1,198 @@
+package connectrpc
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/metrics"
+)
+
+// ClientHedgesMetric counts hedged requests sent, labelled by procedure
+const ClientHedgesMetric = "rpc_client_hedges_total"
+
+// HedgingPolicy configures hedged requests: when a unary call that is safe to
+// repeat has not been answered after Delay, another attempt is sent, up to
+// MaxAttempts in flight, and the first answer wins. This trades extra load
+// for lower tail latency.
+type HedgingPolicy struct {
+	Delay       time.Duration // wait before each further attempt; 0 disables hedging
+	MaxAttempts int           // attempts per call, including the first
+	// Procedures are hedged in addition to those declared idempotent or free
+	// of side effects, e.g. "/user.v1.UserService/GetUser"
+	Procedures []string
+}
+
+// hedgeable reports whether calls to spec may be hedged under p
+func (p HedgingPolicy) hedgeable(spec connect.Spec) bool {
+	if p.Delay <= 0 || p.MaxAttempts <= 1 || spec.StreamType != connect.StreamTypeUnary {
+		return false
+	}
+	return spec.IdempotencyLevel != connect.IdempotencyUnknown || slices.Contains(p.Procedures, spec.Procedure)
+}
+
+type hedgeKey struct{}
+
+// hedge is what HedgingTransport needs to know about a call it may hedge
+type hedge struct {
+	policy    HedgingPolicy
+	procedure string
+}
+
+// NewHedgingInterceptor returns a client interceptor that marks the unary
+// calls p allows to be hedged. Connect writes to a request's headers as it
+// sends it, so attempts cannot share one in flight: the hedged requests
+// themselves are sent by a HedgingTransport under the client.
+func NewHedgingInterceptor(p HedgingPolicy) connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			if spec := req.Spec(); spec.IsClient && p.hedgeable(spec) {
+				ctx = context.WithValue(ctx, hedgeKey{}, &hedge{policy: p, procedure: spec.Procedure})
+			}
+			return next(ctx, req)
+		}
+	})
+}
+
+// HedgingTransport is an http.RoundTripper that hedges the requests of calls
+// marked by a hedging interceptor and passes other requests on to Base. An
+// attempt answered with a 429 or 5xx status or a transport error does not win
+// while others are in flight.
+type HedgingTransport struct {
+	// Base sends the requests; http.DefaultTransport if nil
+	Base http.RoundTripper
+	// Budget, if set, is drawn from for every hedged request
+	Budget *RetryBudget
+	// Metrics, if set, counts hedged requests in ClientHedgesMetric
+	Metrics metrics.Metrics
+}
+
+// attempt is the outcome of one hedged request
+type attempt struct {
+	n      int // index of the attempt
+	resp   *http.Response
+	err    error
+	cancel context.CancelFunc
+}
+
+// failed reports whether the attempt should give way to others still in flight
+func (a attempt) failed() bool {
+	return a.err != nil || a.resp.StatusCode == http.StatusTooManyRequests || a.resp.StatusCode >= 500
+}
+
+// discard releases the attempt's response and connection
+func (a attempt) discard() {
+	if a.resp != nil {
+		a.resp.Body.Close()
+	}
+	a.cancel()
+}
+
+func (t *HedgingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	h, _ := r.Context().Value(hedgeKey{}).(*hedge)
+	if h == nil {
+		return base.RoundTrip(r)
+	}
+	m := t.Metrics
+	if m == nil {
+		m = metrics.NewDefaultMetrics()
+	}
+	// Unary request bodies are small and complete once sent, so every attempt
+	// can replay the same bytes
+	var body []byte
+	if r.Body != nil {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	results := make(chan attempt, h.policy.MaxAttempts)
+	var cancels []context.CancelFunc
+	send := func() {
+		ctx, cancel := context.WithCancel(r.Context())
+		n := len(cancels)
+		cancels = append(cancels, cancel)
+		req := r.Clone(ctx)
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+		req.ContentLength = int64(len(body))
+		go func() {
+			resp, err := base.RoundTrip(req)
+			results <- attempt{n: n, resp: resp, err: err, cancel: cancel}
+		}()
+	}
+
+	send()
+	inFlight := 1
+	timer := time.NewTimer(h.policy.Delay)
+	defer timer.Stop()
+	var last attempt
+	for {
+		select {
+		case a := <-results:
+			inFlight--
+			if !a.failed() || inFlight == 0 {
+				if last.cancel != nil {
+					last.discard()
+				}
+				// Cancel the attempts still in flight and release their responses
+				for n, cancel := range cancels {
+					if n != a.n {
+						cancel()
+					}
+				}
+				go func(n int) {
+					for range n {
+						(<-results).discard()
+					}
+				}(inFlight)
+				if a.err != nil {
+					a.cancel()
+					return nil, a.err
+				}
+				a.resp.Body = &cancelOnClose{ReadCloser: a.resp.Body, cancel: a.cancel}
+				return a.resp, nil
+			}
+			if last.cancel != nil {
+				last.discard()
+			}
+			last = a
+		case <-timer.C:
+			if len(cancels) == h.policy.MaxAttempts {
+				continue
+			}
+			if !t.Budget.withdraw() {
+				m.Counter(ClientRetryBudgetExhaustedMetric, 1, "procedure", h.procedure)
+				continue
+			}
+			m.CounterContext(r.Context(), ClientHedgesMetric, 1, "procedure", h.procedure)
+			send()
+			inFlight++
+			timer.Reset(h.policy.Delay)
+		}
+	}
+}
+
+// cancelOnClose cancels the context of the winning attempt once its response
+// has been read
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
@@ -0,0 +1,235 @@
+package connectrpc
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackedBody records whether a response body was closed
+type trackedBody struct {
+	io.Reader
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return nil
+}
+
+// fakeBase answers the n-th hedged request with attempts[n], recording the
+// request contexts and the bodies it returns
+type fakeBase struct {
+	attempts []func(ctx context.Context) (int, string, error)
+
+	mu     sync.Mutex
+	ctxs   []context.Context
+	bodies []*trackedBody
+}
+
+func (f *fakeBase) RoundTrip(r *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	n := len(f.ctxs)
+	f.ctxs = append(f.ctxs, r.Context())
+	f.mu.Unlock()
+
+	if body, _ := io.ReadAll(r.Body); string(body) != "request" {
+		return nil, errors.New("attempt sent without the request body")
+	}
+	status, text, err := f.attempts[n](r.Context())
+	if err != nil {
+		return nil, err
+	}
+	body := &trackedBody{Reader: strings.NewReader(text)}
+	f.mu.Lock()
+	f.bodies = append(f.bodies, body)
+	f.mu.Unlock()
+	return &http.Response{StatusCode: status, Body: body, Header: make(http.Header)}, nil
+}
+
+func (f *fakeBase) sent() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.ctxs)
+}
+
+// eventually polls cond until it holds or a second has passed
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// hedgedRequest is a request marked for hedging under p
+func hedgedRequest(p HedgingPolicy) *http.Request {
+	ctx := context.WithValue(context.Background(), hedgeKey{}, &hedge{policy: p, procedure: "/test.v1.TestService/Get"})
+	r, _ := http.NewRequestWithContext(ctx, http.MethodPost, "http://users/test.v1.TestService/Get", strings.NewReader("request"))
+	return r
+}
+
+// respond answers at once
+func respond(status int, body string) func(context.Context) (int, string, error) {
+	return func(context.Context) (int, string, error) { return status, body, nil }
+}
+
+// hang answers only once its request is cancelled
+func hang(ctx context.Context) (int, string, error) {
+	<-ctx.Done()
+	return 0, "", ctx.Err()
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return string(b)
+}
+
+func TestHedgingFirstSuccessWins(t *testing.T) {
+	base := &fakeBase{attempts: []func(context.Context) (int, string, error){hang, respond(200, "second"), hang}}
+	transport := &HedgingTransport{Base: base}
+	resp, err := transport.RoundTrip(hedgedRequest(HedgingPolicy{Delay: 5 * time.Millisecond, MaxAttempts: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readBody(t, resp); got != "second" {
+		t.Errorf("body = %q, want the hedged answer", got)
+	}
+
+	// The losers are cancelled and, once the winner's body is closed, so is it
+	base.mu.Lock()
+	defer base.mu.Unlock()
+	for n, ctx := range base.ctxs {
+		if ctx.Err() == nil {
+			t.Errorf("attempt %d not cancelled", n)
+		}
+	}
+}
+
+func TestHedgingClosesLateAnswers(t *testing.T) {
+	release := make(chan struct{})
+	late := func(ctx context.Context) (int, string, error) {
+		<-release // answers after the winner, ignoring the cancellation
+		return 200, "late", nil
+	}
+	base := &fakeBase{attempts: []func(context.Context) (int, string, error){late, respond(200, "fast")}}
+	transport := &HedgingTransport{Base: base}
+	resp, err := transport.RoundTrip(hedgedRequest(HedgingPolicy{Delay: 5 * time.Millisecond, MaxAttempts: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readBody(t, resp); got != "fast" {
+		t.Errorf("body = %q", got)
+	}
+	close(release)
+
+	eventually(t, "the late answer is closed", func() bool {
+		base.mu.Lock()
+		defer base.mu.Unlock()
+		for _, b := range base.bodies {
+			if !b.closed.Load() {
+				return false
+			}
+		}
+		return len(base.bodies) == 2
+	})
+}
+
+func TestHedgingFailuresGiveWay(t *testing.T) {
+	// Every attempt fails once all three are in flight: the last answer is
+	// returned and the others are released
+	var started sync.WaitGroup
+	started.Add(3)
+	fail := func(status int) func(context.Context) (int, string, error) {
+		return func(context.Context) (int, string, error) {
+			started.Done()
+			started.Wait()
+			return status, http.StatusText(status), nil
+		}
+	}
+	base := &fakeBase{attempts: []func(context.Context) (int, string, error){
+		fail(http.StatusServiceUnavailable), fail(http.StatusTooManyRequests), fail(http.StatusBadGateway),
+	}}
+	transport := &HedgingTransport{Base: base}
+	resp, err := transport.RoundTrip(hedgedRequest(HedgingPolicy{Delay: time.Millisecond, MaxAttempts: 3}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode < 429 {
+		t.Errorf("status = %d, want one of the failures", resp.StatusCode)
+	}
+	resp.Body.Close()
+
+	base.mu.Lock()
+	defer base.mu.Unlock()
+	for n, b := range base.bodies {
+		if !b.closed.Load() {
+			t.Errorf("body of attempt %d not closed", n)
+		}
+	}
+}
+
+func TestHedgingWithinBudget(t *testing.T) {
+	budget := NewRetryBudget(0, 0)
+	for budget.withdraw() {
+	}
+	base := &fakeBase{attempts: []func(context.Context) (int, string, error){
+		func(ctx context.Context) (int, string, error) {
+			time.Sleep(20 * time.Millisecond)
+			return 200, "first", nil
+		},
+		respond(200, "hedge"),
+	}}
+	transport := &HedgingTransport{Base: base, Budget: budget}
+	resp, err := transport.RoundTrip(hedgedRequest(HedgingPolicy{Delay: time.Millisecond, MaxAttempts: 2}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readBody(t, resp); got != "first" || base.sent() != 1 {
+		t.Errorf("body = %q after %d requests, want no hedge with the budget spent", got, base.sent())
+	}
+}
+
+func TestHedgingPassesUnmarkedRequests(t *testing.T) {
+	base := &fakeBase{attempts: []func(context.Context) (int, string, error){respond(200, "only")}}
+	r, _ := http.NewRequest(http.MethodPost, "http://users/test.v1.TestService/Create", strings.NewReader("request"))
+	resp, err := (&HedgingTransport{Base: base}).RoundTrip(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := readBody(t, resp); got != "only" || base.sent() != 1 {
+		t.Errorf("body = %q after %d requests", got, base.sent())
+	}
+}
+
+func TestHedgingLeavesNoGoroutines(t *testing.T) {
+	before := runtime.NumGoroutine()
+	for range 20 {
+		late := func(ctx context.Context) (int, string, error) {
+			<-ctx.Done()
+			return 200, "late", nil // answers although cancelled
+		}
+		base := &fakeBase{attempts: []func(context.Context) (int, string, error){late, respond(200, "fast"), hang}}
+		resp, err := (&HedgingTransport{Base: base}).RoundTrip(hedgedRequest(HedgingPolicy{Delay: time.Millisecond, MaxAttempts: 3}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		readBody(t, resp)
+	}
+	eventually(t, "the attempts' goroutines exit", func() bool { return runtime.NumGoroutine() <= before })
+}
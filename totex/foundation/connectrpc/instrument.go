@@ -0,0 +1,219 @@
+package connectrpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// ServerDurationMetric is the histogram of handled RPCs, labelled by procedure and code
+const ServerDurationMetric = "rpc_server_duration_seconds"
+
+// maxErrorBody bounds the response body captured to read a Connect error code
+const maxErrorBody = 1024
+
+// codeNames are the Connect names of the gRPC status codes, by number
+var codeNames = []string{
+	"ok", "canceled", "unknown", "invalid_argument", "deadline_exceeded", "not_found",
+	"already_exists", "permission_denied", "resource_exhausted", "failed_precondition",
+	"aborted", "out_of_range", "unimplemented", "internal", "unavailable", "data_loss",
+	"unauthenticated",
+}
+
+// RPCObserver is notified of every instrumented RPC once it completes, with
+// the procedure, the Connect code and the time the handler took
+type RPCObserver interface {
+	ObserveRPC(ctx context.Context, procedure, code string, duration time.Duration)
+}
+
+// EnableInstrumentation traces every RPC to a registered service, continuing
+// the caller's trace if t can extract it from the request headers, and
+// records its duration in ServerDurationMetric. The observation carries the
+// request context, so a sampled trace is attached to it as an exemplar.
+func (s *Server) EnableInstrumentation(t tracing.Tracer, m metrics.Metrics) {
+	s.tracer = t
+	s.metrics = m
+}
+
+// Interceptor returns the interceptor to pass to every service handler with
+// connect.WithInterceptors. It reports the Connect code of the error an RPC
+// returns to the instrumentation, which can otherwise only read it from the
+// HTTP response: Connect streaming and gRPC-Web responses are 200 OK with the
+// error at the end of the body.
+//
+//	path, handler := userv1connect.NewUserServiceHandler(svc,
+//		connect.WithInterceptors(server.Interceptor()))
+func (s *Server) Interceptor() connect.Interceptor {
+	return codeInterceptor{}
+}
+
+// AddRPCObserver registers o to be notified of instrumented RPCs; it must be
+// called before Start
+func (s *Server) AddRPCObserver(o RPCObserver) {
+	s.observers = append(s.observers, o)
+}
+
+// withInstrumentation starts a server span for each RPC and records its
+// duration and code once it completes
+func (s *Server) withInstrumentation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.isService(r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		procedure := r.URL.Path
+		start := time.Now()
+		ctx := r.Context()
+		if remote, err := s.tracer.Extract(tracing.HTTPHeaders, r.Header); err == nil && remote != nil {
+			// Only continue traces the tracer could read a span context for
+			if sc := tracing.SpanContextFromContext(remote.Context()); sc.IsValid() {
+				ctx = tracing.ContextWithSpan(ctx, remote)
+			}
+		}
+		span, ctx := tracing.StartSpanFromContext(ctx, s.tracer, strings.TrimPrefix(procedure, "/"))
+		defer span.Finish()
+		span.SetTag(tracing.SpanKindTag, "server")
+		span.SetTag("rpc.procedure", procedure)
+
+		result := &rpcResult{}
+		ctx = context.WithValue(ctx, rpcResultKey{}, result)
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK, captureErrors: true}
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		code := result.code
+		if code == "" {
+			// No interceptor ran: it is not installed, or the request failed before reaching it
+			code = rpcCode(rec)
+		}
+		if result.code == "" && code == "unimplemented" && rec.status == http.StatusNotFound {
+			// Keep unknown procedures from adding a series per requested path;
+			// a not_found error of a known procedure is also a 404
+			procedure = "unknown"
+		}
+		span.SetTag("rpc.code", code)
+		if code != "ok" {
+			span.SetError(&rpcError{code: code})
+		}
+		duration := time.Since(start)
+		s.metrics.HistogramContext(ctx, ServerDurationMetric, duration.Seconds(), "procedure", procedure, "code", code)
+		for _, o := range s.observers {
+			o.ObserveRPC(ctx, procedure, code, duration)
+		}
+	})
+}
+
+// isService reports whether path belongs to a handler registered for a service
+func (s *Server) isService(path string) bool {
+	for _, prefix := range s.services {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// rpcResultKey is the context key of the rpcResult of an instrumented RPC
+type rpcResultKey struct{}
+
+// rpcResult is where Interceptor reports the code of an RPC to withInstrumentation
+type rpcResult struct{ code string }
+
+// codeInterceptor records the Connect code of each RPC's error in its rpcResult
+type codeInterceptor struct{}
+
+func (codeInterceptor) WrapUnary(next connect.UnaryFunc) connect.UnaryFunc {
+	return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+		resp, err := next(ctx, req)
+		recordCode(ctx, err)
+		return resp, err
+	}
+}
+
+func (codeInterceptor) WrapStreamingClient(next connect.StreamingClientFunc) connect.StreamingClientFunc {
+	return next
+}
+
+func (codeInterceptor) WrapStreamingHandler(next connect.StreamingHandlerFunc) connect.StreamingHandlerFunc {
+	return func(ctx context.Context, conn connect.StreamingHandlerConn) error {
+		err := next(ctx, conn)
+		recordCode(ctx, err)
+		return err
+	}
+}
+
+// recordCode records the code err is sent with; like Connect, it reports
+// context errors a handler returns as canceled or deadline_exceeded
+func recordCode(ctx context.Context, err error) {
+	result, ok := ctx.Value(rpcResultKey{}).(*rpcResult)
+	if !ok {
+		return
+	}
+	var connectErr *connect.Error
+	switch {
+	case err == nil, errors.As(err, &connectErr):
+		result.code = codeOf(err)
+	case errors.Is(err, context.DeadlineExceeded):
+		result.code = connect.CodeDeadlineExceeded.String()
+	case errors.Is(err, context.Canceled):
+		result.code = connect.CodeCanceled.String()
+	default:
+		result.code = connect.CodeUnknown.String()
+	}
+}
+
+// rpcCode derives the Connect code of a response from the gRPC status, for
+// gRPC and gRPC-Web, or from the HTTP status and error body, for Connect
+func rpcCode(rec *statusRecorder) string {
+	h := rec.Header()
+	status := h.Get(http.TrailerPrefix + "Grpc-Status")
+	if status == "" {
+		status = h.Get("Grpc-Status")
+	}
+	if status != "" {
+		if n, err := strconv.Atoi(status); err == nil && n >= 0 && n < len(codeNames) {
+			return codeNames[n]
+		}
+		return "unknown"
+	}
+	if rec.status == http.StatusOK {
+		return "ok"
+	}
+	var body struct {
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(rec.errorBody, &body) == nil && body.Code != "" {
+		return body.Code
+	}
+	return codeFromHTTPStatus(rec.status)
+}
+
+// codeFromHTTPStatus maps an HTTP status without a Connect error body to a
+// code as the Connect protocol specifies
+func codeFromHTTPStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "internal"
+	case http.StatusUnauthorized:
+		return "unauthenticated"
+	case http.StatusForbidden:
+		return "permission_denied"
+	case http.StatusNotFound:
+		return "unimplemented"
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return "unavailable"
+	}
+	return "unknown"
+}
+
+// rpcError marks a span of a failed RPC
+type rpcError struct{ code string }
+
+func (e *rpcError) Error() string { return "rpc failed: " + e.code }
@@ -0,0 +1,169 @@
+package connectrpc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/yourusername/foundation/connectrpc"
+	"github.com/yourusername/foundation/foundationtest"
+)
+
+const (
+	unaryProcedure  = "/test.v1.TestService/Unary"
+	streamProcedure = "/test.v1.TestService/Stream"
+)
+
+// unary answers with the request's text; "fail" makes it return not_found
+func unary(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+	if req.Msg.Value == "fail" {
+		return nil, connect.NewError(connect.CodeNotFound, errors.New("no such thing"))
+	}
+	return connect.NewResponse(wrapperspb.String(req.Msg.Value)), nil
+}
+
+// stream sends one message, then fails with resource_exhausted if asked to
+func stream(ctx context.Context, req *connect.Request[wrapperspb.StringValue], s *connect.ServerStream[wrapperspb.StringValue]) error {
+	if err := s.Send(wrapperspb.String("first")); err != nil {
+		return err
+	}
+	if req.Msg.Value == "fail" {
+		return connect.NewError(connect.CodeResourceExhausted, errors.New("quota"))
+	}
+	return nil
+}
+
+type codeObserver struct {
+	mu    sync.Mutex
+	codes map[string]string
+}
+
+func (o *codeObserver) ObserveRPC(ctx context.Context, procedure, code string, duration time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.codes[procedure] = code
+}
+
+type instrumentedServer struct {
+	url      string
+	metrics  *foundationtest.Metrics
+	tracer   *foundationtest.Tracer
+	observer *codeObserver
+}
+
+// startInstrumented starts an instrumented server with the test service,
+// installing the server's interceptor if withInterceptor is set
+func startInstrumented(t *testing.T, withInterceptor bool) *instrumentedServer {
+	t.Helper()
+	is := &instrumentedServer{
+		metrics:  foundationtest.NewMetrics(),
+		tracer:   foundationtest.NewTracer(),
+		observer: &codeObserver{codes: make(map[string]string)},
+	}
+	server := connectrpc.NewServer("test", "127.0.0.1:0", foundationtest.NewLogger())
+	server.EnableInstrumentation(is.tracer, is.metrics)
+	server.AddRPCObserver(is.observer)
+
+	var opts []connect.HandlerOption
+	if withInterceptor {
+		opts = append(opts, connect.WithInterceptors(server.Interceptor()))
+	}
+	mux := http.NewServeMux()
+	mux.Handle(unaryProcedure, connect.NewUnaryHandler(unaryProcedure, unary, opts...))
+	mux.Handle(streamProcedure, connect.NewServerStreamHandler(streamProcedure, stream, opts...))
+	server.RegisterHandler("/test.v1.TestService/", mux)
+
+	if err := server.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Stop(context.Background()) })
+	is.url = "http://" + server.Addr()
+	return is
+}
+
+// call makes an RPC and returns the code the client saw
+func (is *instrumentedServer) call(t *testing.T, procedure, msg string, opts ...connect.ClientOption) string {
+	t.Helper()
+	ctx := context.Background()
+	req := connect.NewRequest(wrapperspb.String(msg))
+	var err error
+	if procedure == unaryProcedure {
+		client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](http.DefaultClient, is.url+procedure, opts...)
+		_, err = client.CallUnary(ctx, req)
+	} else {
+		client := connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](http.DefaultClient, is.url+procedure, opts...)
+		var s *connect.ServerStreamForClient[wrapperspb.StringValue]
+		if s, err = client.CallServerStream(ctx, req); err == nil {
+			for s.Receive() {
+			}
+			err = s.Err()
+			s.Close()
+		}
+	}
+	if err == nil {
+		return "ok"
+	}
+	return connect.CodeOf(err).String()
+}
+
+func TestInstrumentationCodes(t *testing.T) {
+	protocols := []struct {
+		name string
+		opts []connect.ClientOption
+	}{
+		{"connect", nil},
+		{"grpc-web", []connect.ClientOption{connect.WithGRPCWeb()}},
+	}
+	calls := []struct {
+		procedure, msg, code string
+	}{
+		{unaryProcedure, "hello", "ok"},
+		{unaryProcedure, "fail", "not_found"},
+		{streamProcedure, "hello", "ok"},
+		// Errors of streams are sent after a 200 OK, at the end of the body
+		{streamProcedure, "fail", "resource_exhausted"},
+	}
+	for _, p := range protocols {
+		for _, c := range calls {
+			t.Run(p.name+c.procedure+"/"+c.msg, func(t *testing.T) {
+				is := startInstrumented(t, true)
+				if got := is.call(t, c.procedure, c.msg, p.opts...); got != c.code {
+					t.Fatalf("client saw %s, want %s", got, c.code)
+				}
+				is.metrics.AssertObserved(t, connectrpc.ServerDurationMetric, "procedure", c.procedure, "code", c.code)
+				span := is.tracer.AssertSpan(t, c.procedure[1:], "rpc.code", c.code)
+				if (span.Err() != nil) != (c.code != "ok") {
+					t.Errorf("span error = %v for code %s", span.Err(), c.code)
+				}
+				is.observer.mu.Lock()
+				defer is.observer.mu.Unlock()
+				if got := is.observer.codes[c.procedure]; got != c.code {
+					t.Errorf("observer saw %s, want %s", got, c.code)
+				}
+			})
+		}
+	}
+}
+
+func TestInstrumentationWithoutInterceptor(t *testing.T) {
+	// Without the interceptor, unary Connect errors are still read from the response
+	is := startInstrumented(t, false)
+	if got := is.call(t, unaryProcedure, "fail"); got != "not_found" {
+		t.Fatalf("client saw %s", got)
+	}
+	is.metrics.AssertObserved(t, connectrpc.ServerDurationMetric, "procedure", unaryProcedure, "code", "not_found")
+
+	// Unknown procedures share one series
+	resp, err := http.Post(is.url+"/test.v1.TestService/Missing", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	is.metrics.AssertObserved(t, connectrpc.ServerDurationMetric, "procedure", "unknown", "code", "unimplemented")
+}
@@ -0,0 +1,31 @@
+package connectrpc
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/yourusername/foundation/logging"
+)
+
+// RequestIDHeader carries the request ID between services
+const RequestIDHeader = "X-Request-Id"
+
+// withRequestID puts the incoming request ID, or a new one, on the request
+// context for context-aware logging and echoes it in the response
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(logging.ContextWithRequestID(r.Context(), id)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
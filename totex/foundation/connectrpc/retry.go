@@ -0,0 +1,163 @@
+package connectrpc
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+const (
+	// ClientRetriesMetric counts retried calls, labelled by procedure and the code that was retried
+	ClientRetriesMetric = "rpc_client_retries_total"
+	// ClientRetryBudgetExhaustedMetric counts retries and hedges skipped because
+	// the retry budget was spent, labelled by procedure
+	ClientRetryBudgetExhaustedMetric = "rpc_client_retry_budget_exhausted_total"
+)
+
+// RetryPolicy configures retries of failed unary calls
+type RetryPolicy struct {
+	MaxAttempts    int            // attempts per call, including the first; 1 disables retries
+	InitialBackoff time.Duration  // longest wait before the first retry, doubling with every retry
+	MaxBackoff     time.Duration  // longest wait between attempts
+	Codes          []connect.Code // codes retried whatever the procedure
+	// IdempotentCodes are also retried for procedures declared idempotent or
+	// free of side effects, where repeating a call that reached the server is safe
+	IdempotentCodes []connect.Code
+}
+
+// DefaultRetryPolicy retries unavailable up to twice, and also
+// deadline_exceeded and aborted for idempotent procedures
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:     3,
+		InitialBackoff:  50 * time.Millisecond,
+		MaxBackoff:      time.Second,
+		Codes:           []connect.Code{connect.CodeUnavailable},
+		IdempotentCodes: []connect.Code{connect.CodeDeadlineExceeded, connect.CodeAborted},
+	}
+}
+
+// retryable reports whether a call to spec that failed with code may be retried
+func (p RetryPolicy) retryable(spec connect.Spec, code connect.Code) bool {
+	if slices.Contains(p.Codes, code) {
+		return true
+	}
+	return spec.IdempotencyLevel != connect.IdempotencyUnknown && slices.Contains(p.IdempotentCodes, code)
+}
+
+// backoff returns how long to wait before retry n, counting from 1, with
+// full jitter so that clients failing together do not retry together
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < n && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if p.MaxBackoff > 0 && d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d <= 0 {
+		return 0
+	}
+	return rand.N(d + 1)
+}
+
+// RetryBudget bounds the retries and hedged requests of a client to a share
+// of its calls, so that a struggling server does not receive a multiple of
+// its normal load. Every call adds ratio to the budget, which also refills by
+// minPerSecond every second so that rarely used clients can retry; each
+// retry takes one from it. Unused budget is kept up to ten seconds' worth of
+// minPerSecond, or 10 retries if that is more.
+type RetryBudget struct {
+	ratio        float64
+	minPerSecond float64
+	max          float64
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+}
+
+// NewRetryBudget creates a full RetryBudget
+func NewRetryBudget(ratio, minPerSecond float64) *RetryBudget {
+	max := max(10*minPerSecond, 10)
+	return &RetryBudget{ratio: ratio, minPerSecond: minPerSecond, max: max, tokens: max, last: time.Now()}
+}
+
+// deposit credits the budget for a call
+func (b *RetryBudget) deposit() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	b.tokens = min(b.tokens+b.ratio, b.max)
+}
+
+// withdraw takes a retry from the budget, reporting whether there was one;
+// a nil budget never runs out
+func (b *RetryBudget) withdraw() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refill()
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (b *RetryBudget) refill() {
+	now := time.Now()
+	b.tokens = min(b.tokens+now.Sub(b.last).Seconds()*b.minPerSecond, b.max)
+	b.last = now
+}
+
+// NewRetryInterceptor returns a client interceptor that retries unary calls
+// failing with a code p allows, waiting with exponential backoff and jitter
+// between attempts, while budget allows; a nil budget does not limit retries.
+// Calls rejected by an open circuit breaker are not retried.
+// The number of retries is set as the rpc.retries tag of the current span.
+func NewRetryInterceptor(p RetryPolicy, budget *RetryBudget, m metrics.Metrics) connect.Interceptor {
+	return connect.UnaryInterceptorFunc(func(next connect.UnaryFunc) connect.UnaryFunc {
+		return func(ctx context.Context, req connect.AnyRequest) (connect.AnyResponse, error) {
+			spec := req.Spec()
+			if p.MaxAttempts <= 1 || !spec.IsClient {
+				return next(ctx, req)
+			}
+			budget.deposit()
+			for attempt := 1; ; attempt++ {
+				resp, err := next(ctx, req)
+				if err == nil || attempt >= p.MaxAttempts || !p.retryable(spec, connect.CodeOf(err)) || errors.Is(err, ErrCircuitOpen) {
+					return resp, err
+				}
+				if !budget.withdraw() {
+					m.Counter(ClientRetryBudgetExhaustedMetric, 1, "procedure", spec.Procedure)
+					return resp, err
+				}
+				timer := time.NewTimer(p.backoff(attempt))
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return resp, err
+				case <-timer.C:
+				}
+				m.CounterContext(ctx, ClientRetriesMetric, 1, "procedure", spec.Procedure, "code", codeOf(err))
+				if span := tracing.SpanFromContext(ctx); span != nil {
+					span.SetTag("rpc.retries", strconv.Itoa(attempt))
+				}
+			}
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package connectrpc
+
+import (
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+)
+
+func TestBackoffBounds(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 10 * time.Millisecond, MaxBackoff: 50 * time.Millisecond}
+	bounds := []time.Duration{10, 20, 40, 50, 50} // milliseconds, for retries 1 to 5
+	for i, bound := range bounds {
+		bound *= time.Millisecond
+		var longest time.Duration
+		for range 200 {
+			d := p.backoff(i + 1)
+			if d < 0 || d > bound {
+				t.Fatalf("backoff(%d) = %v, want at most %v", i+1, d, bound)
+			}
+			longest = max(longest, d)
+		}
+		// Jitter spreads the waits over the whole range
+		if longest < bound/2 {
+			t.Errorf("backoff(%d) never exceeded %v in 200 draws", i+1, longest)
+		}
+	}
+	if d := (RetryPolicy{}).backoff(3); d != 0 {
+		t.Errorf("backoff without an initial backoff = %v", d)
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	p := DefaultRetryPolicy()
+	unknown := connect.Spec{Procedure: "/test.v1.TestService/Create"}
+	idempotent := connect.Spec{Procedure: "/test.v1.TestService/Get", IdempotencyLevel: connect.IdempotencyNoSideEffects}
+	tests := []struct {
+		spec connect.Spec
+		code connect.Code
+		want bool
+	}{
+		{unknown, connect.CodeUnavailable, true},
+		{unknown, connect.CodeDeadlineExceeded, false},
+		{unknown, connect.CodeAborted, false},
+		{idempotent, connect.CodeDeadlineExceeded, true},
+		{idempotent, connect.CodeAborted, true},
+		{idempotent, connect.CodeInvalidArgument, false},
+		{idempotent, connect.CodeInternal, false},
+	}
+	for _, tt := range tests {
+		if got := p.retryable(tt.spec, tt.code); got != tt.want {
+			t.Errorf("retryable(%s, %s) = %v, want %v", tt.spec.Procedure, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestRetryBudget(t *testing.T) {
+	b := NewRetryBudget(0.5, 0)
+	for i := range 10 {
+		if !b.withdraw() {
+			t.Fatalf("budget spent after %d retries, want 10", i)
+		}
+	}
+	if b.withdraw() {
+		t.Fatal("withdrew more than the budget holds")
+	}
+
+	// Every call adds half a retry
+	b.deposit()
+	if b.withdraw() {
+		t.Error("withdrew half a retry")
+	}
+	b.deposit()
+	b.deposit()
+	if !b.withdraw() {
+		t.Error("two calls did not earn a retry")
+	}
+
+	var unlimited *RetryBudget
+	unlimited.deposit()
+	if !unlimited.withdraw() {
+		t.Error("a nil budget ran out")
+	}
+}
+
+func TestRetryBudgetRefills(t *testing.T) {
+	b := NewRetryBudget(0, 1000)
+	for b.withdraw() {
+	}
+	time.Sleep(5 * time.Millisecond)
+	if !b.withdraw() {
+		t.Error("the budget did not refill over time")
+	}
+}
@@ -3,9 +3,14 @@ package connectrpc
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/http"
+	"strings"
 
 	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/redact"
+	"github.com/yourusername/foundation/tracing"
 )
 
 // Server represents a ConnectRPC HTTP server
@@ -14,7 +19,17 @@ type Server struct {
 	logger logging.Logger
 	mux    *http.ServeMux
 	addr   string
+	bound  net.Addr
 	server *http.Server
+	listen func(network, address string) (net.Listener, error)
+
+	accessLog bool
+	redactor  *redact.Redactor
+
+	tracer    tracing.Tracer
+	metrics   metrics.Metrics
+	services  []string // path prefixes of registered service handlers
+	observers []RPCObserver
 }
 
 // NewServer creates a new ConnectRPC server
@@ -24,9 +39,16 @@ func NewServer(name, addr string, logger logging.Logger) *Server {
 		logger: logger,
 		mux:    http.NewServeMux(),
 		addr:   addr,
+		listen: net.Listen,
 	}
 }
 
+// SetListenFunc replaces the function used to open the listening socket,
+// e.g. to reuse a socket inherited from a parent process during an upgrade
+func (s *Server) SetListenFunc(listen func(network, address string) (net.Listener, error)) {
+	s.listen = listen
+}
+
 // RegisterHandler registers a ConnectRPC handler
 func (s *Server) RegisterHandler(path string, handler interface{}) error {
 	h, ok := handler.(http.Handler)
@@ -35,6 +57,9 @@ func (s *Server) RegisterHandler(path string, handler interface{}) error {
 		return fmt.Errorf("handler for %s does not implement http.Handler", path)
 	}
 	s.mux.Handle(path, h)
+	if path != "/" && strings.HasSuffix(path, "/") {
+		s.services = append(s.services, path)
+	}
 	s.logger.Info("Registered handler", "path", path)
 	return nil
 }
@@ -47,12 +72,24 @@ func (s *Server) GetHandler() http.Handler {
 // Start starts the HTTP server
 func (s *Server) Start(ctx context.Context) error {
 	s.logger.Info("Starting ConnectRPC HTTP server", "address", s.addr)
+	ln, err := s.listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", s.addr, err)
+	}
+	s.bound = ln.Addr()
+	var handler http.Handler = s.mux
+	if s.accessLog {
+		handler = s.withAccessLog(handler)
+	}
+	if s.tracer != nil && s.metrics != nil {
+		handler = s.withInstrumentation(handler)
+	}
 	s.server = &http.Server{
 		Addr:    s.addr,
-		Handler: s.mux,
+		Handler: withRequestID(withBaggage(handler)),
 	}
 	go func() {
-		if err := s.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := s.server.Serve(ln); err != nil && err != http.ErrServerClosed {
 			s.logger.Error("HTTP server error", "error", err)
 		}
 	}()
@@ -70,3 +107,12 @@ func (s *Server) Stop(ctx context.Context) error {
 
 // Name returns the server name
 func (s *Server) Name() string { return s.name }
+
+// Addr returns the address the server listens on once started, including the
+// port chosen for an ephemeral ":0" address, or the configured address before
+func (s *Server) Addr() string {
+	if s.bound != nil {
+		return s.bound.String()
+	}
+	return s.addr
+}
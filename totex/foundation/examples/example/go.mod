@@ -2,8 +2,15 @@ module github.com/yourusername/user-service
 
 go 1.24.3
 
+require github.com/yourusername/foundation v0.1.0
+
 require (
-	github.com/yourusername/foundation v0.1.0
+	github.com/bufbuild/connect-go v1.10.0 // indirect
+	github.com/sirupsen/logrus v1.9.3 // indirect
+	go.uber.org/multierr v1.10.0 // indirect
+	go.uber.org/zap v1.27.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	google.golang.org/protobuf v1.36.6 // indirect
 )
 
 replace github.com/yourusername/foundation => ../../
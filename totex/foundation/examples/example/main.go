@@ -55,9 +55,12 @@ func main() {
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
 	logger.Info("Example service started successfully")
-	<-sigChan
-
-	logger.Info("Shutdown signal received, stopping app...")
+	select {
+	case <-sigChan:
+		logger.Info("Shutdown signal received, stopping app...")
+	case <-app.Upgraded():
+		logger.Info("Upgraded process took over, stopping app...")
+	}
 	if err := app.Stop(ctx); err != nil {
 		logger.Error("Error during shutdown", "error", err)
 		os.Exit(1)
@@ -81,8 +84,8 @@ func (h *ExampleHandler) ProcessRequest(ctx context.Context, requestID string, d
 	h.Metrics.Counter("request_processed", 1, "service", "example-service")
 
 	// Log the operation
-	h.Logger.Info("Processing request", "request_id", requestID, "data", data)
+	h.Logger.InfoContext(ctx, "Processing request", "request_id", requestID, "data", data)
 
-	h.Logger.Info("Request processed successfully", "request_id", requestID)
+	h.Logger.InfoContext(ctx, "Request processed successfully", "request_id", requestID)
 	return nil
 }
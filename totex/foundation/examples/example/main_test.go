@@ -0,0 +1,29 @@
+package main
+
+import (
+	"context"
+	"testing"
+
+	foundation "github.com/yourusername/foundation"
+	"github.com/yourusername/foundation/foundationtest"
+)
+
+func TestProcessRequest(t *testing.T) {
+	logger := foundationtest.NewLogger()
+	tracer := foundationtest.NewTracer()
+	metrics := foundationtest.NewMetrics()
+	app := foundation.NewWithConfig("example-service", "test", foundation.AppConfig{},
+		foundation.WithLogger(logger), foundation.WithMetrics(metrics), foundation.WithTracer(tracer))
+	h := &ExampleHandler{Logger: app.Logger(), Tracer: app.Tracer(), Metrics: app.Metrics()}
+
+	if err := h.ProcessRequest(context.Background(), "req-1", "payload"); err != nil {
+		t.Fatalf("ProcessRequest: %v", err)
+	}
+
+	metrics.AssertCounter(t, "request_processed", 1, "service", "example-service")
+	tracer.AssertSpan(t, "process_request")
+	tracer.AssertNoErrors(t)
+	logger.AssertLogged(t, "Processing request", "request_id", "req-1", "data", "payload")
+	logger.AssertLogged(t, "Request processed successfully", "request_id", "req-1")
+	logger.AssertNoErrors(t)
+}
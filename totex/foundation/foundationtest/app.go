@@ -0,0 +1,203 @@
+package foundationtest
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	foundation "github.com/yourusername/foundation"
+)
+
+const (
+	testAppName    = "test-app"
+	testAppVersion = "test"
+	startTimeout   = 10 * time.Second
+	stopTimeout    = 10 * time.Second
+)
+
+// App is a foundation.App booted by StartApp. Its logs, metrics and spans are
+// recorded by Logger, Metrics and Tracer.
+type App struct {
+	*foundation.App
+	Logger  *Logger
+	Metrics *Metrics
+	Tracer  *Tracer
+
+	t          testing.TB
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	expected []expectedLog
+}
+
+type expectedLog struct {
+	msg   string
+	attrs []any
+}
+
+// AppOption configures StartApp
+type AppOption func(*appOptions)
+
+type appOptions struct {
+	leakCheck bool
+	ignore    []string
+}
+
+// WithoutLeakCheck disables the goroutine leak check, e.g. for tests running
+// in parallel with others, whose goroutines cannot be told apart
+func WithoutLeakCheck() AppOption {
+	return func(o *appOptions) {
+		o.leakCheck = false
+	}
+}
+
+// IgnoreGoroutines excludes goroutines whose stack contains any of funcs,
+// e.g. "github.com/org/pkg.(*Pool).run", from the leak check
+func IgnoreGoroutines(funcs ...string) AppOption {
+	return func(o *appOptions) {
+		o.ignore = append(o.ignore, funcs...)
+	}
+}
+
+// StartApp creates an App from cfg with recording test doubles, calls
+// register to add handlers, components and workers, and starts it. Every
+// server listens on an ephemeral port on 127.0.0.1 and upgrades are
+// disabled; a file leader backend without a directory uses a temporary one.
+// If cfg has no servers, a ConnectRPC server is added.
+//
+// The app is stopped through t.Cleanup, which then fails the test if an
+// error was logged that was not announced with ExpectErrorLog, or if
+// goroutines started during the test are still running.
+func StartApp(t testing.TB, cfg foundation.AppConfig, register func(*foundation.App), opts ...AppOption) *App {
+	t.Helper()
+	o := appOptions{leakCheck: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	var before map[uint64]string
+	if o.leakCheck {
+		before = goroutines()
+	}
+
+	cfg = testConfig(t, cfg)
+	a := &App{
+		Logger:     NewLogger(),
+		Metrics:    NewMetrics(),
+		Tracer:     NewTracer(),
+		t:          t,
+		httpClient: &http.Client{Transport: &http.Transport{}},
+	}
+	a.App = foundation.NewWithConfig(testAppName, testAppVersion, cfg,
+		foundation.WithLogger(a.Logger),
+		foundation.WithMetrics(a.Metrics),
+		foundation.WithTracer(a.Tracer),
+	)
+	t.Cleanup(func() {
+		a.stop()
+		if o.leakCheck {
+			checkLeaks(t, before, o.ignore)
+		}
+	})
+
+	if register != nil {
+		register(a.App)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), startTimeout)
+	defer cancel()
+	if err := a.Start(ctx); err != nil {
+		t.Fatalf("foundationtest: start app: %v\n%s", err, a.Logger.dump())
+	}
+	return a
+}
+
+// testConfig points every server at an ephemeral port and disables what a
+// test process must not do
+func testConfig(t testing.TB, cfg foundation.AppConfig) foundation.AppConfig {
+	servers := append([]foundation.ServerConfig(nil), cfg.Servers...)
+	if len(servers) == 0 {
+		servers = append(servers, foundation.ServerConfig{Type: "connectrpc", Name: "test-server"})
+	}
+	for i := range servers {
+		servers[i].Addr = "127.0.0.1:0"
+	}
+	cfg.Servers = servers
+	cfg.Upgrade.Enabled = false
+	if cfg.Leader.Backend == "file" && cfg.Leader.Dir == "" {
+		cfg.Leader.Dir = t.TempDir()
+	}
+	return cfg
+}
+
+// URL returns the base URL of the app's ConnectRPC server
+func (a *App) URL() string {
+	server := a.ConnectRPC()
+	if server == nil {
+		a.t.Fatalf("foundationtest: app has no ConnectRPC server")
+	}
+	return "http://" + server.Addr()
+}
+
+// HTTPClient returns the client used by Client; its idle connections are
+// closed when the app is stopped
+func (a *App) HTTPClient() *http.Client {
+	return a.httpClient
+}
+
+// ExpectErrorLog allows error-level lines with message msg and the given
+// attribute key/value pairs, which otherwise fail the test at cleanup
+func (a *App) ExpectErrorLog(msg string, attrs ...any) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.expected = append(a.expected, expectedLog{msg, attrs})
+}
+
+// Client creates a typed ConnectRPC client for the app's server from a
+// generated constructor, e.g.
+//
+//	client := foundationtest.Client(app, userv1connect.NewUserServiceClient)
+func Client[T, H, O any](a *App, newClient func(H, string, ...O) T, opts ...O) T {
+	a.t.Helper()
+	httpClient, ok := any(a.httpClient).(H)
+	if !ok {
+		a.t.Fatalf("foundationtest: %T does not accept an *http.Client", newClient)
+	}
+	return newClient(httpClient, a.URL(), opts...)
+}
+
+// stop stops the app if it is running and fails the test on unexpected error logs
+func (a *App) stop() {
+	a.t.Helper()
+	a.httpClient.CloseIdleConnections()
+	if a.State() == foundation.StateRunning {
+		ctx, cancel := context.WithTimeout(context.Background(), stopTimeout)
+		defer cancel()
+		if err := a.Stop(ctx); err != nil {
+			a.t.Errorf("foundationtest: stop app: %v", err)
+		}
+	}
+
+	a.mu.Lock()
+	expected := a.expected
+	a.mu.Unlock()
+	for _, e := range a.Logger.AtLevel(slog.LevelError) {
+		if !isExpected(e, expected) {
+			a.t.Errorf("unexpected error log: %s", e)
+		}
+	}
+	if a.t.Failed() {
+		a.t.Logf("app logs:\n%s", a.Logger.dump())
+	}
+}
+
+func isExpected(e LogEntry, expected []expectedLog) bool {
+	for _, x := range expected {
+		if matchEntry(e, x.msg, x.attrs) {
+			return true
+		}
+	}
+	return false
+}
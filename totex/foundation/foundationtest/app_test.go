@@ -0,0 +1,163 @@
+package foundationtest
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	connect "github.com/bufbuild/connect-go"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	foundation "github.com/yourusername/foundation"
+	"github.com/yourusername/foundation/connectrpc"
+)
+
+const echoProcedure = "/test.v1.TestService/Echo"
+
+// newEchoClient has the shape of a generated client constructor
+func newEchoClient(httpClient connect.HTTPClient, baseURL string, opts ...connect.ClientOption) *connect.Client[wrapperspb.StringValue, wrapperspb.StringValue] {
+	return connect.NewClient[wrapperspb.StringValue, wrapperspb.StringValue](httpClient, baseURL+echoProcedure, opts...)
+}
+
+// registerEcho serves echoProcedure, which logs an error for the message "fail"
+func registerEcho(app *foundation.App) {
+	mux := http.NewServeMux()
+	mux.Handle(echoProcedure, connect.NewUnaryHandler(echoProcedure,
+		func(ctx context.Context, req *connect.Request[wrapperspb.StringValue]) (*connect.Response[wrapperspb.StringValue], error) {
+			if req.Msg.Value == "fail" {
+				app.Logger().Error("Echo failed", "reason", "asked to")
+			}
+			return connect.NewResponse(req.Msg), nil
+		}))
+	app.ConnectRPC().RegisterHandler("/test.v1.TestService/", mux)
+}
+
+// appTB is a fakeTB that runs its cleanups on demand, so the failures
+// StartApp reports when the test ends can be checked
+type appTB struct {
+	fakeTB
+	t        *testing.T
+	cleanups []func()
+}
+
+func (tb *appTB) Cleanup(f func())    { tb.cleanups = append(tb.cleanups, f) }
+func (tb *appTB) Failed() bool        { return len(tb.errors) > 0 }
+func (tb *appTB) Logf(string, ...any) {}
+func (tb *appTB) TempDir() string     { return tb.t.TempDir() }
+
+func (tb *appTB) Fatalf(format string, args ...any) {
+	tb.t.Fatalf(format, args...)
+}
+
+// finish runs the cleanups as the end of a test would
+func (tb *appTB) finish() {
+	for i := len(tb.cleanups) - 1; i >= 0; i-- {
+		tb.cleanups[i]()
+	}
+}
+
+func TestStartApp(t *testing.T) {
+	app := StartApp(t, foundation.AppConfig{}, registerEcho)
+	if app.State() != foundation.StateRunning {
+		t.Fatalf("state = %s", app.State())
+	}
+
+	client := Client(app, newEchoClient)
+	resp, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("hello")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Msg.Value != "hello" {
+		t.Errorf("echoed %q", resp.Msg.Value)
+	}
+	app.Metrics.AssertObserved(t, connectrpc.ServerDurationMetric, "procedure", echoProcedure, "code", "ok")
+	if !strings.HasPrefix(app.URL(), "http://127.0.0.1:") {
+		t.Errorf("URL = %s, want an ephemeral port on 127.0.0.1", app.URL())
+	}
+}
+
+func TestStartAppStopsApp(t *testing.T) {
+	tb := &appTB{t: t}
+	app := StartApp(tb, foundation.AppConfig{}, registerEcho)
+	tb.finish()
+	if app.State() != foundation.StateStopped {
+		t.Errorf("state after cleanup = %s", app.State())
+	}
+	if len(tb.errors) > 0 {
+		t.Errorf("a clean test failed: %q", tb.errors)
+	}
+}
+
+func TestStartAppReportsErrorLogs(t *testing.T) {
+	call := func(app *App) {
+		client := Client(app, newEchoClient)
+		if _, err := client.CallUnary(context.Background(), connect.NewRequest(wrapperspb.String("fail"))); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tb := &appTB{t: t}
+	call(StartApp(tb, foundation.AppConfig{}, registerEcho))
+	tb.finish()
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], `unexpected error log: ERROR "Echo failed"`) {
+		t.Errorf("errors = %q, want the unexpected error log", tb.errors)
+	}
+
+	// An announced error is allowed, as long as its attributes match
+	for _, tt := range []struct {
+		attrs []any
+		fails bool
+	}{
+		{nil, false},
+		{[]any{"reason", "asked to"}, false},
+		{[]any{"reason", "other"}, true},
+	} {
+		tb := &appTB{t: t}
+		app := StartApp(tb, foundation.AppConfig{}, registerEcho)
+		app.ExpectErrorLog("Echo failed", tt.attrs...)
+		call(app)
+		tb.finish()
+		if failed := len(tb.errors) > 0; failed != tt.fails {
+			t.Errorf("ExpectErrorLog(%v): failed = %v, want %v; errors: %q", tt.attrs, failed, tt.fails, tb.errors)
+		}
+	}
+}
+
+func TestStartAppReportsLeaks(t *testing.T) {
+	defer func(timeout time.Duration) { leakTimeout = timeout }(leakTimeout)
+	leakTimeout = 50 * time.Millisecond
+
+	leak := func(stop chan struct{}) func(*foundation.App) {
+		return func(*foundation.App) {
+			go leakyLoop(stop)
+		}
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	tb := &appTB{t: t}
+	StartApp(tb, foundation.AppConfig{}, leak(stop))
+	tb.finish()
+	if len(tb.errors) != 1 || !strings.Contains(tb.errors[0], "1 leaked goroutines") || !strings.Contains(tb.errors[0], "leakyLoop") {
+		t.Errorf("errors = %q, want the leaked goroutine", tb.errors)
+	}
+
+	// Ignored or disabled, the same leak passes
+	for _, opt := range []AppOption{IgnoreGoroutines("foundationtest.leakyLoop"), WithoutLeakCheck()} {
+		stop := make(chan struct{})
+		tb := &appTB{t: t}
+		StartApp(tb, foundation.AppConfig{}, leak(stop), opt)
+		tb.finish()
+		close(stop)
+		if len(tb.errors) > 0 {
+			t.Errorf("errors = %q", tb.errors)
+		}
+	}
+}
+
+// leakyLoop runs until stop is closed
+func leakyLoop(stop chan struct{}) {
+	<-stop
+}
@@ -0,0 +1,81 @@
+package foundationtest
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// leakTimeout is how long checkLeaks waits for goroutines to exit
+var leakTimeout = 5 * time.Second
+
+// ignoredGoroutines are started once per process by the standard library and never exit
+var ignoredGoroutines = []string{
+	"os/signal.signal_recv",
+	"os/signal.loop",
+}
+
+// goroutines returns the stacks of all goroutines keyed by goroutine ID
+func goroutines() map[uint64]string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[uint64]string)
+	for _, stack := range strings.Split(string(buf), "\n\n") {
+		var id uint64
+		if _, err := fmt.Sscanf(stack, "goroutine %d ", &id); err == nil {
+			stacks[id] = stack
+		}
+	}
+	return stacks
+}
+
+// leaked returns the stacks of goroutines not in before, other than ignored ones
+func leaked(before map[uint64]string, ignore []string) []string {
+	var stacks []string
+	for id, stack := range goroutines() {
+		if _, ok := before[id]; ok || ignored(stack, ignore) {
+			continue
+		}
+		stacks = append(stacks, stack)
+	}
+	sort.Strings(stacks)
+	return stacks
+}
+
+func ignored(stack string, ignore []string) bool {
+	for _, fn := range append(ignoredGoroutines, ignore...) {
+		if strings.Contains(stack, fn) {
+			return true
+		}
+	}
+	return false
+}
+
+// checkLeaks fails the test if goroutines started since before are still
+// running after leakTimeout
+func checkLeaks(t testing.TB, before map[uint64]string, ignore []string) {
+	t.Helper()
+	deadline := time.Now().Add(leakTimeout)
+	for {
+		stacks := leaked(before, ignore)
+		if len(stacks) == 0 {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Errorf("foundationtest: %d leaked goroutines:\n\n%s", len(stacks), strings.Join(stacks, "\n\n"))
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
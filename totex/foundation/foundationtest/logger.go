@@ -0,0 +1,220 @@
+package foundationtest
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/logging"
+)
+
+// LogEntry is a line recorded by Logger. Attrs holds every attribute,
+// including those added through With and the context's trace and request
+// IDs; attributes in groups are keyed by their dotted path.
+type LogEntry struct {
+	Time    time.Time
+	Level   slog.Level
+	Message string
+	Attrs   map[string]any
+}
+
+// Attr returns the attribute under key
+func (e LogEntry) Attr(key string) (any, bool) {
+	v, ok := e.Attrs[key]
+	return v, ok
+}
+
+// String renders the entry like a text log line
+func (e LogEntry) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %q", e.Level, e.Message)
+	for _, key := range sortedKeys(e.Attrs) {
+		fmt.Fprintf(&b, " %s=%v", key, e.Attrs[key])
+	}
+	return b.String()
+}
+
+// Logger is a logging.Logger that records every line, at every level, for
+// later inspection. Child loggers from With and Named record into the same
+// Logger. Sensitive values are redacted as with the real backends.
+type Logger struct {
+	logging.Logger
+	rec *logRecorder
+}
+
+// NewLogger creates a recording Logger
+func NewLogger(opts ...logging.Option) *Logger {
+	rec := &logRecorder{}
+	return &Logger{
+		Logger: logging.NewSlogLoggerWithHandler("test-logger", "debug", recordingHandler{rec: rec}, opts...),
+		rec:    rec,
+	}
+}
+
+// Levels returns the level registry of the recording logger
+func (l *Logger) Levels() *logging.Levels {
+	return l.Logger.(logging.LevelController).Levels()
+}
+
+// Entries returns the recorded lines in order
+func (l *Logger) Entries() []LogEntry {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	return append([]LogEntry(nil), l.rec.entries...)
+}
+
+// Reset discards the recorded lines
+func (l *Logger) Reset() {
+	l.rec.mu.Lock()
+	defer l.rec.mu.Unlock()
+	l.rec.entries = nil
+}
+
+// Find returns the first line with message msg and the given attribute
+// key/value pairs; an empty msg matches any message
+func (l *Logger) Find(msg string, attrs ...any) (LogEntry, bool) {
+	for _, e := range l.Entries() {
+		if matchEntry(e, msg, attrs) {
+			return e, true
+		}
+	}
+	return LogEntry{}, false
+}
+
+// FindAll returns every line with message msg and the given attribute key/value pairs
+func (l *Logger) FindAll(msg string, attrs ...any) []LogEntry {
+	var found []LogEntry
+	for _, e := range l.Entries() {
+		if matchEntry(e, msg, attrs) {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AtLevel returns the lines at level or above
+func (l *Logger) AtLevel(level slog.Level) []LogEntry {
+	var found []LogEntry
+	for _, e := range l.Entries() {
+		if e.Level >= level {
+			found = append(found, e)
+		}
+	}
+	return found
+}
+
+// AssertLogged fails the test unless a line with message msg and the given
+// attribute key/value pairs was logged, and returns the first such line
+func (l *Logger) AssertLogged(t testing.TB, msg string, attrs ...any) LogEntry {
+	t.Helper()
+	e, ok := l.Find(msg, attrs...)
+	if !ok {
+		t.Errorf("no log line %q with %s; logged:\n%s", msg, formatPairs(attrs), l.dump())
+	}
+	return e
+}
+
+// AssertNotLogged fails the test if a line with message msg and the given
+// attribute key/value pairs was logged
+func (l *Logger) AssertNotLogged(t testing.TB, msg string, attrs ...any) {
+	t.Helper()
+	if e, ok := l.Find(msg, attrs...); ok {
+		t.Errorf("unexpected log line: %s", e)
+	}
+}
+
+// AssertNoErrors fails the test if any line was logged at error level
+func (l *Logger) AssertNoErrors(t testing.TB) {
+	t.Helper()
+	for _, e := range l.AtLevel(slog.LevelError) {
+		t.Errorf("unexpected error log: %s", e)
+	}
+}
+
+func (l *Logger) dump() string {
+	var b strings.Builder
+	for _, e := range l.Entries() {
+		b.WriteString("\t")
+		b.WriteString(e.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func matchEntry(e LogEntry, msg string, attrs []any) bool {
+	if msg != "" && e.Message != msg {
+		return false
+	}
+	return matchPairs(e.Attrs, attrs)
+}
+
+type logRecorder struct {
+	mu      sync.Mutex
+	entries []LogEntry
+}
+
+// recordingHandler is the slog.Handler behind Logger
+type recordingHandler struct {
+	rec    *logRecorder
+	attrs  []slog.Attr
+	groups []string
+}
+
+func (h recordingHandler) Enabled(context.Context, slog.Level) bool { return true }
+
+func (h recordingHandler) Handle(_ context.Context, r slog.Record) error {
+	e := LogEntry{Time: r.Time, Level: r.Level, Message: r.Message, Attrs: make(map[string]any)}
+	for _, a := range h.attrs {
+		addAttr(e.Attrs, "", a)
+	}
+	prefix := strings.Join(h.groups, ".")
+	if prefix != "" {
+		prefix += "."
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		addAttr(e.Attrs, prefix, a)
+		return true
+	})
+
+	h.rec.mu.Lock()
+	defer h.rec.mu.Unlock()
+	h.rec.entries = append(h.rec.entries, e)
+	return nil
+}
+
+func (h recordingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	prefix := strings.Join(h.groups, ".")
+	if prefix != "" {
+		prefix += "."
+	}
+	next := h
+	next.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		next.attrs = append(next.attrs, slog.Attr{Key: prefix + a.Key, Value: a.Value})
+	}
+	return next
+}
+
+func (h recordingHandler) WithGroup(name string) slog.Handler {
+	next := h
+	next.groups = append(append([]string(nil), h.groups...), name)
+	return next
+}
+
+func addAttr(attrs map[string]any, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			addAttr(attrs, prefix, ga)
+		}
+		return
+	}
+	attrs[prefix+a.Key] = v.Any()
+}
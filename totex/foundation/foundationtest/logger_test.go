@@ -0,0 +1,84 @@
+package foundationtest
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+)
+
+func TestLoggerRecords(t *testing.T) {
+	logger := NewLogger()
+	logger.Debug("Starting", "attempt", 1)
+	child := logger.With("user_id", "u1")
+	child.Info("Created user", "source", "signup")
+	logger.Named("repo").Warn("Slow query", "table", "users")
+	logger.Error("Failed", "error", "boom")
+
+	entries := logger.Entries()
+	if len(entries) != 4 {
+		t.Fatalf("recorded %d lines, want 4:\n%s", len(entries), logger.dump())
+	}
+	if entries[0].Level != slog.LevelDebug {
+		t.Errorf("first line at %v, want debug", entries[0].Level)
+	}
+
+	e, ok := logger.Find("Created user", "user_id", "u1", "source", "signup")
+	if !ok {
+		t.Fatalf("Find missed a line with attributes from With:\n%s", logger.dump())
+	}
+	if v, _ := e.Attr("user_id"); v != "u1" {
+		t.Errorf("Attr(user_id) = %v", v)
+	}
+	if _, ok := logger.Find("Created user", "user_id", "u2"); ok {
+		t.Error("Find matched a different attribute value")
+	}
+	if _, ok := logger.Find("", "table", "users"); !ok {
+		t.Error("Find with an empty message matched nothing")
+	}
+	if got := len(logger.FindAll("", "attempt", 1)); got != 1 {
+		t.Errorf("FindAll = %d lines, want 1", got)
+	}
+	if got := len(logger.AtLevel(slog.LevelWarn)); got != 2 {
+		t.Errorf("AtLevel(warn) = %d lines, want 2", got)
+	}
+
+	logger.Reset()
+	if got := len(logger.Entries()); got != 0 {
+		t.Errorf("Reset left %d lines", got)
+	}
+}
+
+func TestLoggerGroups(t *testing.T) {
+	logger := NewLogger()
+	logger.Info("Request", slog.Group("http", "method", "GET", "status", 200))
+
+	logger.AssertLogged(t, "Request", "http.method", "GET", "http.status", 200)
+}
+
+func TestLoggerContext(t *testing.T) {
+	logger := NewLogger()
+	tracer := NewTracer()
+	span := tracer.StartSpan("op").(*Span)
+
+	logger.InfoContext(span.Context(), "In span")
+	logger.InfoContext(context.Background(), "Outside span")
+
+	logger.AssertLogged(t, "In span", "trace_id", span.SpanContext.TraceID)
+	if _, ok := logger.Find("Outside span", "trace_id", span.SpanContext.TraceID); ok {
+		t.Error("line logged without a span carries its trace ID")
+	}
+}
+
+func TestLoggerAsserts(t *testing.T) {
+	logger := NewLogger()
+	logger.Info("Created user", "user_id", "u1")
+
+	assertFails(t, false, func(tb testing.TB) { logger.AssertLogged(tb, "Created user", "user_id", "u1") })
+	assertFails(t, true, func(tb testing.TB) { logger.AssertLogged(tb, "Created user", "user_id", "u2") })
+	assertFails(t, true, func(tb testing.TB) { logger.AssertNotLogged(tb, "Created user") })
+	assertFails(t, false, func(tb testing.TB) { logger.AssertNotLogged(tb, "Deleted user") })
+	assertFails(t, false, logger.AssertNoErrors)
+
+	logger.Error("Failed")
+	assertFails(t, true, logger.AssertNoErrors)
+}
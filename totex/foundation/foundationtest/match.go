@@ -0,0 +1,49 @@
+package foundationtest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// matchPairs reports whether values holds every key/value pair in pairs.
+// Values are compared by their printed form, so 1 matches an int64(1).
+func matchPairs[V any](values map[string]V, pairs []any) bool {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		key := fmt.Sprint(pairs[i])
+		v, ok := values[key]
+		if !ok || fmt.Sprint(v) != fmt.Sprint(pairs[i+1]) {
+			return false
+		}
+	}
+	return true
+}
+
+// stringPairs converts label key/value pairs to a map
+func stringPairs(pairs []string) map[string]string {
+	m := make(map[string]string, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		m[pairs[i]] = pairs[i+1]
+	}
+	return m
+}
+
+func formatPairs[P any](pairs []P) string {
+	if len(pairs) == 0 {
+		return "no attributes"
+	}
+	parts := make([]string, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		parts = append(parts, fmt.Sprintf("%v=%v", pairs[i], pairs[i+1]))
+	}
+	return strings.Join(parts, " ")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
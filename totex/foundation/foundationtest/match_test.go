@@ -0,0 +1,74 @@
+package foundationtest
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+)
+
+// fakeTB records the failures reported by the Assert helpers
+type fakeTB struct {
+	testing.TB
+	errors []string
+}
+
+func (f *fakeTB) Helper() {}
+
+func (f *fakeTB) Errorf(format string, args ...any) {
+	f.errors = append(f.errors, fmt.Sprintf(format, args...))
+}
+
+// assertFails runs assert against a fakeTB and checks whether it failed
+func assertFails(t *testing.T, want bool, assert func(tb testing.TB)) {
+	t.Helper()
+	tb := &fakeTB{}
+	assert(tb)
+	if failed := len(tb.errors) > 0; failed != want {
+		t.Errorf("failed = %v, want %v; errors: %q", failed, want, tb.errors)
+	}
+}
+
+func TestMatchPairs(t *testing.T) {
+	values := map[string]any{"user_id": int64(42), "name": "ada", "ok": true}
+	tests := []struct {
+		pairs []any
+		want  bool
+	}{
+		{nil, true},
+		{[]any{"name", "ada"}, true},
+		{[]any{"user_id", 42}, true}, // compared by printed form
+		{[]any{"user_id", "42", "ok", true}, true},
+		{[]any{"name", "bob"}, false},
+		{[]any{"missing", ""}, false},
+		{[]any{"name", "ada", "ok", false}, false},
+		{[]any{"name"}, true}, // a trailing key without value is ignored
+	}
+	for _, tt := range tests {
+		if got := matchPairs(values, tt.pairs); got != tt.want {
+			t.Errorf("matchPairs(%v) = %v, want %v", tt.pairs, got, tt.want)
+		}
+	}
+}
+
+func TestStringPairs(t *testing.T) {
+	got := stringPairs([]string{"a", "1", "b", "2", "dangling"})
+	if len(got) != 2 || got["a"] != "1" || got["b"] != "2" {
+		t.Errorf("stringPairs = %v", got)
+	}
+}
+
+func TestFormatPairs(t *testing.T) {
+	if got := formatPairs[any](nil); got != "no attributes" {
+		t.Errorf("formatPairs(nil) = %q", got)
+	}
+	if got := formatPairs([]string{"a", "1", "b", "2"}); got != "a=1 b=2" {
+		t.Errorf("formatPairs = %q", got)
+	}
+}
+
+func TestSortedKeys(t *testing.T) {
+	got := sortedKeys(map[string]int{"b": 1, "c": 2, "a": 3})
+	if !slices.Equal(got, []string{"a", "b", "c"}) {
+		t.Errorf("sortedKeys = %v", got)
+	}
+}
@@ -0,0 +1,211 @@
+package foundationtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+// MetricKind is the instrument type of a recorded sample
+type MetricKind string
+
+const (
+	KindCounter   MetricKind = "counter"
+	KindGauge     MetricKind = "gauge"
+	KindHistogram MetricKind = "histogram"
+	KindSummary   MetricKind = "summary"
+)
+
+// Sample is one call recorded by Metrics
+type Sample struct {
+	Kind   MetricKind
+	Name   string
+	Value  float64
+	Labels map[string]string
+	// TraceID is the trace of the span in the context of a CounterContext or
+	// HistogramContext call, if it was sampled
+	TraceID string
+}
+
+// String renders the sample as name{labels} value
+func (s Sample) String() string {
+	labels := make([]string, 0, len(s.Labels))
+	for _, k := range sortedKeys(s.Labels) {
+		labels = append(labels, fmt.Sprintf("%s=%q", k, s.Labels[k]))
+	}
+	return fmt.Sprintf("%s %s{%s} %v", s.Kind, s.Name, strings.Join(labels, ","), s.Value)
+}
+
+// Metrics is a metrics.Metrics that records every call for later inspection.
+// Label filters in queries match samples carrying at least the given labels.
+type Metrics struct {
+	mu      sync.Mutex
+	samples []Sample
+}
+
+// NewMetrics creates a recording Metrics
+func NewMetrics() *Metrics {
+	return &Metrics{}
+}
+
+func (m *Metrics) Counter(name string, value float64, labels ...string) {
+	m.record(nil, KindCounter, name, value, labels)
+}
+
+func (m *Metrics) Gauge(name string, value float64, labels ...string) {
+	m.record(nil, KindGauge, name, value, labels)
+}
+
+func (m *Metrics) Histogram(name string, value float64, labels ...string) {
+	m.record(nil, KindHistogram, name, value, labels)
+}
+
+func (m *Metrics) Summary(name string, value float64, labels ...string) {
+	m.record(nil, KindSummary, name, value, labels)
+}
+
+func (m *Metrics) CounterContext(ctx context.Context, name string, value float64, labels ...string) {
+	m.record(ctx, KindCounter, name, value, labels)
+}
+
+func (m *Metrics) HistogramContext(ctx context.Context, name string, value float64, labels ...string) {
+	m.record(ctx, KindHistogram, name, value, labels)
+}
+
+func (m *Metrics) Name() string { return "test-metrics" }
+
+func (m *Metrics) record(ctx context.Context, kind MetricKind, name string, value float64, labels []string) {
+	sample := Sample{Kind: kind, Name: name, Value: value, Labels: stringPairs(labels)}
+	if ctx != nil {
+		if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() && sc.Sampled {
+			sample.TraceID = sc.TraceID
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = append(m.samples, sample)
+}
+
+// Samples returns the recorded calls in order
+func (m *Metrics) Samples() []Sample {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Sample(nil), m.samples...)
+}
+
+// Reset discards the recorded calls
+func (m *Metrics) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.samples = nil
+}
+
+// Find returns the samples of kind and name carrying the given label pairs
+func (m *Metrics) Find(kind MetricKind, name string, labels ...string) []Sample {
+	pairs := toAny(labels)
+	var found []Sample
+	for _, s := range m.Samples() {
+		if s.Kind == kind && s.Name == name && matchPairs(s.Labels, pairs) {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+// CounterValue returns the sum of the counter's increments carrying the given label pairs
+func (m *Metrics) CounterValue(name string, labels ...string) float64 {
+	var total float64
+	for _, s := range m.Find(KindCounter, name, labels...) {
+		total += s.Value
+	}
+	return total
+}
+
+// GaugeValue returns the last value set on the gauge with the given label
+// pairs, and whether it was set at all
+func (m *Metrics) GaugeValue(name string, labels ...string) (float64, bool) {
+	found := m.Find(KindGauge, name, labels...)
+	if len(found) == 0 {
+		return 0, false
+	}
+	return found[len(found)-1].Value, true
+}
+
+// Observations returns the values observed by the histogram or summary with the given label pairs
+func (m *Metrics) Observations(name string, labels ...string) []float64 {
+	var values []float64
+	for _, s := range m.Samples() {
+		if s.Name != name || (s.Kind != KindHistogram && s.Kind != KindSummary) {
+			continue
+		}
+		if matchPairs(s.Labels, toAny(labels)) {
+			values = append(values, s.Value)
+		}
+	}
+	return values
+}
+
+// AssertCounter fails the test unless the counter with the given label pairs adds up to want
+func (m *Metrics) AssertCounter(t testing.TB, name string, want float64, labels ...string) {
+	t.Helper()
+	if got := m.CounterValue(name, labels...); got != want {
+		t.Errorf("counter %s with %s = %v, want %v; recorded:\n%s", name, formatPairs(labels), got, want, m.dump(name))
+	}
+}
+
+// AssertGauge fails the test unless the gauge with the given label pairs was last set to want
+func (m *Metrics) AssertGauge(t testing.TB, name string, want float64, labels ...string) {
+	t.Helper()
+	got, ok := m.GaugeValue(name, labels...)
+	if !ok {
+		t.Errorf("gauge %s with %s was never set; recorded:\n%s", name, formatPairs(labels), m.dump(name))
+		return
+	}
+	if got != want {
+		t.Errorf("gauge %s with %s = %v, want %v", name, formatPairs(labels), got, want)
+	}
+}
+
+// AssertObserved fails the test unless the histogram or summary with the
+// given label pairs observed at least one value, and returns the values
+func (m *Metrics) AssertObserved(t testing.TB, name string, labels ...string) []float64 {
+	t.Helper()
+	values := m.Observations(name, labels...)
+	if len(values) == 0 {
+		t.Errorf("no observations for %s with %s; recorded:\n%s", name, formatPairs(labels), m.dump(name))
+	}
+	return values
+}
+
+// dump lists the samples named name, or all samples if there are none
+func (m *Metrics) dump(name string) string {
+	samples := m.Samples()
+	var named []Sample
+	for _, s := range samples {
+		if s.Name == name {
+			named = append(named, s)
+		}
+	}
+	if len(named) > 0 {
+		samples = named
+	}
+	var b strings.Builder
+	for _, s := range samples {
+		b.WriteString("\t")
+		b.WriteString(s.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func toAny(labels []string) []any {
+	pairs := make([]any, len(labels))
+	for i, l := range labels {
+		pairs[i] = l
+	}
+	return pairs
+}
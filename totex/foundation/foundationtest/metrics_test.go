@@ -0,0 +1,71 @@
+package foundationtest
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestMetricsQueries(t *testing.T) {
+	m := NewMetrics()
+	m.Counter("requests_total", 1, "method", "GET", "code", "ok")
+	m.Counter("requests_total", 2, "method", "GET", "code", "error")
+	m.CounterContext(context.Background(), "requests_total", 4, "method", "POST", "code", "ok")
+	m.Gauge("queue_depth", 3)
+	m.Gauge("queue_depth", 5)
+	m.Histogram("duration_seconds", 0.1, "method", "GET")
+	m.HistogramContext(context.Background(), "duration_seconds", 0.2, "method", "POST")
+	m.Summary("duration_seconds", 0.3, "method", "GET")
+
+	// Label filters match samples carrying at least the given labels
+	if got := m.CounterValue("requests_total"); got != 7 {
+		t.Errorf("CounterValue() = %v, want 7", got)
+	}
+	if got := m.CounterValue("requests_total", "method", "GET"); got != 3 {
+		t.Errorf("CounterValue(GET) = %v, want 3", got)
+	}
+	if got := m.CounterValue("requests_total", "method", "GET", "code", "ok"); got != 1 {
+		t.Errorf("CounterValue(GET, ok) = %v, want 1", got)
+	}
+	if got := m.CounterValue("requests_total", "method", "PUT"); got != 0 {
+		t.Errorf("CounterValue(PUT) = %v, want 0", got)
+	}
+
+	if v, ok := m.GaugeValue("queue_depth"); !ok || v != 5 {
+		t.Errorf("GaugeValue = %v, %v; want the last value 5", v, ok)
+	}
+	if _, ok := m.GaugeValue("missing"); ok {
+		t.Error("GaugeValue reported an unset gauge as set")
+	}
+
+	if got := m.Observations("duration_seconds", "method", "GET"); !slices.Equal(got, []float64{0.1, 0.3}) {
+		t.Errorf("Observations(GET) = %v, want histogram and summary values", got)
+	}
+	if got := len(m.Find(KindHistogram, "duration_seconds")); got != 2 {
+		t.Errorf("Find(histogram) = %d samples, want 2", got)
+	}
+
+	if got := m.Samples()[0].String(); got != `counter requests_total{code="ok",method="GET"} 1` {
+		t.Errorf("String = %s", got)
+	}
+
+	m.Reset()
+	if got := len(m.Samples()); got != 0 {
+		t.Errorf("Reset left %d samples", got)
+	}
+}
+
+func TestMetricsAsserts(t *testing.T) {
+	m := NewMetrics()
+	m.Counter("requests_total", 2, "method", "GET")
+	m.Gauge("queue_depth", 5)
+	m.Histogram("duration_seconds", 0.1)
+
+	assertFails(t, false, func(tb testing.TB) { m.AssertCounter(tb, "requests_total", 2, "method", "GET") })
+	assertFails(t, true, func(tb testing.TB) { m.AssertCounter(tb, "requests_total", 1) })
+	assertFails(t, false, func(tb testing.TB) { m.AssertGauge(tb, "queue_depth", 5) })
+	assertFails(t, true, func(tb testing.TB) { m.AssertGauge(tb, "queue_depth", 4) })
+	assertFails(t, true, func(tb testing.TB) { m.AssertGauge(tb, "missing", 0) })
+	assertFails(t, false, func(tb testing.TB) { m.AssertObserved(tb, "duration_seconds") })
+	assertFails(t, true, func(tb testing.TB) { m.AssertObserved(tb, "duration_seconds", "method", "GET") })
+}
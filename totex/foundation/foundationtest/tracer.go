@@ -0,0 +1,330 @@
+package foundationtest
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+// Span is a span recorded by Tracer. Spans started with tracing.ChildOf or
+// tracing.StartSpanFromContext are linked to their parent and share its trace ID.
+type Span struct {
+	Name        string
+	SpanContext tracing.SpanContext
+	Start       time.Time
+
+	tracer   *Tracer
+	parent   *Span
+	children []*Span
+	tags     map[string]string
+	err      error
+	end      time.Time
+}
+
+// SetParent links the span to parent; it is called by tracing.ChildOf
+func (s *Span) SetParent(parent tracing.Span) {
+	p, ok := unwrapSpan(parent).(*Span)
+	if !ok || p.tracer != s.tracer {
+		return
+	}
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.parent = p
+	s.SpanContext.TraceID = p.SpanContext.TraceID
+	p.children = append(p.children, s)
+}
+
+func (s *Span) SetTag(key, value string) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.tags[key] = value
+}
+
+func (s *Span) SetError(err error) {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	s.err = err
+}
+
+func (s *Span) Finish() {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	if s.end.IsZero() {
+		s.end = time.Now()
+	}
+}
+
+// Context returns a context carrying the span and its span context
+func (s *Span) Context() context.Context {
+	ctx := tracing.ContextWithSpanContext(context.Background(), s.SpanContext)
+	return tracing.ContextWithSpan(ctx, s)
+}
+
+// Parent returns the parent span, or nil for a root span
+func (s *Span) Parent() *Span {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	return s.parent
+}
+
+// Children returns the spans started as children of s, in start order
+func (s *Span) Children() []*Span {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	return append([]*Span(nil), s.children...)
+}
+
+// Child returns the first child span named name, or nil
+func (s *Span) Child(name string) *Span {
+	for _, c := range s.Children() {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Tags returns a copy of the span's tags
+func (s *Span) Tags() map[string]string {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	tags := make(map[string]string, len(s.tags))
+	for k, v := range s.tags {
+		tags[k] = v
+	}
+	return tags
+}
+
+// Err returns the error set on the span
+func (s *Span) Err() error {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	return s.err
+}
+
+// Finished reports whether Finish was called
+func (s *Span) Finished() bool {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	return !s.end.IsZero()
+}
+
+// Duration returns how long the span ran, or zero if it has not finished
+func (s *Span) Duration() time.Duration {
+	s.tracer.mu.Lock()
+	defer s.tracer.mu.Unlock()
+	if s.end.IsZero() {
+		return 0
+	}
+	return s.end.Sub(s.Start)
+}
+
+// Tree renders the span and its descendants, one per line, indented by depth
+func (s *Span) Tree() string {
+	var b strings.Builder
+	s.writeTree(&b, 0)
+	return b.String()
+}
+
+func (s *Span) writeTree(b *strings.Builder, depth int) {
+	fmt.Fprintf(b, "%s%s", strings.Repeat("  ", depth), s.Name)
+	if tags := s.Tags(); len(tags) > 0 {
+		parts := make([]string, 0, len(tags))
+		for _, k := range sortedKeys(tags) {
+			parts = append(parts, k+"="+tags[k])
+		}
+		fmt.Fprintf(b, " {%s}", strings.Join(parts, " "))
+	}
+	if err := s.Err(); err != nil {
+		fmt.Fprintf(b, " error=%q", err)
+	}
+	b.WriteString("\n")
+	for _, c := range s.Children() {
+		c.writeTree(b, depth+1)
+	}
+}
+
+// Tracer is a tracing.Tracer that records every span for later inspection
+type Tracer struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+// NewTracer creates a recording Tracer
+func NewTracer() *Tracer {
+	return &Tracer{}
+}
+
+func (t *Tracer) StartSpan(name string, opts ...tracing.SpanOption) tracing.Span {
+	s := &Span{
+		Name:        name,
+		SpanContext: tracing.SpanContext{TraceID: randomID(16), SpanID: randomID(8), Sampled: true},
+		Start:       time.Now(),
+		tracer:      t,
+		tags:        make(map[string]string),
+	}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Inject does nothing; the recording tracer does not propagate across processes
+func (t *Tracer) Inject(span tracing.Span, format interface{}, carrier interface{}) error {
+	return nil
+}
+
+// Extract returns a new root span that is not recorded
+func (t *Tracer) Extract(format interface{}, carrier interface{}) (tracing.Span, error) {
+	return &Span{Name: "extracted", tracer: t, tags: make(map[string]string)}, nil
+}
+
+func (t *Tracer) Name() string { return "test-tracer" }
+
+// Spans returns every recorded span in start order
+func (t *Tracer) Spans() []*Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*Span(nil), t.spans...)
+}
+
+// Roots returns the recorded spans without a parent
+func (t *Tracer) Roots() []*Span {
+	var roots []*Span
+	for _, s := range t.Spans() {
+		if s.Parent() == nil {
+			roots = append(roots, s)
+		}
+	}
+	return roots
+}
+
+// Reset discards the recorded spans
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.spans = nil
+}
+
+// Find returns the first span named name with the given tag key/value pairs, or nil
+func (t *Tracer) Find(name string, tags ...string) *Span {
+	if found := t.FindAll(name, tags...); len(found) > 0 {
+		return found[0]
+	}
+	return nil
+}
+
+// FindAll returns every span named name with the given tag key/value pairs
+func (t *Tracer) FindAll(name string, tags ...string) []*Span {
+	var found []*Span
+	for _, s := range t.Spans() {
+		if s.Name == name && matchPairs(s.Tags(), toAny(tags)) {
+			found = append(found, s)
+		}
+	}
+	return found
+}
+
+// FindPath returns the first span reached by following names from a root
+// span down through its children, or nil. FindPath("a", "b") finds a span
+// "b" whose parent is a root span "a".
+func (t *Tracer) FindPath(names ...string) *Span {
+	if len(names) == 0 {
+		return nil
+	}
+	for _, root := range t.Roots() {
+		if root.Name != names[0] {
+			continue
+		}
+		if s := root.findPath(names[1:]); s != nil {
+			return s
+		}
+	}
+	return nil
+}
+
+func (s *Span) findPath(names []string) *Span {
+	if len(names) == 0 {
+		return s
+	}
+	for _, c := range s.Children() {
+		if c.Name != names[0] {
+			continue
+		}
+		if found := c.findPath(names[1:]); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// AssertSpan fails the test unless a finished span named name with the given
+// tag key/value pairs was recorded, and returns it
+func (t *Tracer) AssertSpan(tb testing.TB, name string, tags ...string) *Span {
+	tb.Helper()
+	s := t.Find(name, tags...)
+	switch {
+	case s == nil:
+		tb.Errorf("no span %q with %s; recorded:\n%s", name, formatPairs(tags), t.dump())
+	case !s.Finished():
+		tb.Errorf("span %q was not finished", name)
+	}
+	return s
+}
+
+// AssertPath fails the test unless a span is reached by following names from
+// a root span down through its children, and returns it
+func (t *Tracer) AssertPath(tb testing.TB, names ...string) *Span {
+	tb.Helper()
+	s := t.FindPath(names...)
+	if s == nil {
+		tb.Errorf("no span path %s; recorded:\n%s", strings.Join(names, " > "), t.dump())
+	}
+	return s
+}
+
+// AssertNoErrors fails the test if any recorded span has an error set
+func (t *Tracer) AssertNoErrors(tb testing.TB) {
+	tb.Helper()
+	for _, s := range t.Spans() {
+		if err := s.Err(); err != nil {
+			tb.Errorf("span %q has error: %v", s.Name, err)
+		}
+	}
+}
+
+func (t *Tracer) dump() string {
+	var b strings.Builder
+	for _, root := range t.Roots() {
+		root.writeTree(&b, 1)
+	}
+	return b.String()
+}
+
+// unwrapSpan returns the span beneath wrappers such as the app's redacting tracer
+func unwrapSpan(span tracing.Span) tracing.Span {
+	for {
+		u, ok := span.(interface{ Unwrap() tracing.Span })
+		if !ok {
+			return span
+		}
+		span = u.Unwrap()
+	}
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,81 @@
+package foundationtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+func TestTracerTree(t *testing.T) {
+	tracer := NewTracer()
+	root, ctx := tracing.StartSpanFromContext(context.Background(), tracer, "handle")
+	root.SetTag("procedure", "/user.v1.UserService/CreateUser")
+	child, ctx := tracing.StartSpanFromContext(ctx, tracer, "db.query")
+	child.SetTag("table", "users")
+	grandchild, _ := tracing.StartSpanFromContext(ctx, tracer, "db.connect")
+	grandchild.Finish()
+	child.Finish()
+	other := tracer.StartSpan("background")
+	other.Finish()
+	root.Finish()
+
+	if got := len(tracer.Spans()); got != 4 {
+		t.Fatalf("recorded %d spans, want 4", got)
+	}
+	roots := tracer.Roots()
+	if len(roots) != 2 || roots[0].Name != "handle" || roots[1].Name != "background" {
+		t.Fatalf("Roots = %v", roots)
+	}
+
+	h := roots[0]
+	db := h.Child("db.query")
+	if db == nil || db.Parent() != h || db.SpanContext.TraceID != h.SpanContext.TraceID {
+		t.Fatalf("db.query is not a child of handle in its trace:\n%s", h.Tree())
+	}
+	if h.Child("db.connect") != nil {
+		t.Error("Child found a grandchild")
+	}
+	if got := tracer.FindPath("handle", "db.query", "db.connect"); got == nil || got.Parent() != db {
+		t.Errorf("FindPath(handle > db.query > db.connect) = %v", got)
+	}
+	if got := tracer.FindPath("handle", "db.connect"); got != nil {
+		t.Error("FindPath skipped a level")
+	}
+	if got := tracer.Find("db.query", "table", "users"); got != db {
+		t.Errorf("Find(db.query, table=users) = %v", got)
+	}
+	if got := tracer.Find("db.query", "table", "orders"); got != nil {
+		t.Error("Find matched a different tag value")
+	}
+	if got := len(tracer.FindAll("handle", "procedure", "/user.v1.UserService/CreateUser")); got != 1 {
+		t.Errorf("FindAll = %d spans, want 1", got)
+	}
+	if !db.Finished() || db.Duration() <= 0 {
+		t.Errorf("db.query finished = %v after %v", db.Finished(), db.Duration())
+	}
+
+	tracer.Reset()
+	if got := len(tracer.Spans()); got != 0 {
+		t.Errorf("Reset left %d spans", got)
+	}
+}
+
+func TestTracerAsserts(t *testing.T) {
+	tracer := NewTracer()
+	root := tracer.StartSpan("handle")
+	child := tracer.StartSpan("db.query", tracing.ChildOf(root))
+	child.SetTag("table", "users")
+	child.Finish()
+
+	assertFails(t, false, func(tb testing.TB) { tracer.AssertSpan(tb, "db.query", "table", "users") })
+	assertFails(t, true, func(tb testing.TB) { tracer.AssertSpan(tb, "db.query", "table", "orders") })
+	assertFails(t, true, func(tb testing.TB) { tracer.AssertSpan(tb, "handle") }) // not finished
+	assertFails(t, false, func(tb testing.TB) { tracer.AssertPath(tb, "handle", "db.query") })
+	assertFails(t, true, func(tb testing.TB) { tracer.AssertPath(tb, "db.query") })
+	assertFails(t, false, tracer.AssertNoErrors)
+
+	child.SetError(errors.New("timeout"))
+	assertFails(t, true, tracer.AssertNoErrors)
+}
@@ -0,0 +1,109 @@
+// Package testpb builds protobuf messages for tests of code that handles
+// arbitrary messages, without generated code. Its Account message marks
+// fields with the foundation.v1.sensitive option as the schema module does.
+package testpb
+
+import (
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// account is the descriptor of test.v1.Account:
+//
+//	message Account {
+//	  string name = 1;
+//	  string password = 2 [(foundation.v1.sensitive) = true];
+//	  int64 pin = 3 [(foundation.v1.sensitive) = true];
+//	  Account referrer = 4;
+//	}
+var account = build()
+
+// Account returns a test.v1.Account; a non-nil referrer is set as its referrer
+func Account(name, password string, pin int64, referrer proto.Message) proto.Message {
+	m := dynamicpb.NewMessage(account)
+	fields := account.Fields()
+	m.Set(fields.ByName("name"), protoreflect.ValueOfString(name))
+	m.Set(fields.ByName("password"), protoreflect.ValueOfString(password))
+	m.Set(fields.ByName("pin"), protoreflect.ValueOfInt64(pin))
+	if referrer != nil {
+		m.Set(fields.ByName("referrer"), protoreflect.ValueOfMessage(referrer.ProtoReflect()))
+	}
+	return m
+}
+
+// Field returns the value of the named field of an Account; the referrer is
+// returned as a proto.Message
+func Field(m proto.Message, name string) any {
+	r := m.ProtoReflect()
+	v := r.Get(r.Descriptor().Fields().ByName(protoreflect.Name(name))).Interface()
+	if msg, ok := v.(protoreflect.Message); ok {
+		return msg.Interface()
+	}
+	return v
+}
+
+func build() protoreflect.MessageDescriptor {
+	files := new(protoregistry.Files)
+	must(files.RegisterFile(descriptorpb.File_google_protobuf_descriptor_proto))
+
+	options, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("foundation/v1/options.proto"),
+		Package:    proto.String("foundation.v1"),
+		Dependency: []string{"google/protobuf/descriptor.proto"},
+		Syntax:     proto.String("proto3"),
+		Extension: []*descriptorpb.FieldDescriptorProto{{
+			Name:     proto.String("sensitive"),
+			Number:   proto.Int32(50000),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     descriptorpb.FieldDescriptorProto_TYPE_BOOL.Enum(),
+			Extendee: proto.String(".google.protobuf.FieldOptions"),
+			JsonName: proto.String("sensitive"),
+		}},
+	}, files)
+	must(err)
+	must(files.RegisterFile(options))
+
+	sensitive := func() *descriptorpb.FieldOptions {
+		opts := &descriptorpb.FieldOptions{}
+		proto.SetExtension(opts, dynamicpb.NewExtensionType(options.Extensions().Get(0)), true)
+		return opts
+	}
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescriptorProto {
+		return &descriptorpb.FieldDescriptorProto{
+			Name:     proto.String(name),
+			Number:   proto.Int32(number),
+			Label:    descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum(),
+			Type:     typ.Enum(),
+			JsonName: proto.String(name),
+		}
+	}
+	password := field("password", 2, descriptorpb.FieldDescriptorProto_TYPE_STRING)
+	password.Options = sensitive()
+	pin := field("pin", 3, descriptorpb.FieldDescriptorProto_TYPE_INT64)
+	pin.Options = sensitive()
+	referrer := field("referrer", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE)
+	referrer.TypeName = proto.String(".test.v1.Account")
+
+	file, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:       proto.String("test/v1/account.proto"),
+		Package:    proto.String("test.v1"),
+		Dependency: []string{"foundation/v1/options.proto"},
+		Syntax:     proto.String("proto3"),
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name:  proto.String("Account"),
+			Field: []*descriptorpb.FieldDescriptorProto{field("name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING), password, pin, referrer},
+		}},
+	}, files)
+	must(err)
+	return file.Messages().Get(0)
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
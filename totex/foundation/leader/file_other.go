@@ -0,0 +1,25 @@
+//go:build !unix
+
+package leader
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FileBackend is only supported on unix platforms
+type FileBackend struct{}
+
+// NewFileBackend always fails on this platform
+func NewFileBackend(dir string) (*FileBackend, error) {
+	return nil, errors.New("file leader backend requires flock, which is not available on this platform")
+}
+
+func (b *FileBackend) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	return false, errors.New("file leader backend not supported")
+}
+
+func (b *FileBackend) Release(ctx context.Context, key, holder string) error {
+	return nil
+}
@@ -0,0 +1,90 @@
+//go:build unix
+
+package leader
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileBackendContention(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	// Separate backends hold separate open files, so they contend like processes
+	a, err := NewFileBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := NewFileBackend(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acquire := func(backend *FileBackend, holder string, want bool) {
+		t.Helper()
+		got, err := backend.Acquire(ctx, "jobs/cleanup", holder, 0)
+		if err != nil {
+			t.Fatalf("Acquire(%s): %v", holder, err)
+		}
+		if got != want {
+			t.Fatalf("Acquire(%s) = %v, want %v", holder, got, want)
+		}
+	}
+
+	acquire(a, "a", true)
+	acquire(a, "a", true) // renewal
+	acquire(b, "b", false)
+
+	data, err := os.ReadFile(a.path("jobs/cleanup"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(data)); got != "a" {
+		t.Errorf("lock file records holder %q, want a", got)
+	}
+
+	if err := a.Release(ctx, "jobs/cleanup", "b"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(b, "b", false)
+
+	if err := a.Release(ctx, "jobs/cleanup", "a"); err != nil {
+		t.Fatal(err)
+	}
+	acquire(b, "b", true)
+	acquire(a, "a", false)
+}
+
+func TestFileBackendSharedByHolders(t *testing.T) {
+	ctx := context.Background()
+	backend, err := NewFileBackend(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ok, err := backend.Acquire(ctx, "key", "first", 0); err != nil || !ok {
+		t.Fatalf("Acquire(first) = %v, %v; want true", ok, err)
+	}
+	if ok, err := backend.Acquire(ctx, "key", "second", 0); err != nil || ok {
+		t.Fatalf("Acquire(second) = %v, %v; want false while first holds the lock", ok, err)
+	}
+	// Releasing as another holder must not drop first's lock
+	if err := backend.Release(ctx, "key", "second"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := backend.Acquire(ctx, "key", "first", 0); !ok {
+		t.Fatal("first lost the lock to a release by second")
+	}
+	if ok, _ := backend.Acquire(ctx, "key", "second", 0); ok {
+		t.Fatal("second acquired the lock after releasing it for first")
+	}
+
+	if err := backend.Release(ctx, "key", "first"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := backend.Acquire(ctx, "key", "second", 0); err != nil || !ok {
+		t.Fatalf("Acquire(second) = %v, %v; want true once first released", ok, err)
+	}
+}
@@ -0,0 +1,177 @@
+package leader
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/yourusername/foundation/logging"
+)
+
+// Backend is a lock store used for leader election. Implementations for
+// etcd or Kubernetes Lease objects map Acquire to a create-or-renew of a
+// lease owned by holder, and Release to deleting it.
+type Backend interface {
+	// Acquire takes or renews the lease on key for holder and reports whether holder now owns it
+	Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error)
+	// Release gives up the lease on key if holder owns it
+	Release(ctx context.Context, key, holder string) error
+}
+
+const (
+	defaultTTL = 15 * time.Second
+	// releaseTimeout bounds giving up a lease after the elector's context is cancelled
+	releaseTimeout = 5 * time.Second
+)
+
+// Elector runs functions only while holding leadership of a key
+type Elector struct {
+	backend Backend
+	logger  logging.Logger
+	holder  string
+	ttl     time.Duration
+}
+
+// Option configures an Elector
+type Option func(*Elector)
+
+// WithTTL sets how long a lease lasts without renewal; leases are renewed every ttl/3
+func WithTTL(ttl time.Duration) Option {
+	return func(e *Elector) { e.ttl = ttl }
+}
+
+// WithHolder sets the identity recorded as lease holder; defaults to hostname, pid and a random suffix
+func WithHolder(holder string) Option {
+	return func(e *Elector) { e.holder = holder }
+}
+
+// NewElector creates an Elector on the given backend
+func NewElector(backend Backend, logger logging.Logger, opts ...Option) *Elector {
+	e := &Elector{
+		backend: backend,
+		logger:  logger,
+		holder:  defaultHolder(),
+		ttl:     defaultTTL,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
+// Holder returns the identity this elector acquires leases as
+func (e *Elector) Holder() string { return e.holder }
+
+// Run campaigns for key until ctx is cancelled. While leading it runs fn with
+// a context that is cancelled as soon as leadership is lost; fn is started
+// again if leadership is regained. Run returns fn's result if fn returns
+// while leading, or nil once ctx is cancelled.
+func (e *Elector) Run(ctx context.Context, key string, fn func(ctx context.Context) error) error {
+	retry := time.NewTicker(e.ttl / 3)
+	defer retry.Stop()
+
+	for {
+		acquired := time.Now()
+		leading, err := e.backend.Acquire(ctx, key, e.holder, e.ttl)
+		if err != nil && ctx.Err() == nil {
+			e.logger.Warn("Failed to acquire leadership", "key", key, "error", err)
+		}
+		if leading {
+			done, err := e.lead(ctx, key, acquired, fn)
+			if done {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-retry.C:
+		}
+	}
+}
+
+// lead runs fn while renewing the lease taken at acquired. It reports done
+// when Run should return: fn finished or ctx was cancelled, rather than
+// leadership being lost.
+//
+// The lease is counted from before the Acquire call that took or renewed it,
+// as the backend computes its expiry on its own clock, and fn is cancelled a
+// third of the TTL before it would expire, leaving room for Acquire latency
+// and clock skew before another replica can take over.
+func (e *Elector) lead(ctx context.Context, key string, acquired time.Time, fn func(ctx context.Context) error) (bool, error) {
+	e.logger.Info("Acquired leadership", "key", key, "holder", e.holder)
+
+	leadCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	result := make(chan error, 1)
+	go func() { result <- fn(leadCtx) }()
+
+	renew := time.NewTicker(e.ttl / 3)
+	defer renew.Stop()
+	margin := e.ttl - e.ttl/3
+	deadline := acquired.Add(margin)
+	expire := time.NewTimer(time.Until(deadline))
+	defer expire.Stop()
+
+	var err error
+	for {
+		select {
+		case err := <-result:
+			e.release(key)
+			return true, err
+		case <-ctx.Done():
+			<-result
+			e.release(key)
+			return true, nil
+		case <-expire.C:
+		case <-renew.C:
+			start := time.Now()
+			// A renewal that outlasts the lease is as good as a failed one
+			renewCtx, cancelRenew := context.WithDeadline(ctx, deadline)
+			var leading bool
+			leading, err = e.backend.Acquire(renewCtx, key, e.holder, e.ttl)
+			cancelRenew()
+			switch {
+			case err == nil && leading:
+				deadline = start.Add(margin)
+				expire.Reset(time.Until(deadline))
+				continue
+			case err != nil && ctx.Err() == nil && time.Now().Before(deadline):
+				// The lease has not expired yet, so keep leading and retry
+				e.logger.Warn("Failed to renew leadership", "key", key, "error", err)
+				continue
+			case ctx.Err() != nil:
+				continue
+			}
+		}
+		e.logger.Warn("Lost leadership", "key", key, "holder", e.holder, "error", err)
+		cancel()
+		<-result
+		return false, nil
+	}
+}
+
+func (e *Elector) release(key string) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	if err := e.backend.Release(ctx, key, e.holder); err != nil {
+		e.logger.Warn("Failed to release leadership", "key", key, "error", err)
+		return
+	}
+	e.logger.Info("Released leadership", "key", key, "holder", e.holder)
+}
+
+func defaultHolder() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s-%d-%s", host, os.Getpid(), hex.EncodeToString(suffix))
+}
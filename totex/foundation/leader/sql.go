@@ -0,0 +1,121 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Placeholder formats the n-th (1-based) bind parameter for a SQL dialect
+type Placeholder func(n int) string
+
+var (
+	// QuestionPlaceholder is used by MySQL and SQLite
+	QuestionPlaceholder Placeholder = func(int) string { return "?" }
+	// DollarPlaceholder is used by PostgreSQL
+	DollarPlaceholder Placeholder = func(n int) string { return fmt.Sprintf("$%d", n) }
+)
+
+const defaultLeaseTable = "leader_leases"
+
+// SQLBackend elects a leader through lease rows in a SQL table. Expiry times
+// are taken from the replicas' clocks, so they should be kept in sync to well
+// within the TTL.
+type SQLBackend struct {
+	db          *sql.DB
+	table       string
+	placeholder Placeholder
+}
+
+// SQLOption configures a SQLBackend
+type SQLOption func(*SQLBackend)
+
+// WithTable sets the lease table name; defaults to leader_leases
+func WithTable(table string) SQLOption {
+	return func(b *SQLBackend) { b.table = table }
+}
+
+// WithPlaceholder sets the bind parameter style; defaults to QuestionPlaceholder
+func WithPlaceholder(p Placeholder) SQLOption {
+	return func(b *SQLBackend) { b.placeholder = p }
+}
+
+// NewSQLBackend creates a SQLBackend on db
+func NewSQLBackend(db *sql.DB, opts ...SQLOption) *SQLBackend {
+	b := &SQLBackend{db: db, table: defaultLeaseTable, placeholder: QuestionPlaceholder}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// EnsureTable creates the lease table if it does not exist
+func (b *SQLBackend) EnsureTable(ctx context.Context) error {
+	_, err := b.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	name VARCHAR(255) NOT NULL PRIMARY KEY,
+	holder VARCHAR(255) NOT NULL,
+	expires_at BIGINT NOT NULL
+)`, b.table))
+	if err != nil {
+		return fmt.Errorf("create lease table: %w", err)
+	}
+	return nil
+}
+
+// Acquire renews the lease if holder owns it or it has expired, and otherwise
+// tries to create it
+func (b *SQLBackend) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	now := time.Now()
+	expiresAt := now.Add(ttl).UnixMilli()
+
+	res, err := b.db.ExecContext(ctx, b.query(
+		"UPDATE %s SET holder = %s, expires_at = %s WHERE name = %s AND (holder = %s OR expires_at < %s)"),
+		holder, expiresAt, key, holder, now.UnixMilli())
+	if err != nil {
+		return false, fmt.Errorf("renew lease: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		return true, nil
+	}
+
+	_, insertErr := b.db.ExecContext(ctx, b.query(
+		"INSERT INTO %s (name, holder, expires_at) VALUES (%s, %s, %s)"),
+		key, holder, expiresAt)
+	if insertErr == nil {
+		return true, nil
+	}
+
+	// A failed insert normally means another holder owns the row; only report
+	// the error if the row is in fact missing
+	var current string
+	err = b.db.QueryRowContext(ctx, b.query("SELECT holder FROM %s WHERE name = %s"), key).Scan(&current)
+	switch {
+	case err == nil:
+		return current == holder, nil
+	case errors.Is(err, sql.ErrNoRows):
+		return false, fmt.Errorf("create lease: %w", insertErr)
+	default:
+		return false, fmt.Errorf("read lease: %w", err)
+	}
+}
+
+// Release deletes the lease if holder owns it
+func (b *SQLBackend) Release(ctx context.Context, key, holder string) error {
+	_, err := b.db.ExecContext(ctx, b.query("DELETE FROM %s WHERE name = %s AND holder = %s"), key, holder)
+	if err != nil {
+		return fmt.Errorf("release lease: %w", err)
+	}
+	return nil
+}
+
+// query fills in the table name and numbered placeholders for every %s after it
+func (b *SQLBackend) query(format string) string {
+	args := []any{b.table}
+	for n := 1; n < strings.Count(format, "%s"); n++ {
+		args = append(args, b.placeholder(n))
+	}
+	return fmt.Sprintf(format, args...)
+}
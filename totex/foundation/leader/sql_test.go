@@ -0,0 +1,290 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/logging"
+)
+
+// leaseDriver is an in-process database/sql driver that runs the statements
+// of SQLBackend against a map, so lease handling can be tested without a
+// database server. Each DSN is a separate database.
+type leaseDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*leaseDB
+}
+
+type leaseDB struct {
+	mu      sync.Mutex
+	tables  map[string]map[string]lease
+	queries []string
+}
+
+type lease struct {
+	holder    string
+	expiresAt int64
+}
+
+var (
+	testDriver = &leaseDriver{dbs: make(map[string]*leaseDB)}
+	testDSN    atomic.Int64
+)
+
+func init() {
+	sql.Register("leasetest", testDriver)
+}
+
+// openLeaseDB opens a fresh in-process database
+func openLeaseDB(t *testing.T) (*sql.DB, *leaseDB) {
+	t.Helper()
+	dsn := fmt.Sprint(testDSN.Add(1))
+	db, err := sql.Open("leasetest", dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	testDriver.mu.Lock()
+	defer testDriver.mu.Unlock()
+	ldb := &leaseDB{tables: make(map[string]map[string]lease)}
+	testDriver.dbs[dsn] = ldb
+	return db, ldb
+}
+
+func (d *leaseDriver) Open(dsn string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[dsn]
+	if !ok {
+		return nil, fmt.Errorf("unknown database %q", dsn)
+	}
+	return &leaseConn{db: db}, nil
+}
+
+type leaseConn struct{ db *leaseDB }
+
+func (c *leaseConn) Prepare(query string) (driver.Stmt, error) {
+	return &leaseStmt{db: c.db, query: query}, nil
+}
+func (c *leaseConn) Close() error              { return nil }
+func (c *leaseConn) Begin() (driver.Tx, error) { return nil, errors.New("transactions not supported") }
+
+type leaseStmt struct {
+	db    *leaseDB
+	query string
+}
+
+func (s *leaseStmt) Close() error  { return nil }
+func (s *leaseStmt) NumInput() int { return -1 }
+
+// table returns the rows of the table the statement names, the word after
+// its first keyword
+func (s *leaseStmt) table(keyword string) map[string]lease {
+	_, rest, _ := strings.Cut(s.query, keyword+" ")
+	name, _, _ := strings.Cut(rest, " ")
+	name = strings.TrimSpace(name)
+	rows, ok := s.db.tables[name]
+	if !ok {
+		rows = make(map[string]lease)
+		s.db.tables[name] = rows
+	}
+	return rows
+}
+
+func (s *leaseStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.queries = append(s.db.queries, s.query)
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS"):
+		s.table("EXISTS")
+		return driver.RowsAffected(0), nil
+	case strings.HasPrefix(s.query, "UPDATE"):
+		rows := s.table("UPDATE")
+		holder, expiresAt, key, now := args[0].(string), args[1].(int64), args[2].(string), args[4].(int64)
+		l, ok := rows[key]
+		if !ok || (l.holder != holder && l.expiresAt >= now) {
+			return driver.RowsAffected(0), nil
+		}
+		rows[key] = lease{holder: holder, expiresAt: expiresAt}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "INSERT"):
+		rows := s.table("INTO")
+		key := args[0].(string)
+		if _, ok := rows[key]; ok {
+			return nil, errors.New("UNIQUE constraint failed: name")
+		}
+		rows[key] = lease{holder: args[1].(string), expiresAt: args[2].(int64)}
+		return driver.RowsAffected(1), nil
+	case strings.HasPrefix(s.query, "DELETE"):
+		rows := s.table("FROM")
+		key, holder := args[0].(string), args[1].(string)
+		if l, ok := rows[key]; ok && l.holder == holder {
+			delete(rows, key)
+			return driver.RowsAffected(1), nil
+		}
+		return driver.RowsAffected(0), nil
+	}
+	return nil, fmt.Errorf("unexpected statement %q", s.query)
+}
+
+func (s *leaseStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.db.mu.Lock()
+	defer s.db.mu.Unlock()
+	s.db.queries = append(s.db.queries, s.query)
+	if !strings.HasPrefix(s.query, "SELECT holder") {
+		return nil, fmt.Errorf("unexpected query %q", s.query)
+	}
+	rows := &leaseRows{}
+	if l, ok := s.table("FROM")[args[0].(string)]; ok {
+		rows.holders = []string{l.holder}
+	}
+	return rows, nil
+}
+
+type leaseRows struct{ holders []string }
+
+func (r *leaseRows) Columns() []string { return []string{"holder"} }
+func (r *leaseRows) Close() error      { return nil }
+func (r *leaseRows) Next(dest []driver.Value) error {
+	if len(r.holders) == 0 {
+		return io.EOF
+	}
+	dest[0], r.holders = r.holders[0], r.holders[1:]
+	return nil
+}
+
+func TestSQLBackendLease(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openLeaseDB(t)
+	backend := NewSQLBackend(db)
+	if err := backend.EnsureTable(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	acquire := func(holder string, ttl time.Duration, want bool) {
+		t.Helper()
+		got, err := backend.Acquire(ctx, "jobs/cleanup", holder, ttl)
+		if err != nil {
+			t.Fatalf("Acquire(%s): %v", holder, err)
+		}
+		if got != want {
+			t.Fatalf("Acquire(%s) = %v, want %v", holder, got, want)
+		}
+	}
+
+	acquire("a", time.Minute, true)
+	acquire("b", time.Minute, false)
+	acquire("a", time.Minute, true) // renewal
+
+	if err := backend.Release(ctx, "jobs/cleanup", "b"); err != nil {
+		t.Fatal(err)
+	}
+	acquire("b", time.Minute, false)
+
+	if err := backend.Release(ctx, "jobs/cleanup", "a"); err != nil {
+		t.Fatal(err)
+	}
+	acquire("b", time.Minute, true)
+}
+
+func TestSQLBackendExpiry(t *testing.T) {
+	ctx := context.Background()
+	db, _ := openLeaseDB(t)
+	backend := NewSQLBackend(db)
+
+	const ttl = 200 * time.Millisecond
+	if ok, err := backend.Acquire(ctx, "key", "a", ttl); err != nil || !ok {
+		t.Fatalf("Acquire(a) = %v, %v; want true", ok, err)
+	}
+	// Renewing within the TTL keeps the lease from expiring
+	time.Sleep(ttl / 2)
+	if ok, _ := backend.Acquire(ctx, "key", "a", ttl); !ok {
+		t.Fatal("renewal failed")
+	}
+	time.Sleep(ttl / 2)
+	if ok, _ := backend.Acquire(ctx, "key", "b", ttl); ok {
+		t.Fatal("b took a renewed lease")
+	}
+
+	// Without renewal the lease expires and another holder takes it over
+	time.Sleep(2 * ttl)
+	if ok, err := backend.Acquire(ctx, "key", "b", ttl); err != nil || !ok {
+		t.Fatalf("Acquire(b) = %v, %v; want true once the lease expired", ok, err)
+	}
+	if ok, _ := backend.Acquire(ctx, "key", "a", ttl); ok {
+		t.Fatal("a still holds the lease after b took it over")
+	}
+}
+
+func TestSQLBackendOptions(t *testing.T) {
+	ctx := context.Background()
+	db, ldb := openLeaseDB(t)
+	backend := NewSQLBackend(db, WithTable("locks"), WithPlaceholder(DollarPlaceholder))
+
+	if ok, err := backend.Acquire(ctx, "key", "a", time.Minute); err != nil || !ok {
+		t.Fatalf("Acquire = %v, %v; want true", ok, err)
+	}
+	if _, ok := ldb.tables["locks"]["key"]; !ok {
+		t.Fatalf("lease not stored in the locks table: %v", ldb.tables)
+	}
+	want := "UPDATE locks SET holder = $1, expires_at = $2 WHERE name = $3 AND (holder = $4 OR expires_at < $5)"
+	if ldb.queries[0] != want {
+		t.Errorf("query = %q, want %q", ldb.queries[0], want)
+	}
+}
+
+// failingRenewals passes the first Acquire to its backend and fails the rest
+type failingRenewals struct {
+	Backend
+	calls atomic.Int64
+}
+
+func (b *failingRenewals) Acquire(ctx context.Context, key, holder string, ttl time.Duration) (bool, error) {
+	if b.calls.Add(1) > 1 {
+		return false, errors.New("connection refused")
+	}
+	return b.Backend.Acquire(ctx, key, holder, ttl)
+}
+
+func TestElectorStopsBeforeLeaseExpires(t *testing.T) {
+	db, ldb := openLeaseDB(t)
+	backend := &failingRenewals{Backend: NewSQLBackend(db)}
+	const ttl = 300 * time.Millisecond
+	e := NewElector(backend, logging.NewSlogLoggerWithWriter("test", "error", "text", io.Discard), WithTTL(ttl), WithHolder("a"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*ttl)
+	defer cancel()
+	lost := make(chan time.Time, 1)
+	go e.Run(ctx, "key", func(ctx context.Context) error {
+		<-ctx.Done()
+		lost <- time.Now()
+		cancel()
+		return nil
+	})
+
+	var stopped time.Time
+	select {
+	case stopped = <-lost:
+	case <-time.After(5 * ttl):
+		t.Fatal("fn kept running after renewals failed")
+	}
+	ldb.mu.Lock()
+	expiresAt := time.UnixMilli(ldb.tables[defaultLeaseTable]["key"].expiresAt)
+	ldb.mu.Unlock()
+	if !stopped.Before(expiresAt) {
+		t.Errorf("fn cancelled at %v, after the lease expired at %v", stopped, expiresAt)
+	}
+	if backend.calls.Load() < 2 {
+		t.Errorf("Acquire called %d times, want renewals before giving up", backend.calls.Load())
+	}
+}
@@ -0,0 +1,62 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+type requestIDKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// contextHandler adds trace_id, span_id, request_id and the configured
+// baggage members from the record's context to every record
+type contextHandler struct {
+	slog.Handler
+	baggageKeys []string
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	r.AddAttrs(contextAttrs(ctx, h.baggageKeys)...)
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs), h.baggageKeys}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name), h.baggageKeys}
+}
+
+// contextAttrs returns the correlation attributes carried by ctx, and its
+// baggage members under baggageKeys
+func contextAttrs(ctx context.Context, baggageKeys []string) []slog.Attr {
+	var attrs []slog.Attr
+	if sc := tracing.SpanContextFromContext(ctx); sc.IsValid() {
+		attrs = append(attrs, slog.String("trace_id", sc.TraceID), slog.String("span_id", sc.SpanID))
+	}
+	if id := RequestIDFromContext(ctx); id != "" {
+		attrs = append(attrs, slog.String("request_id", id))
+	}
+	if len(baggageKeys) > 0 {
+		baggage := tracing.BaggageFromContext(ctx)
+		for _, key := range baggageKeys {
+			if v, ok := baggage.Get(key); ok {
+				attrs = append(attrs, slog.String(key, v))
+			}
+		}
+	}
+	return attrs
+}
@@ -0,0 +1,268 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RootLogger is the name under which the root logger's level is registered
+const RootLogger = "root"
+
+// Levels is a registry of logger levels that can be changed at runtime.
+// Named loggers form a dot-separated hierarchy: unless overridden,
+// "user.repository" follows "user", which follows the root level.
+type Levels struct {
+	mu    sync.Mutex
+	root  *namedLevel
+	named map[string]*namedLevel
+}
+
+// namedLevel is the level of one logger; it implements slog.Leveler
+type namedLevel struct {
+	name   string
+	parent *namedLevel
+
+	// override holds the level set for this logger, valid when hasOverride is set
+	override    atomic.Int64
+	hasOverride atomic.Bool
+
+	// The pending revert of a temporary change; guarded by Levels.mu
+	revert      *time.Timer
+	expiresAt   time.Time
+	revertLevel slog.Level
+	revertHad   bool
+}
+
+func (n *namedLevel) Level() slog.Level {
+	for l := n; l != nil; l = l.parent {
+		if l.hasOverride.Load() {
+			return slog.Level(l.override.Load())
+		}
+	}
+	return slog.LevelInfo
+}
+
+func (n *namedLevel) set(level slog.Level) {
+	n.override.Store(int64(level))
+	n.hasOverride.Store(true)
+}
+
+// LevelInfo describes the level of one logger
+type LevelInfo struct {
+	Name      string     `json:"name"`
+	Level     string     `json:"level"`
+	Inherited bool       `json:"inherited"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewLevels creates a registry with the root logger at level
+func NewLevels(level slog.Level) *Levels {
+	root := &namedLevel{name: RootLogger}
+	root.set(level)
+	return &Levels{root: root, named: make(map[string]*namedLevel)}
+}
+
+// Root returns the root logger's level
+func (l *Levels) Root() slog.Leveler {
+	return l.root
+}
+
+// Leveler returns the level of the named logger, registering it and its
+// parents if needed
+func (l *Levels) Leveler(name string) slog.Leveler {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.lookup(name)
+}
+
+// lookup finds or creates the entry for name; the caller holds l.mu
+func (l *Levels) lookup(name string) *namedLevel {
+	if name == "" || name == RootLogger {
+		return l.root
+	}
+	if n, ok := l.named[name]; ok {
+		return n
+	}
+	parent := l.root
+	if i := strings.LastIndex(name, "."); i > 0 {
+		parent = l.lookup(name[:i])
+	}
+	n := &namedLevel{name: name, parent: parent}
+	l.named[name] = n
+	return n
+}
+
+// Set changes the level of the named logger. With a positive ttl the change
+// is reverted automatically once ttl has passed.
+func (l *Levels) Set(name string, level slog.Level, ttl time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.lookup(name)
+	if n.revert != nil {
+		// Keep reverting to the level from before the earlier temporary change
+		n.revert.Stop()
+		n.revert = nil
+	} else {
+		n.revertLevel, n.revertHad = slog.Level(n.override.Load()), n.hasOverride.Load()
+	}
+	n.set(level)
+	n.expiresAt = time.Time{}
+	if ttl <= 0 {
+		return
+	}
+
+	n.expiresAt = time.Now().Add(ttl)
+	var timer *time.Timer
+	timer = time.AfterFunc(ttl, func() {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if n.revert != timer {
+			// Superseded by a later change
+			return
+		}
+		n.revert = nil
+		n.expiresAt = time.Time{}
+		if n.revertHad {
+			n.set(n.revertLevel)
+		} else {
+			n.clear()
+		}
+	})
+	n.revert = timer
+}
+
+// Reset removes the override for the named logger so it follows its parent again.
+// The root logger cannot be reset.
+func (l *Levels) Reset(name string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	n := l.lookup(name)
+	if n == l.root {
+		return fmt.Errorf("the root logger level cannot be reset")
+	}
+	if n.revert != nil {
+		n.revert.Stop()
+		n.revert = nil
+		n.expiresAt = time.Time{}
+	}
+	n.clear()
+	return nil
+}
+
+// List returns the level of every registered logger, root first
+func (l *Levels) List() []LevelInfo {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	names := make([]string, 0, len(l.named))
+	for name := range l.named {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	infos := []LevelInfo{l.root.info()}
+	for _, name := range names {
+		infos = append(infos, l.named[name].info())
+	}
+	return infos
+}
+
+func (n *namedLevel) info() LevelInfo {
+	info := LevelInfo{
+		Name:      n.name,
+		Level:     strings.ToLower(n.Level().String()),
+		Inherited: !n.hasOverride.Load(),
+	}
+	if !n.expiresAt.IsZero() {
+		expiresAt := n.expiresAt
+		info.ExpiresAt = &expiresAt
+	}
+	return info
+}
+
+func (n *namedLevel) clear() {
+	n.hasOverride.Store(false)
+}
+
+// Handler serves the registry for runtime level changes and always responds
+// with the resulting list of loggers:
+//
+//	GET                                           list all loggers
+//	PUT    ?name=user.repository&level=debug&ttl=10m  set a level, reverting after the optional ttl
+//	DELETE ?name=user.repository                  make the logger follow its parent again
+func (l *Levels) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := r.URL.Query().Get("name")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			level, ok := ParseLevel(r.URL.Query().Get("level"))
+			if !ok {
+				http.Error(w, "level must be one of debug, info, warn, error", http.StatusBadRequest)
+				return
+			}
+			var ttl time.Duration
+			if s := r.URL.Query().Get("ttl"); s != "" {
+				var err error
+				if ttl, err = time.ParseDuration(s); err != nil || ttl < 0 {
+					http.Error(w, "invalid ttl", http.StatusBadRequest)
+					return
+				}
+			}
+			l.Set(name, level, ttl)
+		case http.MethodDelete:
+			if err := l.Reset(name); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, PUT, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(l.List())
+	})
+}
+
+// ParseLevel parses a level name (debug, info, warn, error)
+func ParseLevel(s string) (slog.Level, bool) {
+	switch strings.ToLower(s) {
+	case "debug":
+		return slog.LevelDebug, true
+	case "info":
+		return slog.LevelInfo, true
+	case "warn", "warning":
+		return slog.LevelWarn, true
+	case "error":
+		return slog.LevelError, true
+	default:
+		return 0, false
+	}
+}
+
+// LevelController is implemented by loggers whose levels are managed by a Levels registry
+type LevelController interface {
+	Levels() *Levels
+}
+
+// childName returns the dotted name of a named child of the logger called
+// parent; the root logger's own name is not part of the hierarchy
+func childName(parent string, levels *Levels, name string) string {
+	levels.mu.Lock()
+	_, named := levels.named[parent]
+	levels.mu.Unlock()
+	if !named {
+		return name
+	}
+	return parent + "." + name
+}
@@ -1,11 +1,23 @@
 package logging
 
-// Logger interface for structured logging
+import (
+	"context"
+)
+
+// Logger interface for structured logging. The Context variants add the
+// trace_id, span_id and request_id carried by ctx to the log line, and the
+// baggage members selected with WithBaggageKeys. Named
+// returns a child logger whose level can be changed at runtime on its own.
 type Logger interface {
 	Debug(msg string, args ...any)
 	Info(msg string, args ...any)
 	Warn(msg string, args ...any)
 	Error(msg string, args ...any)
+	DebugContext(ctx context.Context, msg string, args ...any)
+	InfoContext(ctx context.Context, msg string, args ...any)
+	WarnContext(ctx context.Context, msg string, args ...any)
+	ErrorContext(ctx context.Context, msg string, args ...any)
 	With(args ...any) Logger
+	Named(name string) Logger
 	Name() string
 }
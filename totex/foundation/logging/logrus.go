@@ -1,11 +1,187 @@
 package logging
 
 import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+
 	"github.com/sirupsen/logrus"
+
+	"github.com/yourusername/foundation/redact"
 )
 
 // LogrusLogger implements the Logger interface using logrus
 type LogrusLogger struct {
-	logger *logrus.Logger
-	name   string
+	logger      *logrus.Logger
+	entry       *logrus.Entry
+	name        string
+	levels      *Levels
+	level       slog.Leveler
+	redactor    *redact.Redactor
+	sampler     *sampler
+	baggageKeys []string
+}
+
+// NewLogrusLogger creates a new logrus-based logger writing to w
+func NewLogrusLogger(name string, level, format string, w io.Writer, opts ...Option) Logger {
+	logger := logrus.New()
+	logger.SetOutput(w)
+	// Levels are filtered per logger in log, so logrus itself lets everything through
+	logger.SetLevel(logrus.DebugLevel)
+	if format == "json" {
+		logger.SetFormatter(&logrus.JSONFormatter{})
+	} else {
+		logger.SetFormatter(&logrus.TextFormatter{FullTimestamp: true, DisableColors: true})
+	}
+
+	o := newOptions(opts)
+	levels := NewLevels(parseLevel(level))
+	l := &LogrusLogger{
+		logger:      logger,
+		entry:       logrus.NewEntry(logger),
+		name:        name,
+		levels:      levels,
+		level:       levels.Root(),
+		redactor:    o.redactor,
+		baggageKeys: o.baggageKeys,
+	}
+	if o.sampling.Interval > 0 {
+		l.sampler = newSampler(o.sampling, func(msg string, args ...any) {
+			l.entry.WithFields(l.fields(args)).Warn(msg)
+		})
+	}
+	return l
+}
+
+// logrusLevel maps a slog level to the matching logrus level
+func logrusLevel(level slog.Level) logrus.Level {
+	switch {
+	case level <= slog.LevelDebug:
+		return logrus.DebugLevel
+	case level <= slog.LevelInfo:
+		return logrus.InfoLevel
+	case level <= slog.LevelWarn:
+		return logrus.WarnLevel
+	default:
+		return logrus.ErrorLevel
+	}
+}
+
+// fromLogrusLevel maps a logrus level to the matching slog level
+func fromLogrusLevel(level logrus.Level) slog.Level {
+	switch {
+	case level >= logrus.DebugLevel:
+		return slog.LevelDebug
+	case level == logrus.InfoLevel:
+		return slog.LevelInfo
+	case level == logrus.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func (l *LogrusLogger) Debug(msg string, args ...any) { l.log(nil, logrus.DebugLevel, msg, args) }
+func (l *LogrusLogger) Info(msg string, args ...any)  { l.log(nil, logrus.InfoLevel, msg, args) }
+func (l *LogrusLogger) Warn(msg string, args ...any)  { l.log(nil, logrus.WarnLevel, msg, args) }
+func (l *LogrusLogger) Error(msg string, args ...any) { l.log(nil, logrus.ErrorLevel, msg, args) }
+func (l *LogrusLogger) Name() string                  { return l.name }
+
+func (l *LogrusLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logrus.DebugLevel, msg, args)
+}
+func (l *LogrusLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logrus.InfoLevel, msg, args)
+}
+func (l *LogrusLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logrus.WarnLevel, msg, args)
+}
+func (l *LogrusLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.log(ctx, logrus.ErrorLevel, msg, args)
+}
+
+// With returns a child logger that adds args to every line
+func (l *LogrusLogger) With(args ...any) Logger {
+	return &LogrusLogger{
+		logger:      l.logger,
+		entry:       l.entry.WithFields(l.fields(args)),
+		name:        l.name,
+		levels:      l.levels,
+		level:       l.level,
+		redactor:    l.redactor,
+		sampler:     l.sampler,
+		baggageKeys: l.baggageKeys,
+	}
+}
+
+// Named returns a child logger whose level can be changed on its own through Levels
+func (l *LogrusLogger) Named(name string) Logger {
+	name = childName(l.name, l.levels, name)
+	return &LogrusLogger{
+		logger:      l.logger,
+		entry:       l.entry.WithField("logger", name),
+		name:        name,
+		levels:      l.levels,
+		level:       l.levels.Leveler(name),
+		redactor:    l.redactor,
+		sampler:     l.sampler,
+		baggageKeys: l.baggageKeys,
+	}
+}
+
+// Levels returns the registry controlling this logger's level and its named children's
+func (l *LogrusLogger) Levels() *Levels { return l.levels }
+
+func (l *LogrusLogger) log(ctx context.Context, level logrus.Level, msg string, args []any) {
+	if logrusLevel(l.level.Level()) < level {
+		return
+	}
+	if l.sampler != nil && !l.sampler.allow(fromLogrusLevel(level), msg) {
+		return
+	}
+	entry := l.entry
+	if ctx != nil {
+		entry = entry.WithContext(ctx)
+		for _, attr := range contextAttrs(ctx, l.baggageKeys) {
+			entry = entry.WithField(attr.Key, l.redactor.Value(attr.Key, attr.Value.Any()))
+		}
+	}
+	if len(args) > 0 {
+		entry = entry.WithFields(l.fields(args))
+	}
+	entry.Log(level, msg)
+}
+
+// fields converts slog-style key/value pairs and slog.Attrs to logrus fields,
+// masking sensitive values
+func (l *LogrusLogger) fields(args []any) logrus.Fields {
+	fields := make(logrus.Fields, len(args)/2)
+	for i := 0; i < len(args); i++ {
+		switch key := args[i].(type) {
+		case slog.Attr:
+			fields[key.Key] = logrusValue(attrValue(l.redactor, key))
+		case string:
+			if i+1 == len(args) {
+				fields["!BADKEY"] = key
+				break
+			}
+			fields[key] = logrusValue(l.redactor.Value(key, args[i+1]))
+			i++
+		default:
+			fields["!BADKEY"] = key
+		}
+	}
+	return fields
+}
+
+// logrusValue renders errors as their message, which the JSON formatter would otherwise drop
+func logrusValue(v any) any {
+	if err, ok := v.(error); ok {
+		return err.Error()
+	}
+	if s, ok := v.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return v
 }
@@ -0,0 +1,37 @@
+package logging
+
+import "github.com/yourusername/foundation/redact"
+
+// Option configures a logger backend
+type Option func(*options)
+
+type options struct {
+	redactor    *redact.Redactor
+	sampling    SamplingOptions
+	baggageKeys []string
+}
+
+// WithRedactor sets the redactor applied to attribute values; loggers use
+// redact.Default() unless configured otherwise, and a nil redactor disables redaction
+func WithRedactor(r *redact.Redactor) Option {
+	return func(o *options) { o.redactor = r }
+}
+
+// WithSampling samples repeated lines as described by SamplingOptions
+func WithSampling(sampling SamplingOptions) Option {
+	return func(o *options) { o.sampling = sampling }
+}
+
+// WithBaggageKeys adds the baggage members under keys carried by a line's
+// context to the line, as attributes named after the keys
+func WithBaggageKeys(keys ...string) Option {
+	return func(o *options) { o.baggageKeys = keys }
+}
+
+func newOptions(opts []Option) options {
+	o := options{redactor: redact.Default()}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -0,0 +1,264 @@
+package logging
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// OutputOptions configures where log lines are written
+type OutputOptions struct {
+	Path       string        // "stdout", "stderr" or a file path
+	MaxSize    int64         // rotate once the file would exceed this many bytes; 0 disables
+	MaxAge     time.Duration // rotate once the file has been open this long; 0 disables
+	MaxBackups int           // rotated files to keep; 0 keeps all
+	Compress   bool          // gzip rotated files
+}
+
+const (
+	// backupTimeFormat names rotated files; it sorts chronologically
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+	// rotateRetryInterval spaces out attempts after a failed rotation
+	rotateRetryInterval = time.Second
+)
+
+// OpenOutput opens the output described by opts. Files are appended to and
+// rotated according to opts; stdout and stderr are never closed.
+func OpenOutput(opts OutputOptions) (io.WriteCloser, error) {
+	switch opts.Path {
+	case "", "stdout":
+		return nopCloser{os.Stdout}, nil
+	case "stderr":
+		return nopCloser{os.Stderr}, nil
+	default:
+		return NewRotatingFile(opts)
+	}
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// RotatingFile is a log file that rotates by size and age, keeps a bounded
+// number of optionally compressed backups, and can be reopened after an
+// external tool such as logrotate has moved it
+type RotatingFile struct {
+	opts OutputOptions
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+	retryAt  time.Time
+	signals  chan os.Signal
+	cleanup  sync.WaitGroup
+}
+
+// NewRotatingFile opens opts.Path for appending, creating it if needed
+func NewRotatingFile(opts OutputOptions) (*RotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(opts.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	f := &RotatingFile{opts: opts}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write appends p, rotating first if the size or age limit is reached. If
+// rotation fails, p goes to the current file and rotation is retried later.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.needsRotation(int64(len(p))) {
+		if err := f.rotate(); err != nil {
+			f.retryAt = time.Now().Add(rotateRetryInterval)
+			fmt.Fprintf(os.Stderr, "logging: rotate %s: %v\n", f.opts.Path, err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate moves the current file aside and starts a new one
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.rotate()
+}
+
+// Reopen reopens the file at the configured path, picking up a new file
+// after an external rotation. The current file stays in use if that fails.
+func (f *RotatingFile) Reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.open()
+}
+
+// ReopenOnSignal reopens the file whenever SIGHUP arrives, until Close
+func (f *RotatingFile) ReopenOnSignal() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.signals != nil {
+		return
+	}
+	f.signals = make(chan os.Signal, 1)
+	signal.Notify(f.signals, syscall.SIGHUP)
+	go func(signals chan os.Signal) {
+		for range signals {
+			if err := f.Reopen(); err != nil {
+				fmt.Fprintf(os.Stderr, "logging: reopen %s: %v\n", f.opts.Path, err)
+			}
+		}
+	}(f.signals)
+}
+
+// Close closes the file and waits for pending compression and cleanup
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.signals != nil {
+		signal.Stop(f.signals)
+		close(f.signals)
+		f.signals = nil
+	}
+	var err error
+	if f.file != nil {
+		err = f.file.Close()
+		f.file = nil
+	}
+	f.mu.Unlock()
+
+	f.cleanup.Wait()
+	return err
+}
+
+// open opens the configured path and switches writes to it, closing the
+// previous file only once the new one is open
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.opts.Path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	if f.file != nil {
+		f.file.Close()
+	}
+	f.file = file
+	f.size = info.Size()
+	f.openedAt = time.Now()
+	return nil
+}
+
+func (f *RotatingFile) needsRotation(next int64) bool {
+	if time.Now().Before(f.retryAt) {
+		return false
+	}
+	if f.opts.MaxSize > 0 && f.size > 0 && f.size+next > f.opts.MaxSize {
+		return true
+	}
+	return f.opts.MaxAge > 0 && time.Since(f.openedAt) > f.opts.MaxAge
+}
+
+// rotate renames the current file to a timestamped backup and opens a new
+// one; compression and pruning of old backups happen in the background. On
+// failure the current file, renamed or not, stays open so no lines are lost.
+func (f *RotatingFile) rotate() error {
+	ext := filepath.Ext(f.opts.Path)
+	base := strings.TrimSuffix(f.opts.Path, ext)
+	backup := fmt.Sprintf("%s-%s%s", base, time.Now().Format(backupTimeFormat), ext)
+	if err := os.Rename(f.opts.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+
+	f.cleanup.Add(1)
+	go func() {
+		defer f.cleanup.Done()
+		if f.opts.Compress {
+			if err := compressFile(backup); err != nil {
+				fmt.Fprintf(os.Stderr, "logging: compress %s: %v\n", backup, err)
+			}
+		}
+		f.prune(base, ext)
+	}()
+	return nil
+}
+
+// prune removes the oldest backups beyond MaxBackups
+func (f *RotatingFile) prune(base, ext string) {
+	if f.opts.MaxBackups <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(base + "-*" + ext + "*")
+	if err != nil {
+		return
+	}
+	var backups []string
+	for _, m := range matches {
+		stamp := strings.TrimPrefix(m, base+"-")
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		if _, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)]); err == nil {
+			backups = append(backups, m)
+		}
+	}
+	// Names embed the rotation time, so lexical order is chronological
+	sort.Strings(backups)
+	for len(backups) > f.opts.MaxBackups {
+		os.Remove(backups[0])
+		backups = backups[1:]
+	}
+}
+
+// compressFile gzips path to path.gz and removes the original
+func compressFile(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path+".gz", os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	gz := gzip.NewWriter(dst)
+	if _, err := io.Copy(gz, src); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := gz.Close(); err != nil {
+		dst.Close()
+		os.Remove(path + ".gz")
+		return err
+	}
+	if err := dst.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
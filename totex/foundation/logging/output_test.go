@@ -0,0 +1,205 @@
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestFile(t *testing.T, opts OutputOptions) *RotatingFile {
+	t.Helper()
+	if opts.Path == "" {
+		opts.Path = filepath.Join(t.TempDir(), "app.log")
+	}
+	f, err := NewRotatingFile(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func write(t *testing.T, f *RotatingFile, line string) {
+	t.Helper()
+	if n, err := f.Write([]byte(line + "\n")); err != nil || n != len(line)+1 {
+		t.Fatalf("Write(%q) = %d, %v", line, n, err)
+	}
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// backups lists the rotated files next to path, oldest first
+func backups(t *testing.T, path string) []string {
+	t.Helper()
+	ext := filepath.Ext(path)
+	matches, err := filepath.Glob(strings.TrimSuffix(path, ext) + "-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+func TestRotatingFileMaxSize(t *testing.T) {
+	f := openTestFile(t, OutputOptions{MaxSize: 10})
+	write(t, f, "first")
+	write(t, f, "second") // 6+7 bytes exceed 10, so this starts a new file
+	write(t, f, "x")
+	f.Close()
+
+	if got := readFile(t, f.opts.Path); got != "second\nx\n" {
+		t.Errorf("current file = %q", got)
+	}
+	b := backups(t, f.opts.Path)
+	if len(b) != 1 || readFile(t, b[0]) != "first\n" {
+		t.Errorf("backups = %v", b)
+	}
+	if !strings.HasSuffix(b[0], ".log") {
+		t.Errorf("backup %s lost the extension", b[0])
+	}
+}
+
+func TestRotatingFileMaxAge(t *testing.T) {
+	f := openTestFile(t, OutputOptions{MaxAge: time.Hour})
+	write(t, f, "old")
+	write(t, f, "still old")
+	f.mu.Lock()
+	f.openedAt = time.Now().Add(-2 * time.Hour)
+	f.mu.Unlock()
+	write(t, f, "new")
+	f.Close()
+
+	if got := readFile(t, f.opts.Path); got != "new\n" {
+		t.Errorf("current file = %q", got)
+	}
+	if b := backups(t, f.opts.Path); len(b) != 1 || readFile(t, b[0]) != "old\nstill old\n" {
+		t.Errorf("backups = %v", b)
+	}
+}
+
+func TestRotatingFileMaxBackups(t *testing.T) {
+	f := openTestFile(t, OutputOptions{MaxBackups: 2})
+	for _, line := range []string{"1", "2", "3", "4"} {
+		write(t, f, line)
+		if err := f.Rotate(); err != nil {
+			t.Fatal(err)
+		}
+		// Backup names have millisecond resolution
+		time.Sleep(2 * time.Millisecond)
+	}
+	f.Close()
+
+	b := backups(t, f.opts.Path)
+	if len(b) != 2 {
+		t.Fatalf("kept %d backups, want 2: %v", len(b), b)
+	}
+	if readFile(t, b[0]) != "3\n" || readFile(t, b[1]) != "4\n" {
+		t.Errorf("kept %q and %q, want the newest two", readFile(t, b[0]), readFile(t, b[1]))
+	}
+}
+
+func TestRotatingFileCompress(t *testing.T) {
+	f := openTestFile(t, OutputOptions{Compress: true})
+	write(t, f, "compressed")
+	if err := f.Rotate(); err != nil {
+		t.Fatal(err)
+	}
+	// Close waits for the background compression
+	f.Close()
+
+	b := backups(t, f.opts.Path)
+	if len(b) != 1 || !strings.HasSuffix(b[0], ".log.gz") {
+		t.Fatalf("backups = %v, want one gzipped file", b)
+	}
+	src, err := os.Open(b[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "compressed\n" {
+		t.Errorf("decompressed = %q", data)
+	}
+}
+
+func TestRotatingFileReopen(t *testing.T) {
+	f := openTestFile(t, OutputOptions{})
+	write(t, f, "before")
+
+	// An external tool moves the file; lines follow it until Reopen
+	moved := f.opts.Path + ".1"
+	if err := os.Rename(f.opts.Path, moved); err != nil {
+		t.Fatal(err)
+	}
+	write(t, f, "moved")
+	if err := f.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	write(t, f, "after")
+	f.Close()
+
+	if got := readFile(t, moved); got != "before\nmoved\n" {
+		t.Errorf("moved file = %q", got)
+	}
+	if got := readFile(t, f.opts.Path); got != "after\n" {
+		t.Errorf("reopened file = %q", got)
+	}
+	if err := f.Reopen(); err != os.ErrClosed {
+		t.Errorf("Reopen after Close = %v, want os.ErrClosed", err)
+	}
+	if _, err := f.Write([]byte("closed\n")); err != os.ErrClosed {
+		t.Errorf("Write after Close = %v, want os.ErrClosed", err)
+	}
+}
+
+func TestRotatingFileFailedRotation(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "logs")
+	f := openTestFile(t, OutputOptions{Path: filepath.Join(dir, "app.log"), MaxSize: 10})
+	write(t, f, "first")
+
+	// Opening the new file fails while the directory is gone
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Rotate(); err == nil {
+		t.Fatal("Rotate succeeded without a log directory")
+	}
+	write(t, f, "kept writing")
+	if err := f.Reopen(); err == nil {
+		t.Fatal("Reopen succeeded without a log directory")
+	}
+	write(t, f, "still writing")
+
+	// Once the directory is back the next rotation recovers
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	f.mu.Lock()
+	f.retryAt = time.Time{}
+	f.mu.Unlock()
+	write(t, f, "recovered")
+	f.Close()
+
+	if got := readFile(t, f.opts.Path); got != "recovered\n" {
+		t.Errorf("log file = %q", got)
+	}
+}
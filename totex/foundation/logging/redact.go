@@ -0,0 +1,75 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/yourusername/foundation/redact"
+)
+
+// redactHandler masks sensitive attribute values before they reach the wrapped handler
+type redactHandler struct {
+	slog.Handler
+	redactor *redact.Redactor
+}
+
+// newRedactHandler wraps h, unless redaction is disabled
+func newRedactHandler(h slog.Handler, r *redact.Redactor) slog.Handler {
+	if r == nil {
+		return h
+	}
+	return redactHandler{h, r}
+}
+
+func (h redactHandler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, r.Message, r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.attr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, out)
+}
+
+func (h redactHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	redacted := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		redacted[i] = h.attr(a)
+	}
+	return redactHandler{h.Handler.WithAttrs(redacted), h.redactor}
+}
+
+func (h redactHandler) WithGroup(name string) slog.Handler {
+	return redactHandler{h.Handler.WithGroup(name), h.redactor}
+}
+
+func (h redactHandler) attr(a slog.Attr) slog.Attr {
+	a.Value = a.Value.Resolve()
+	switch {
+	case h.redactor.Sensitive(a.Key):
+		a.Value = slog.StringValue(redact.Mask)
+	case a.Value.Kind() == slog.KindGroup:
+		group := a.Value.Group()
+		redacted := make([]slog.Attr, len(group))
+		for i, ga := range group {
+			redacted[i] = h.attr(ga)
+		}
+		a.Value = slog.GroupValue(redacted...)
+	case a.Value.Kind() == slog.KindAny:
+		a.Value = slog.AnyValue(h.redactor.Value(a.Key, a.Value.Any()))
+	}
+	return a
+}
+
+// attrValue returns the value of a, redacted by r, for backends without
+// slog attribute support; groups become maps
+func attrValue(r *redact.Redactor, a slog.Attr) any {
+	v := a.Value.Resolve()
+	if v.Kind() != slog.KindGroup || r.Sensitive(a.Key) {
+		return r.Value(a.Key, v.Any())
+	}
+	group := make(map[string]any, len(v.Group()))
+	for _, ga := range v.Group() {
+		group[ga.Key] = attrValue(r, ga)
+	}
+	return group
+}
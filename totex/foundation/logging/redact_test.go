@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/yourusername/foundation/internal/testpb"
+	"github.com/yourusername/foundation/redact"
+)
+
+// secrets are the values logRedactable logs that must not reach the output
+var secrets = []string{"123-45-6789", "t0k3n", "abc123", "hunter2", "4321", "xyz789"}
+
+// logRedactable logs a sensitive value of every kind the loggers mask
+func logRedactable(l Logger) {
+	l.With("api_token", "xyz789").Info("Signed up",
+		"user", "ada",
+		"ssn", "123-45-6789",
+		slog.Group("request", slog.String("token", "t0k3n"), slog.String("path", "/signup")),
+		"error", errors.New("upstream rejected token=abc123"),
+		"account", testpb.Account("grace", "hunter2", 4321, nil),
+	)
+}
+
+// backends creates a logger of each kind writing format to w
+var backends = map[string]func(w io.Writer, format string, opts ...Option) Logger{
+	"slog": func(w io.Writer, format string, opts ...Option) Logger {
+		return NewSlogLoggerWithWriter("test", "info", format, w, opts...)
+	},
+	"logrus": func(w io.Writer, format string, opts ...Option) Logger {
+		return NewLogrusLogger("test", "info", format, w, opts...)
+	},
+	"zap": func(w io.Writer, format string, opts ...Option) Logger {
+		return NewZapLogger("test", "info", format, w, opts...)
+	},
+}
+
+func TestLoggersRedact(t *testing.T) {
+	// The message's password is marked foundation.v1.sensitive; the other
+	// keys are configured
+	redactor := redact.New("ssn", "token")
+	for name, newLogger := range backends {
+		for _, format := range []string{"json", "text"} {
+			t.Run(name+"/"+format, func(t *testing.T) {
+				var plain, redacted bytes.Buffer
+				logRedactable(newLogger(&plain, format, WithRedactor(nil)))
+				logRedactable(newLogger(&redacted, format, WithRedactor(redactor)))
+
+				for _, secret := range secrets {
+					if !strings.Contains(plain.String(), secret) {
+						// The backend does not print this value at all
+						continue
+					}
+					if strings.Contains(redacted.String(), secret) {
+						t.Errorf("%s logged: %s", secret, redacted.String())
+					}
+				}
+				for _, kept := range []string{"ada", "/signup", "upstream rejected", redact.Mask} {
+					if !strings.Contains(redacted.String(), kept) {
+						t.Errorf("%q missing from %s", kept, redacted.String())
+					}
+				}
+				// slog's JSON handler encodes the message without its fields
+				if !strings.Contains(plain.String(), "hunter2") && name+"/"+format != "slog/json" {
+					t.Errorf("the message is not printed, so its redaction is untested: %s", plain.String())
+				}
+			})
+		}
+	}
+}
+
+func TestDefaultRedactor(t *testing.T) {
+	// Loggers mask the default keys unless configured otherwise
+	var buf bytes.Buffer
+	NewSlogLoggerWithWriter("test", "info", "json", &buf).Info("Connecting", "db_password", "hunter2", "api_key", "abc123")
+	if out := buf.String(); strings.Contains(out, "hunter2") || strings.Contains(out, "abc123") {
+		t.Errorf("logged %s", out)
+	}
+}
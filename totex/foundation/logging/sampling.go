@@ -0,0 +1,188 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SamplingOptions configures per-message log sampling. Lines are keyed by
+// level and message: within each interval the first First lines of a key are
+// logged, then every Thereafter-th. Errors with a message not yet seen in the
+// interval are always logged.
+type SamplingOptions struct {
+	Interval   time.Duration // sampling window; 0 disables sampling
+	First      int           // lines per key logged in full each interval
+	Thereafter int           // after First, log every Thereafter-th line; 0 drops the rest
+}
+
+const (
+	// maxSampledKeys bounds the keys tracked per interval; further keys share one counter
+	maxSampledKeys = 10000
+	// summaryTopMessages is how many of the most dropped messages a summary names
+	summaryTopMessages = 10
+)
+
+// sampler decides which lines are logged and reports what it dropped at the
+// end of each interval through emit
+type sampler struct {
+	opts SamplingOptions
+	emit func(msg string, args ...any)
+
+	mu        sync.Mutex
+	windowEnd time.Time
+	counts    map[sampleKey]*sampleCount
+	dropped   int
+	summary   *time.Timer
+}
+
+type sampleKey struct {
+	level slog.Level
+	msg   string
+}
+
+type sampleCount struct {
+	seen    int
+	dropped int
+}
+
+func newSampler(opts SamplingOptions, emit func(msg string, args ...any)) *sampler {
+	return &sampler{opts: opts, emit: emit, counts: make(map[sampleKey]*sampleCount)}
+}
+
+// allow reports whether a line should be logged and counts it
+func (s *sampler) allow(level slog.Level, msg string) bool {
+	now := time.Now()
+
+	s.mu.Lock()
+	var flushed func()
+	if !now.Before(s.windowEnd) {
+		flushed = s.flush()
+		s.windowEnd = now.Add(s.opts.Interval)
+	}
+
+	key := sampleKey{level, msg}
+	c, ok := s.counts[key]
+	if !ok {
+		if len(s.counts) >= maxSampledKeys {
+			key = sampleKey{level, ""}
+		}
+		if c, ok = s.counts[key]; !ok {
+			c = &sampleCount{}
+			s.counts[key] = c
+		}
+	}
+	c.seen++
+
+	allowed := c.seen <= s.opts.First ||
+		(level >= slog.LevelError && c.seen == 1) ||
+		(s.opts.Thereafter > 0 && (c.seen-s.opts.First)%s.opts.Thereafter == 0)
+	if !allowed {
+		c.dropped++
+		s.dropped++
+		if s.summary == nil {
+			windowEnd := s.windowEnd
+			s.summary = time.AfterFunc(time.Until(windowEnd), func() { s.flushWindow(windowEnd) })
+		}
+	}
+	s.mu.Unlock()
+
+	if flushed != nil {
+		flushed()
+	}
+	return allowed
+}
+
+// flushWindow reports the drops of the interval ending at windowEnd, unless
+// a later line has already rolled the interval over
+func (s *sampler) flushWindow(windowEnd time.Time) {
+	s.mu.Lock()
+	if !s.windowEnd.Equal(windowEnd) {
+		s.mu.Unlock()
+		return
+	}
+	flushed := s.flush()
+	s.windowEnd = time.Time{}
+	s.mu.Unlock()
+
+	if flushed != nil {
+		flushed()
+	}
+}
+
+// flush resets the counters and returns a function emitting the summary of
+// dropped lines, or nil if nothing was dropped; the caller holds s.mu
+func (s *sampler) flush() func() {
+	if s.summary != nil {
+		s.summary.Stop()
+		s.summary = nil
+	}
+	dropped, counts := s.dropped, s.counts
+	s.dropped = 0
+	s.counts = make(map[sampleKey]*sampleCount)
+	if dropped == 0 {
+		return nil
+	}
+
+	type droppedMessage struct {
+		msg     string
+		dropped int
+	}
+	var top []droppedMessage
+	for key, c := range counts {
+		if c.dropped > 0 {
+			top = append(top, droppedMessage{key.msg, c.dropped})
+		}
+	}
+	sort.Slice(top, func(i, j int) bool { return top[i].dropped > top[j].dropped })
+	if len(top) > summaryTopMessages {
+		top = top[:summaryTopMessages]
+	}
+	messages := make(map[string]int, len(top))
+	for _, m := range top {
+		messages[m.msg] += m.dropped
+	}
+
+	interval := s.opts.Interval
+	return func() {
+		s.emit("Dropped sampled log lines", "dropped", dropped, "interval", interval, "messages", messages)
+	}
+}
+
+// samplingHandler drops lines rejected by its sampler
+type samplingHandler struct {
+	slog.Handler
+	sampler *sampler
+}
+
+// NewSamplingHandler wraps h so repeated lines are sampled according to opts.
+// A summary of dropped lines is written to h at the end of each interval in
+// which lines were dropped.
+func NewSamplingHandler(h slog.Handler, opts SamplingOptions) slog.Handler {
+	if opts.Interval <= 0 {
+		return h
+	}
+	s := newSampler(opts, func(msg string, args ...any) {
+		r := slog.NewRecord(time.Now(), slog.LevelWarn, msg, 0)
+		r.Add(args...)
+		h.Handle(context.Background(), r)
+	})
+	return samplingHandler{h, s}
+}
+
+func (h samplingHandler) Handle(ctx context.Context, r slog.Record) error {
+	if !h.sampler.allow(r.Level, r.Message) {
+		return nil
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return samplingHandler{h.Handler.WithAttrs(attrs), h.sampler}
+}
+
+func (h samplingHandler) WithGroup(name string) slog.Handler {
+	return samplingHandler{h.Handler.WithGroup(name), h.sampler}
+}
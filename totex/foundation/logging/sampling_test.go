@@ -0,0 +1,202 @@
+package logging
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// summary is a dropped-lines summary emitted by a sampler
+type summary struct {
+	dropped  int
+	messages map[string]int
+}
+
+// testSampler returns a sampler whose summaries are sent to the returned channel
+func testSampler(opts SamplingOptions) (*sampler, chan summary) {
+	summaries := make(chan summary, 10)
+	s := newSampler(opts, func(msg string, args ...any) {
+		var sum summary
+		for i := 0; i+1 < len(args); i += 2 {
+			switch args[i] {
+			case "dropped":
+				sum.dropped = args[i+1].(int)
+			case "messages":
+				sum.messages = args[i+1].(map[string]int)
+			}
+		}
+		summaries <- sum
+	})
+	return s, summaries
+}
+
+// allowed returns the 1-based indexes of n lines that s lets through
+func allowed(s *sampler, n int, level slog.Level, msg string) []int {
+	var got []int
+	for i := 1; i <= n; i++ {
+		if s.allow(level, msg) {
+			got = append(got, i)
+		}
+	}
+	return got
+}
+
+// rollOver ends the sampler's current interval, so its next line flushes it
+func rollOver(s *sampler) {
+	s.mu.Lock()
+	s.windowEnd = time.Now()
+	s.mu.Unlock()
+}
+
+func TestSamplerFirstThenEvery(t *testing.T) {
+	tests := []struct {
+		opts SamplingOptions
+		want string
+	}{
+		{SamplingOptions{Interval: time.Hour, First: 3, Thereafter: 5}, "[1 2 3 8 13 18]"},
+		{SamplingOptions{Interval: time.Hour, First: 2}, "[1 2]"},
+		{SamplingOptions{Interval: time.Hour, Thereafter: 10}, "[10 20]"},
+	}
+	for _, tt := range tests {
+		s, _ := testSampler(tt.opts)
+		if got := fmt.Sprint(allowed(s, 20, slog.LevelInfo, "Polled")); got != tt.want {
+			t.Errorf("%+v let through %s, want %s", tt.opts, got, tt.want)
+		}
+		// Each level and message is sampled on its own
+		if got := allowed(s, 1, slog.LevelWarn, "Polled"); len(got) != 1 && tt.opts.First > 0 {
+			t.Errorf("%+v dropped the first warning of a sampled info message", tt.opts)
+		}
+	}
+}
+
+func TestSamplerErrors(t *testing.T) {
+	s, _ := testSampler(SamplingOptions{Interval: time.Hour})
+	if got := allowed(s, 3, slog.LevelInfo, "Polled"); len(got) != 0 {
+		t.Errorf("info lines %v logged with First 0", got)
+	}
+	for _, msg := range []string{"Query failed", "Publish failed"} {
+		if got := fmt.Sprint(allowed(s, 3, slog.LevelError, msg)); got != "[1]" {
+			t.Errorf("%q let through %s, want only the first", msg, got)
+		}
+	}
+	// A new interval lets the error through again
+	rollOver(s)
+	if !s.allow(slog.LevelError, "Query failed") {
+		t.Error("a known error was dropped in a new interval")
+	}
+}
+
+func TestSamplerSummary(t *testing.T) {
+	s, summaries := testSampler(SamplingOptions{Interval: time.Hour, First: 1})
+	allowed(s, 5, slog.LevelInfo, "Polled")
+	allowed(s, 3, slog.LevelWarn, "Slow query")
+	allowed(s, 1, slog.LevelInfo, "Started")
+
+	rollOver(s)
+	if !s.allow(slog.LevelInfo, "Polled") {
+		t.Error("the first line of a new interval was dropped")
+	}
+	sum := <-summaries
+	if sum.dropped != 6 || sum.messages["Polled"] != 4 || sum.messages["Slow query"] != 2 || len(sum.messages) != 2 {
+		t.Errorf("summary = %+v, want 6 dropped: 4 Polled and 2 Slow query", sum)
+	}
+
+	// An interval without drops has no summary
+	rollOver(s)
+	s.allow(slog.LevelInfo, "Polled")
+	select {
+	case sum := <-summaries:
+		t.Errorf("summary %+v of an interval without drops", sum)
+	default:
+	}
+}
+
+func TestSamplerSummaryTimer(t *testing.T) {
+	// Drops are reported when the interval ends even if nothing else is logged
+	s, summaries := testSampler(SamplingOptions{Interval: 20 * time.Millisecond, First: 1})
+	allowed(s, 3, slog.LevelInfo, "Polled")
+	select {
+	case sum := <-summaries:
+		if sum.dropped != 2 {
+			t.Errorf("summary = %+v, want 2 dropped", sum)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no summary at the end of the interval")
+	}
+}
+
+func TestSamplerKeyLimit(t *testing.T) {
+	s, summaries := testSampler(SamplingOptions{Interval: time.Hour, First: 1})
+	for i := range maxSampledKeys {
+		s.allow(slog.LevelInfo, fmt.Sprintf("Message %d", i))
+	}
+	// Further messages share one counter
+	if got := fmt.Sprint(allowed(s, 1, slog.LevelInfo, "One"), allowed(s, 1, slog.LevelInfo, "Two")); got != "[1] []" {
+		t.Errorf("messages past the key limit let through %s", got)
+	}
+	if len(s.counts) != maxSampledKeys+1 {
+		t.Errorf("tracking %d keys", len(s.counts))
+	}
+	rollOver(s)
+	s.allow(slog.LevelInfo, "Polled")
+	if sum := <-summaries; sum.dropped != 1 || sum.messages[""] != 1 {
+		t.Errorf("summary = %+v", sum)
+	}
+}
+
+// syncBuffer is a bytes.Buffer safe for the sampler's summary goroutine
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// droppedThree matches a count of 3 dropped lines in each backend's text format
+var droppedThree = regexp.MustCompile(`dropped"?[=:] ?3\b`)
+
+func TestLoggersSample(t *testing.T) {
+	for name, newLogger := range backends {
+		t.Run(name, func(t *testing.T) {
+			var out syncBuffer
+			l := newLogger(&out, "text", WithSampling(SamplingOptions{Interval: 50 * time.Millisecond, First: 2}))
+			for range 5 {
+				l.Info("Polled", "queue", "emails")
+			}
+			l.Error("Poll failed")
+			l.Error("Poll failed")
+			if n := strings.Count(out.String(), "Polled"); n != 2 {
+				t.Errorf("logged %d of 5 lines, want 2:\n%s", n, out.String())
+			}
+			if n := strings.Count(out.String(), "Poll failed"); n != 2 {
+				t.Errorf("logged %d of 2 errors, want both:\n%s", n, out.String())
+			}
+
+			deadline := time.Now().Add(5 * time.Second)
+			for !strings.Contains(out.String(), "Dropped sampled log lines") {
+				if time.Now().After(deadline) {
+					t.Fatalf("no summary of the dropped lines:\n%s", out.String())
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			if !droppedThree.MatchString(out.String()) {
+				t.Errorf("summary does not count 3 dropped lines:\n%s", out.String())
+			}
+		})
+	}
+}
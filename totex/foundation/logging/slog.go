@@ -1,61 +1,141 @@
 package logging
 
 import (
+	"context"
+	"io"
 	"log/slog"
 	"os"
+
+	"github.com/yourusername/foundation/redact"
 )
 
 // SlogLogger implements Logger using slog
 type SlogLogger struct {
-	name string
-	slog *slog.Logger
+	name   string
+	slog   *slog.Logger
+	levels *Levels
+}
+
+// NewSlogLogger creates a new slog-based logger writing to output: "stdout",
+// "stderr" or a file path. If the file cannot be opened it logs to stderr.
+func NewSlogLogger(name string, level, format, output string, opts ...Option) Logger {
+	w, err := OpenOutput(OutputOptions{Path: output})
+	if err != nil {
+		logger := NewSlogLoggerWithWriter(name, level, format, os.Stderr, opts...)
+		logger.Error("Failed to open log output, using stderr", "output", output, "error", err)
+		return logger
+	}
+	return NewSlogLoggerWithWriter(name, level, format, w, opts...)
 }
 
-// NewSlogLogger creates a new slog-based logger
-func NewSlogLogger(name string, level, format, output string) Logger {
+// NewSlogLoggerWithWriter creates a new slog-based logger writing to w
+func NewSlogLoggerWithWriter(name string, level, format string, w io.Writer, opts ...Option) Logger {
 	var h slog.Handler
 
-	// Set log level
-	levelOpt := &slog.LevelVar{}
-	switch level {
-	case "debug":
-		levelOpt.Set(slog.LevelDebug)
-	case "warn":
-		levelOpt.Set(slog.LevelWarn)
-	case "error":
-		levelOpt.Set(slog.LevelError)
-	default:
-		levelOpt.Set(slog.LevelInfo)
-	}
+	// Levels are filtered per logger by levelHandler, so the base handler lets everything through
+	handlerOpts := &slog.HandlerOptions{Level: slog.LevelDebug}
 
 	// Set format
 	if format == "json" {
-		h = slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
-			Level: levelOpt,
-		})
+		h = slog.NewJSONHandler(w, handlerOpts)
 	} else {
-		h = slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{
-			Level: levelOpt,
-		})
+		h = slog.NewTextHandler(w, handlerOpts)
 	}
+	return NewSlogLoggerWithHandler(name, level, h, opts...)
+}
 
+// NewSlogLoggerWithHandler creates a new slog-based logger on top of h. h
+// receives the lines at or above each logger's level, after sampling and
+// redaction, with the context's trace and request IDs added.
+func NewSlogLoggerWithHandler(name string, level string, h slog.Handler, opts ...Option) Logger {
+	o := newOptions(opts)
+	levels := NewLevels(parseLevel(level))
 	return &SlogLogger{
-		name: name,
-		slog: slog.New(h),
+		name:   name,
+		slog:   slog.New(levelHandler{contextHandler{NewSamplingHandler(newRedactHandler(h, o.redactor), o.sampling), o.baggageKeys}, levels.Root(), ""}),
+		levels: levels,
+	}
+}
+
+// parseLevel maps a configured level name to a slog level, defaulting to info
+func parseLevel(level string) slog.Level {
+	if l, ok := ParseLevel(level); ok {
+		return l
 	}
+	return slog.LevelInfo
 }
 
 // NewDefaultSlogLogger creates a default slog logger
 func NewDefaultSlogLogger() Logger {
+	levels := NewLevels(slog.LevelInfo)
 	return &SlogLogger{
-		name: "default-logger",
-		slog: slog.Default(),
+		name:   "default-logger",
+		slog:   slog.New(levelHandler{contextHandler{newRedactHandler(slog.Default().Handler(), redact.Default()), nil}, levels.Root(), ""}),
+		levels: levels,
+	}
+}
+
+// levelHandler filters records by the level of the logger it belongs to and
+// adds the logger's name, if it is a named logger
+type levelHandler struct {
+	slog.Handler
+	level slog.Leveler
+	name  string
+}
+
+func (h levelHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return level >= h.level.Level() && h.Handler.Enabled(ctx, level)
+}
+
+func (h levelHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.name != "" {
+		r = r.Clone()
+		r.AddAttrs(slog.String("logger", h.name))
 	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h levelHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return levelHandler{h.Handler.WithAttrs(attrs), h.level, h.name}
+}
+
+func (h levelHandler) WithGroup(name string) slog.Handler {
+	return levelHandler{h.Handler.WithGroup(name), h.level, h.name}
 }
 
 func (l *SlogLogger) Debug(msg string, args ...any) { l.slog.Debug(msg, args...) }
 func (l *SlogLogger) Info(msg string, args ...any)  { l.slog.Info(msg, args...) }
 func (l *SlogLogger) Warn(msg string, args ...any)  { l.slog.Warn(msg, args...) }
 func (l *SlogLogger) Error(msg string, args ...any) { l.slog.Error(msg, args...) }
-func (l *SlogLogger) With(args ...any) Logger       { return l }
 func (l *SlogLogger) Name() string                  { return l.name }
+
+func (l *SlogLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.slog.DebugContext(ctx, msg, args...)
+}
+func (l *SlogLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.slog.InfoContext(ctx, msg, args...)
+}
+func (l *SlogLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.slog.WarnContext(ctx, msg, args...)
+}
+func (l *SlogLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.slog.ErrorContext(ctx, msg, args...)
+}
+
+// With returns a child logger that adds args to every line
+func (l *SlogLogger) With(args ...any) Logger {
+	return &SlogLogger{name: l.name, slog: l.slog.With(args...), levels: l.levels}
+}
+
+// Named returns a child logger whose level can be changed on its own through Levels
+func (l *SlogLogger) Named(name string) Logger {
+	name = childName(l.name, l.levels, name)
+	h := l.slog.Handler()
+	if lh, ok := h.(levelHandler); ok {
+		h = levelHandler{lh.Handler, l.levels.Leveler(name), name}
+	}
+	return &SlogLogger{name: name, slog: slog.New(h), levels: l.levels}
+}
+
+// Levels returns the registry controlling this logger's level and its named children's
+func (l *SlogLogger) Levels() *Levels { return l.levels }
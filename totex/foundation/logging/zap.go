@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/yourusername/foundation/redact"
+)
+
+// ZapLogger implements the Logger interface using zap
+type ZapLogger struct {
+	sugar       *zap.SugaredLogger
+	name        string
+	levels      *Levels
+	redactor    *redact.Redactor
+	baggageKeys []string
+}
+
+// NewZapLogger creates a new zap-based logger writing to w
+func NewZapLogger(name string, level, format string, w io.Writer, opts ...Option) Logger {
+	encoderCfg := zap.NewProductionEncoderConfig()
+	encoderCfg.EncodeTime = zapcore.ISO8601TimeEncoder
+
+	var encoder zapcore.Encoder
+	if format == "json" {
+		encoder = zapcore.NewJSONEncoder(encoderCfg)
+	} else {
+		encoder = zapcore.NewConsoleEncoder(encoderCfg)
+	}
+
+	// Levels are filtered per logger by levelCore, so the base core lets everything through
+	o := newOptions(opts)
+	levels := NewLevels(parseLevel(level))
+	var core zapcore.Core = zapcore.NewCore(encoder, zapcore.AddSync(w), zapcore.DebugLevel)
+	if o.sampling.Interval > 0 {
+		summary := zap.New(core).Sugar()
+		core = samplingCore{core, newSampler(o.sampling, summary.Warnw)}
+	}
+	return &ZapLogger{
+		sugar:       zap.New(levelCore{core, levels.Root()}).Sugar(),
+		name:        name,
+		levels:      levels,
+		redactor:    o.redactor,
+		baggageKeys: o.baggageKeys,
+	}
+}
+
+// samplingCore drops entries rejected by its sampler; it sits below
+// levelCore, so only enabled entries are counted
+type samplingCore struct {
+	zapcore.Core
+	sampler *sampler
+}
+
+func (c samplingCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if !c.sampler.allow(fromZapLevel(ent.Level), ent.Message) {
+		return nil
+	}
+	return c.Core.Write(ent, fields)
+}
+
+func (c samplingCore) With(fields []zapcore.Field) zapcore.Core {
+	return samplingCore{c.Core.With(fields), c.sampler}
+}
+
+// levelCore filters entries by the level of the logger it belongs to
+type levelCore struct {
+	zapcore.Core
+	level slog.Leveler
+}
+
+func (c levelCore) Enabled(level zapcore.Level) bool {
+	return level >= zapLevel(c.level.Level()) && c.Core.Enabled(level)
+}
+
+func (c levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{c.Core.With(fields), c.level}
+}
+
+// zapLevel maps a slog level to the matching zap level
+func zapLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level <= slog.LevelDebug:
+		return zapcore.DebugLevel
+	case level <= slog.LevelInfo:
+		return zapcore.InfoLevel
+	case level <= slog.LevelWarn:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// fromZapLevel maps a zap level to the matching slog level
+func fromZapLevel(level zapcore.Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func (l *ZapLogger) Debug(msg string, args ...any) { l.sugar.Debugw(msg, l.args(args)...) }
+func (l *ZapLogger) Info(msg string, args ...any)  { l.sugar.Infow(msg, l.args(args)...) }
+func (l *ZapLogger) Warn(msg string, args ...any)  { l.sugar.Warnw(msg, l.args(args)...) }
+func (l *ZapLogger) Error(msg string, args ...any) { l.sugar.Errorw(msg, l.args(args)...) }
+func (l *ZapLogger) Name() string                  { return l.name }
+
+func (l *ZapLogger) DebugContext(ctx context.Context, msg string, args ...any) {
+	l.sugar.Debugw(msg, l.contextArgs(ctx, args)...)
+}
+func (l *ZapLogger) InfoContext(ctx context.Context, msg string, args ...any) {
+	l.sugar.Infow(msg, l.contextArgs(ctx, args)...)
+}
+func (l *ZapLogger) WarnContext(ctx context.Context, msg string, args ...any) {
+	l.sugar.Warnw(msg, l.contextArgs(ctx, args)...)
+}
+func (l *ZapLogger) ErrorContext(ctx context.Context, msg string, args ...any) {
+	l.sugar.Errorw(msg, l.contextArgs(ctx, args)...)
+}
+
+// With returns a child logger that adds args to every line
+func (l *ZapLogger) With(args ...any) Logger {
+	return &ZapLogger{sugar: l.sugar.With(l.args(args)...), name: l.name, levels: l.levels, redactor: l.redactor, baggageKeys: l.baggageKeys}
+}
+
+// Named returns a child logger whose level can be changed on its own through Levels
+func (l *ZapLogger) Named(name string) Logger {
+	// zap joins nested names with dots, like the Levels hierarchy
+	sugar := l.sugar.Named(name)
+	name = childName(l.name, l.levels, name)
+	level := l.levels.Leveler(name)
+	sugar = sugar.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+		if lc, ok := core.(levelCore); ok {
+			core = lc.Core
+		}
+		return levelCore{core, level}
+	}))
+	return &ZapLogger{sugar: sugar, name: name, levels: l.levels, redactor: l.redactor, baggageKeys: l.baggageKeys}
+}
+
+// Levels returns the registry controlling this logger's level and its named children's
+func (l *ZapLogger) Levels() *Levels { return l.levels }
+
+// args converts slog.Attrs to zap fields and masks sensitive values; plain
+// key/value pairs are understood by the sugared logger as they are
+func (l *ZapLogger) args(args []any) []any {
+	out := make([]any, len(args))
+	for i := 0; i < len(args); i++ {
+		switch arg := args[i].(type) {
+		case slog.Attr:
+			out[i] = zap.Any(arg.Key, attrValue(l.redactor, arg))
+		case zapcore.Field:
+			if l.redactor.Sensitive(arg.Key) {
+				arg = zap.String(arg.Key, redact.Mask)
+			}
+			out[i] = arg
+		case string:
+			out[i] = arg
+			if i+1 < len(args) {
+				out[i+1] = l.redactor.Value(arg, args[i+1])
+				i++
+			}
+		default:
+			out[i] = arg
+		}
+	}
+	return out
+}
+
+func (l *ZapLogger) contextArgs(ctx context.Context, args []any) []any {
+	args = l.args(args)
+	for _, attr := range contextAttrs(ctx, l.baggageKeys) {
+		args = append(args, zap.Any(attr.Key, l.redactor.Value(attr.Key, attr.Value.Any())))
+	}
+	return args
+}
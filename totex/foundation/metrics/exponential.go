@@ -0,0 +1,153 @@
+package metrics
+
+import "math"
+
+const (
+	// maxExponentialBuckets bounds the buckets per sign of an exponential histogram
+	maxExponentialBuckets = 160
+	// maxExponentialScale is the initial and finest scale of an exponential histogram
+	maxExponentialScale = 20
+)
+
+// ExponentialHistogram is a snapshot of a base-2 exponential histogram as
+// defined by OpenTelemetry. Bucket i of a sign covers (base^i, base^(i+1)]
+// with base = 2^(2^-Scale), starting at index Offset.
+type ExponentialHistogram struct {
+	Scale          int32
+	ZeroCount      uint64
+	PositiveOffset int32
+	Positive       []uint64
+	NegativeOffset int32
+	Negative       []uint64
+}
+
+// expoHistogram aggregates observations into exponential buckets, lowering
+// the scale whenever a sign would need more than maxExponentialBuckets
+type expoHistogram struct {
+	scale     int32
+	zeroCount uint64
+	pos, neg  expoBuckets
+}
+
+type expoBuckets struct {
+	offset int32
+	counts []uint64
+}
+
+func newExpoHistogram() *expoHistogram {
+	return &expoHistogram{scale: maxExponentialScale}
+}
+
+func (h *expoHistogram) observe(v float64) {
+	if v == 0 || math.IsNaN(v) || math.IsInf(v, 0) {
+		if v == 0 {
+			h.zeroCount++
+		}
+		return
+	}
+	b := &h.pos
+	if v < 0 {
+		b, v = &h.neg, -v
+	}
+	idx := expoIndex(v, h.scale)
+	if change := b.downscaleFor(idx); change > 0 {
+		h.pos.downscale(change)
+		h.neg.downscale(change)
+		h.scale -= change
+		idx = expoIndex(v, h.scale)
+	}
+	b.increment(idx)
+}
+
+func (h *expoHistogram) snapshot() *ExponentialHistogram {
+	return &ExponentialHistogram{
+		Scale:          h.scale,
+		ZeroCount:      h.zeroCount,
+		PositiveOffset: h.pos.offset,
+		Positive:       append([]uint64(nil), h.pos.counts...),
+		NegativeOffset: h.neg.offset,
+		Negative:       append([]uint64(nil), h.neg.counts...),
+	}
+}
+
+// expoIndex returns the bucket index of v > 0 at scale
+func expoIndex(v float64, scale int32) int32 {
+	if scale > 0 {
+		return int32(math.Ceil(math.Log2(v)*math.Ldexp(1, int(scale)))) - 1
+	}
+	// Log2 is exact for powers of two, so they stay in the lower bucket
+	return (int32(math.Ceil(math.Log2(v))) - 1) >> -scale
+}
+
+// downscaleFor returns how much the scale must drop for idx to fit
+func (b *expoBuckets) downscaleFor(idx int32) int32 {
+	if len(b.counts) == 0 {
+		return 0
+	}
+	lo, hi := b.offset, b.offset+int32(len(b.counts))-1
+	lo, hi = min(lo, idx), max(hi, idx)
+	var change int32
+	for hi-lo+1 > maxExponentialBuckets {
+		lo >>= 1
+		hi >>= 1
+		change++
+	}
+	return change
+}
+
+func (b *expoBuckets) increment(idx int32) {
+	switch {
+	case len(b.counts) == 0:
+		b.offset = idx
+		b.counts = []uint64{0}
+	case idx < b.offset:
+		grown := make([]uint64, int(b.offset-idx)+len(b.counts))
+		copy(grown[b.offset-idx:], b.counts)
+		b.counts, b.offset = grown, idx
+	case int(idx-b.offset) >= len(b.counts):
+		b.counts = append(b.counts, make([]uint64, int(idx-b.offset)+1-len(b.counts))...)
+	}
+	b.counts[idx-b.offset]++
+}
+
+// downscale merges buckets for a scale change lower
+func (b *expoBuckets) downscale(change int32) {
+	if len(b.counts) == 0 || change == 0 {
+		return
+	}
+	offset := b.offset >> change
+	last := (b.offset + int32(len(b.counts)) - 1) >> change
+	counts := make([]uint64, last-offset+1)
+	for i, n := range b.counts {
+		counts[((b.offset+int32(i))>>change)-offset] += n
+	}
+	b.offset, b.counts = offset, counts
+}
+
+// Sub returns the observations in h that are not in prev, an earlier
+// snapshot of the same histogram, at h's scale
+func (h *ExponentialHistogram) Sub(prev *ExponentialHistogram) *ExponentialHistogram {
+	if prev == nil {
+		return h
+	}
+	change := prev.Scale - h.Scale
+	pos := expoBuckets{prev.PositiveOffset, append([]uint64(nil), prev.Positive...)}
+	neg := expoBuckets{prev.NegativeOffset, append([]uint64(nil), prev.Negative...)}
+	pos.downscale(change)
+	neg.downscale(change)
+
+	out := &ExponentialHistogram{Scale: h.Scale, ZeroCount: h.ZeroCount - prev.ZeroCount}
+	out.PositiveOffset, out.Positive = subBuckets(h.PositiveOffset, h.Positive, pos)
+	out.NegativeOffset, out.Negative = subBuckets(h.NegativeOffset, h.Negative, neg)
+	return out
+}
+
+func subBuckets(offset int32, counts []uint64, prev expoBuckets) (int32, []uint64) {
+	out := append([]uint64(nil), counts...)
+	for i, n := range prev.counts {
+		if j := prev.offset + int32(i) - offset; j >= 0 && int(j) < len(out) {
+			out[j] -= n
+		}
+	}
+	return offset, out
+}
@@ -0,0 +1,158 @@
+package metrics
+
+import (
+	"context"
+	"math"
+)
+
+// Counter is a registered counter family. Label values are bound with With;
+// it panics unless given one value per label name.
+type Counter struct{ f *family }
+
+// CounterSeries is a counter bound to label values
+type CounterSeries struct{ s *series }
+
+// Gauge is a registered gauge family
+type Gauge struct{ f *family }
+
+// GaugeSeries is a gauge bound to label values
+type GaugeSeries struct{ s *series }
+
+// Histogram is a registered histogram family
+type Histogram struct{ f *family }
+
+// HistogramSeries is a histogram bound to label values
+type HistogramSeries struct {
+	s      *series
+	bounds []float64
+}
+
+// Summary is a registered summary family. Quantiles are computed over the
+// most recent 1024 observations of each series.
+type Summary struct{ f *family }
+
+// SummarySeries is a summary bound to label values
+type SummarySeries struct{ s *series }
+
+// NewCounter registers a counter with the given label names. Registering
+// the same counter again returns it; NewCounter panics if the name or
+// labels are invalid or the name is registered with a different shape.
+func (r *Registry) NewCounter(name, help string, labelNames ...string) *Counter {
+	return &Counter{r.mustRegister(Desc{Kind: KindCounter, Name: name, Help: help, LabelNames: labelNames})}
+}
+
+// NewGauge registers a gauge with the given label names, panicking like NewCounter
+func (r *Registry) NewGauge(name, help string, labelNames ...string) *Gauge {
+	return &Gauge{r.mustRegister(Desc{Kind: KindGauge, Name: name, Help: help, LabelNames: labelNames})}
+}
+
+// NewHistogram registers a histogram with the given bucket upper bounds,
+// which must be strictly increasing; nil buckets use DefBuckets. The +Inf
+// bucket is implicit. It panics like NewCounter, and if "le" is a label name.
+func (r *Registry) NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return &Histogram{r.mustRegister(Desc{Kind: KindHistogram, Name: name, Help: help, LabelNames: labelNames, Buckets: buckets})}
+}
+
+// NewSummary registers a summary reporting the given quantiles, each between
+// 0 and 1; nil quantiles use DefQuantiles. It panics like NewCounter, and if
+// "quantile" is a label name.
+func (r *Registry) NewSummary(name, help string, quantiles []float64, labelNames ...string) *Summary {
+	return &Summary{r.mustRegister(Desc{Kind: KindSummary, Name: name, Help: help, LabelNames: labelNames, Quantiles: quantiles})}
+}
+
+// NewCounter registers a counter in DefaultRegistry
+func NewCounter(name, help string, labelNames ...string) *Counter {
+	return DefaultRegistry.NewCounter(name, help, labelNames...)
+}
+
+// NewGauge registers a gauge in DefaultRegistry
+func NewGauge(name, help string, labelNames ...string) *Gauge {
+	return DefaultRegistry.NewGauge(name, help, labelNames...)
+}
+
+// NewHistogram registers a histogram in DefaultRegistry
+func NewHistogram(name, help string, buckets []float64, labelNames ...string) *Histogram {
+	return DefaultRegistry.NewHistogram(name, help, buckets, labelNames...)
+}
+
+// NewSummary registers a summary in DefaultRegistry
+func NewSummary(name, help string, quantiles []float64, labelNames ...string) *Summary {
+	return DefaultRegistry.NewSummary(name, help, quantiles, labelNames...)
+}
+
+// LinearBuckets returns count buckets, width apart, starting at start
+func LinearBuckets(start, width float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start + float64(i)*width
+	}
+	return buckets
+}
+
+// ExponentialBuckets returns count buckets, each factor times the previous,
+// starting at start
+func ExponentialBuckets(start, factor float64, count int) []float64 {
+	buckets := make([]float64, count)
+	for i := range buckets {
+		buckets[i] = start * math.Pow(factor, float64(i))
+	}
+	return buckets
+}
+
+// With returns the series for labelValues, in label name order
+func (c *Counter) With(labelValues ...string) CounterSeries {
+	c.f.checkValues(labelValues)
+	return CounterSeries{c.f.series(labelValues)}
+}
+
+// Inc adds 1 to the counter
+func (c CounterSeries) Inc() { c.s.add(1) }
+
+// Add adds v to the counter; it panics if v is negative
+func (c CounterSeries) Add(v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.s.add(v)
+}
+
+// AddContext adds v to the counter with the trace of the span in ctx as exemplar
+func (c CounterSeries) AddContext(ctx context.Context, v float64) {
+	if v < 0 {
+		panic("metrics: counter cannot decrease")
+	}
+	c.s.addExemplar(v, exemplarFrom(ctx, v))
+}
+
+// With returns the series for labelValues, in label name order
+func (g *Gauge) With(labelValues ...string) GaugeSeries {
+	g.f.checkValues(labelValues)
+	return GaugeSeries{g.f.series(labelValues)}
+}
+
+func (g GaugeSeries) Set(v float64) { g.s.set(v) }
+func (g GaugeSeries) Add(v float64) { g.s.add(v) }
+func (g GaugeSeries) Sub(v float64) { g.s.add(-v) }
+func (g GaugeSeries) Inc()          { g.s.add(1) }
+func (g GaugeSeries) Dec()          { g.s.add(-1) }
+
+// With returns the series for labelValues, in label name order
+func (h *Histogram) With(labelValues ...string) HistogramSeries {
+	h.f.checkValues(labelValues)
+	return HistogramSeries{h.f.series(labelValues), h.f.desc.Buckets}
+}
+
+func (h HistogramSeries) Observe(v float64) { h.s.observe(v, nil, h.bounds...) }
+
+// ObserveContext records v with the trace of the span in ctx as exemplar
+func (h HistogramSeries) ObserveContext(ctx context.Context, v float64) {
+	h.s.observe(v, exemplarFrom(ctx, v), h.bounds...)
+}
+
+// With returns the series for labelValues, in label name order
+func (s *Summary) With(labelValues ...string) SummarySeries {
+	s.f.checkValues(labelValues)
+	return SummarySeries{s.f.series(labelValues)}
+}
+
+func (s SummarySeries) Observe(v float64) { s.s.observe(v, nil) }
@@ -1,11 +1,17 @@
 package metrics
 
-// Metrics interface for observability
+import "context"
+
+// Metrics interface for observability. The Context variants attach the
+// trace of the span in ctx, if any, as an exemplar; exemplars are only
+// defined for counters and histograms.
 type Metrics interface {
 	Counter(name string, value float64, labels ...string)
 	Gauge(name string, value float64, labels ...string)
 	Histogram(name string, value float64, labels ...string)
 	Summary(name string, value float64, labels ...string)
+	CounterContext(ctx context.Context, name string, value float64, labels ...string)
+	HistogramContext(ctx context.Context, name string, value float64, labels ...string)
 	Name() string
 }
 
@@ -29,3 +35,9 @@ func (m *NoopMetrics) Gauge(name string, value float64, labels ...string)     {}
 func (m *NoopMetrics) Histogram(name string, value float64, labels ...string) {}
 func (m *NoopMetrics) Summary(name string, value float64, labels ...string)   {}
 func (m *NoopMetrics) Name() string                                           { return m.name }
+
+func (m *NoopMetrics) CounterContext(ctx context.Context, name string, value float64, labels ...string) {
+}
+
+func (m *NoopMetrics) HistogramContext(ctx context.Context, name string, value float64, labels ...string) {
+}
@@ -0,0 +1,501 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OTLPProtocol is the transport of the OTLP exporter
+type OTLPProtocol string
+
+const (
+	OTLPHTTP OTLPProtocol = "http/protobuf"
+	OTLPGRPC OTLPProtocol = "grpc"
+)
+
+// Temporality is how the OTLP exporter reports counters and histograms
+type Temporality int
+
+const (
+	// Cumulative reports totals since each series was created
+	Cumulative Temporality = iota
+	// Delta reports the change since the previous successful export
+	Delta
+)
+
+const (
+	otlpScope        = "github.com/yourusername/foundation/metrics"
+	otlpGRPCPath     = "/opentelemetry.proto.collector.metrics.v1.MetricsService/Export"
+	otlpHTTPPath     = "/v1/metrics"
+	otlpErrorBodyMax = 512
+)
+
+// AggregationTemporality values of the OTLP protocol
+const (
+	otlpDelta      = 1
+	otlpCumulative = 2
+)
+
+// OTLPOption configures an OTLPExporter
+type OTLPOption func(*otlpOptions)
+
+type otlpOptions struct {
+	protocol    OTLPProtocol
+	interval    time.Duration
+	timeout     time.Duration
+	temporality Temporality
+	exponential bool
+	headers     map[string]string
+	resource    map[string]string
+	onError     func(error)
+}
+
+// WithOTLPProtocol selects OTLP/HTTP with protobuf payloads (the default) or OTLP/gRPC
+func WithOTLPProtocol(p OTLPProtocol) OTLPOption {
+	return func(o *otlpOptions) {
+		o.protocol = p
+	}
+}
+
+// WithOTLPInterval sets how often the exporter pushes; the default, also used
+// for d <= 0, is one minute
+func WithOTLPInterval(d time.Duration) OTLPOption {
+	return func(o *otlpOptions) {
+		o.interval = d
+	}
+}
+
+// WithOTLPTimeout bounds each push; the default, also used for d <= 0, is ten
+// seconds
+func WithOTLPTimeout(d time.Duration) OTLPOption {
+	return func(o *otlpOptions) {
+		o.timeout = d
+	}
+}
+
+// WithTemporality selects cumulative (the default) or delta temporality for
+// counters and histograms
+func WithTemporality(t Temporality) OTLPOption {
+	return func(o *otlpOptions) {
+		o.temporality = t
+	}
+}
+
+// WithExponentialHistograms exports histograms as base-2 exponential
+// histograms instead of with their explicit buckets. It enables exponential
+// histograms on the exporter's registry, so create the exporter before
+// observing; a family with series observed earlier keeps its explicit buckets.
+func WithExponentialHistograms() OTLPOption {
+	return func(o *otlpOptions) {
+		o.exponential = true
+	}
+}
+
+// WithOTLPHeaders adds headers to every push, e.g. for authentication
+func WithOTLPHeaders(headers map[string]string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.headers = headers
+	}
+}
+
+// WithResource sets the resource attributes, such as service.name
+func WithResource(attrs map[string]string) OTLPOption {
+	return func(o *otlpOptions) {
+		o.resource = attrs
+	}
+}
+
+// WithOTLPErrorHandler sets the function called when a periodic push fails
+func WithOTLPErrorHandler(fn func(error)) OTLPOption {
+	return func(o *otlpOptions) {
+		o.onError = fn
+	}
+}
+
+// OTLPExporter pushes the families of a registry to an OpenTelemetry
+// collector on an interval. It is an App component: OnStart starts pushing
+// and OnStop makes a final push.
+type OTLPExporter struct {
+	registry *Registry
+	opts     otlpOptions
+	url      string
+	client   *http.Client
+
+	mu   sync.Mutex // serialises pushes and guards prev
+	prev map[string]otlpPoint
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// otlpPoint is the state of a series at the last successful delta push
+type otlpPoint struct {
+	time    time.Time
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+	expo    *ExponentialHistogram
+}
+
+// ParseTemporality parses "cumulative" or "delta"
+func ParseTemporality(s string) (Temporality, error) {
+	switch strings.ToLower(s) {
+	case "", "cumulative":
+		return Cumulative, nil
+	case "delta":
+		return Delta, nil
+	default:
+		return Cumulative, fmt.Errorf("unknown temporality %q", s)
+	}
+}
+
+// NewOTLPExporter creates an exporter pushing r to endpoint. For OTLP/HTTP
+// an endpoint without a path gets /v1/metrics; an endpoint without a scheme
+// uses plain http, which for OTLP/gRPC means HTTP/2 without TLS.
+func NewOTLPExporter(r *Registry, endpoint string, opts ...OTLPOption) *OTLPExporter {
+	o := otlpOptions{protocol: OTLPHTTP, interval: time.Minute, timeout: 10 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		o.interval = time.Minute
+	}
+	if o.timeout <= 0 {
+		o.timeout = 10 * time.Second
+	}
+	if o.onError == nil {
+		o.onError = func(error) {}
+	}
+	if o.exponential {
+		r.EnableExponentialHistograms()
+	}
+
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		u = &url.URL{Scheme: "http", Host: endpoint}
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if o.protocol == OTLPGRPC {
+		u.Path = otlpGRPCPath
+		transport.Protocols = new(http.Protocols)
+		transport.Protocols.SetHTTP2(true)
+		transport.Protocols.SetUnencryptedHTTP2(true)
+	} else if u.Path == "" || u.Path == "/" {
+		u.Path = otlpHTTPPath
+	}
+
+	return &OTLPExporter{
+		registry: r,
+		opts:     o,
+		url:      u.String(),
+		client:   &http.Client{Transport: transport},
+		prev:     make(map[string]otlpPoint),
+	}
+}
+
+func (e *OTLPExporter) Name() string        { return "otlp-metrics-exporter" }
+func (e *OTLPExporter) DependsOn() []string { return nil }
+
+// OnStart starts pushing on the configured interval
+func (e *OTLPExporter) OnStart(ctx context.Context) error {
+	e.stop = make(chan struct{})
+	e.done = make(chan struct{})
+	go e.run()
+	return nil
+}
+
+// OnStop stops the periodic pushes and makes a final one
+func (e *OTLPExporter) OnStop(ctx context.Context) error {
+	if e.stop != nil {
+		close(e.stop)
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+		}
+	}
+	defer e.client.CloseIdleConnections()
+	return e.Export(ctx)
+}
+
+func (e *OTLPExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(e.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.stop:
+			return
+		case <-ticker.C:
+			if err := e.Export(context.Background()); err != nil {
+				e.opts.onError(err)
+			}
+		}
+	}
+}
+
+// Export pushes the current state of the registry once
+func (e *OTLPExporter) Export(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, e.opts.timeout)
+	defer cancel()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	body, prev := e.encode(e.registry.Gather(), time.Now())
+	if err := e.send(ctx, body); err != nil {
+		return err
+	}
+	e.prev = prev
+	return nil
+}
+
+func (e *OTLPExporter) send(ctx context.Context, body []byte) error {
+	if e.opts.protocol == OTLPGRPC {
+		return e.sendGRPC(ctx, body)
+	}
+	return e.sendHTTP(ctx, body)
+}
+
+func (e *OTLPExporter) sendHTTP(ctx context.Context, body []byte) error {
+	req, err := e.newRequest(ctx, body, "application/x-protobuf")
+	if err != nil {
+		return err
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp export: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("otlp export: read response: %w", err)
+	}
+	if resp.StatusCode/100 != 2 {
+		if len(data) > otlpErrorBodyMax {
+			data = data[:otlpErrorBodyMax]
+		}
+		return fmt.Errorf("otlp export: %s: %s", resp.Status, bytes.TrimSpace(data))
+	}
+	return checkPartialSuccess(data)
+}
+
+func (e *OTLPExporter) sendGRPC(ctx context.Context, body []byte) error {
+	framed := make([]byte, 5, 5+len(body))
+	binary.BigEndian.PutUint32(framed[1:], uint32(len(body)))
+	req, err := e.newRequest(ctx, append(framed, body...), "application/grpc")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("TE", "trailers")
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("otlp export: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("otlp export: read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("otlp export: %s", resp.Status)
+	}
+
+	status, msg := resp.Trailer.Get("Grpc-Status"), resp.Trailer.Get("Grpc-Message")
+	if status == "" {
+		status, msg = resp.Header.Get("Grpc-Status"), resp.Header.Get("Grpc-Message")
+	}
+	if status != "0" {
+		if unescaped, err := url.PathUnescape(msg); err == nil {
+			msg = unescaped
+		}
+		return fmt.Errorf("otlp export: grpc status %s: %s", status, msg)
+	}
+	if len(data) < 5 {
+		return nil
+	}
+	return checkPartialSuccess(data[5:])
+}
+
+func (e *OTLPExporter) newRequest(ctx context.Context, body []byte, contentType string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("otlp export: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	for k, v := range e.opts.headers {
+		req.Header.Set(k, v)
+	}
+	return req, nil
+}
+
+func checkPartialSuccess(data []byte) error {
+	rejected, msg, err := decodePartialSuccess(data)
+	if err != nil {
+		return fmt.Errorf("otlp export: decode response: %w", err)
+	}
+	if rejected > 0 {
+		return fmt.Errorf("otlp export: %d data points rejected: %s", rejected, msg)
+	}
+	return nil
+}
+
+// encode builds an ExportMetricsServiceRequest and the delta state to keep
+// if it is accepted
+func (e *OTLPExporter) encode(families []Family, now time.Time) ([]byte, map[string]otlpPoint) {
+	prev := make(map[string]otlpPoint)
+	var metrics []byte
+	for _, f := range families {
+		if len(f.Series) == 0 {
+			continue
+		}
+		metrics = appendMessage(metrics, otlpScopeMetricsMetrics, e.encodeMetric(f, now, prev))
+	}
+
+	var scope, scopeMetrics []byte
+	scope = appendString(scope, otlpScopeName, otlpScope)
+	scopeMetrics = appendMessage(scopeMetrics, otlpScopeMetricsScope, scope)
+	scopeMetrics = append(scopeMetrics, metrics...)
+
+	keys := make([]string, 0, len(e.opts.resource))
+	for k := range e.opts.resource {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, k := range keys {
+		values[i] = e.opts.resource[k]
+	}
+
+	var resource, resourceMetrics, req []byte
+	resource = appendAttributes(resource, otlpResourceAttributes, keys, values)
+	resourceMetrics = appendMessage(resourceMetrics, otlpResourceMetricsResource, resource)
+	resourceMetrics = appendMessage(resourceMetrics, otlpResourceMetricsScopeMetrics, scopeMetrics)
+	req = appendMessage(req, otlpRequestResourceMetrics, resourceMetrics)
+	return req, prev
+}
+
+func (e *OTLPExporter) encodeMetric(f Family, now time.Time, prev map[string]otlpPoint) []byte {
+	temporality := uint64(otlpCumulative)
+	if e.opts.temporality == Delta {
+		temporality = otlpDelta
+	}
+	exponential := e.opts.exponential && f.Kind == KindHistogram
+	for _, s := range f.Series {
+		exponential = exponential && s.Exponential != nil
+	}
+
+	var data []byte
+	for _, s := range f.Series {
+		key := f.Name + "\xff" + strings.Join(s.LabelValues, "\xff")
+		cur := otlpPoint{time: now, value: s.Value, count: s.Count, sum: s.Sum, buckets: s.BucketCounts, expo: s.Exponential}
+		prev[key] = cur
+		start := s.Start
+		if e.opts.temporality == Delta && f.Kind != KindGauge && f.Kind != KindSummary {
+			if p, ok := e.prev[key]; ok {
+				start = p.time
+				cur = cur.sub(p)
+			}
+		}
+
+		var point []byte
+		switch {
+		case f.Kind == KindCounter || f.Kind == KindGauge:
+			point = appendAttributes(point, otlpNumberAttributes, f.LabelNames, s.LabelValues)
+			if f.Kind == KindCounter {
+				point = appendFixed64(point, otlpPointStart, uint64(start.UnixNano()))
+			}
+			point = appendFixed64(point, otlpPointTime, uint64(now.UnixNano()))
+			point = appendDouble(point, otlpNumberDouble, cur.value)
+		case exponential:
+			var pos, neg []byte
+			pos = appendSint32(pos, otlpExpoOffset, cur.expo.PositiveOffset)
+			pos = appendPackedVarint(pos, otlpExpoCounts, cur.expo.Positive)
+			neg = appendSint32(neg, otlpExpoOffset, cur.expo.NegativeOffset)
+			neg = appendPackedVarint(neg, otlpExpoCounts, cur.expo.Negative)
+			point = appendAttributes(point, otlpExpoAttributes, f.LabelNames, s.LabelValues)
+			point = appendFixed64(point, otlpPointStart, uint64(start.UnixNano()))
+			point = appendFixed64(point, otlpPointTime, uint64(now.UnixNano()))
+			point = appendFixed64(point, otlpPointCount, cur.count)
+			point = appendDouble(point, otlpPointSum, cur.sum)
+			point = appendSint32(point, otlpExpoScale, cur.expo.Scale)
+			point = appendFixed64(point, otlpExpoZeroCount, cur.expo.ZeroCount)
+			point = appendMessage(point, otlpExpoPositive, pos)
+			point = appendMessage(point, otlpExpoNegative, neg)
+		case f.Kind == KindHistogram:
+			point = appendAttributes(point, otlpHistogramAttributes, f.LabelNames, s.LabelValues)
+			point = appendFixed64(point, otlpPointStart, uint64(start.UnixNano()))
+			point = appendFixed64(point, otlpPointTime, uint64(now.UnixNano()))
+			point = appendFixed64(point, otlpPointCount, cur.count)
+			point = appendDouble(point, otlpPointSum, cur.sum)
+			point = appendPackedFixed64(point, otlpHistogramBuckets, cur.buckets)
+			point = appendPackedDouble(point, otlpHistogramBounds, f.Buckets)
+		case f.Kind == KindSummary:
+			point = appendAttributes(point, otlpSummaryAttributes, f.LabelNames, s.LabelValues)
+			point = appendFixed64(point, otlpPointStart, uint64(start.UnixNano()))
+			point = appendFixed64(point, otlpPointTime, uint64(now.UnixNano()))
+			point = appendFixed64(point, otlpPointCount, cur.count)
+			point = appendDouble(point, otlpPointSum, cur.sum)
+			if s.Count > 0 {
+				for i, q := range f.Quantiles {
+					var qv []byte
+					qv = appendDouble(qv, otlpQuantile, q)
+					qv = appendDouble(qv, otlpQuantileValue, s.QuantileValues[i])
+					point = appendMessage(point, otlpSummaryQuantiles, qv)
+				}
+			}
+		}
+		data = appendMessage(data, otlpDataPoints, point)
+	}
+
+	var metric []byte
+	metric = appendString(metric, otlpMetricName, f.Name)
+	metric = appendString(metric, otlpMetricDescription, f.Help)
+	switch {
+	case f.Kind == KindCounter:
+		data = appendVarint(data, otlpTemporality, temporality)
+		data = appendVarint(data, otlpMonotonic, 1)
+		metric = appendMessage(metric, otlpMetricSum, data)
+	case f.Kind == KindGauge:
+		metric = appendMessage(metric, otlpMetricGauge, data)
+	case exponential:
+		data = appendVarint(data, otlpTemporality, temporality)
+		metric = appendMessage(metric, otlpMetricExponentialHistogram, data)
+	case f.Kind == KindHistogram:
+		data = appendVarint(data, otlpTemporality, temporality)
+		metric = appendMessage(metric, otlpMetricHistogram, data)
+	case f.Kind == KindSummary:
+		metric = appendMessage(metric, otlpMetricSummary, data)
+	}
+	return metric
+}
+
+// sub returns the change from p to c; a decrease means the series was
+// reset, so c is returned whole
+func (c otlpPoint) sub(p otlpPoint) otlpPoint {
+	if c.value < p.value || c.count < p.count {
+		return c
+	}
+	d := otlpPoint{time: c.time, value: c.value - p.value, count: c.count - p.count, sum: c.sum - p.sum}
+	if len(c.buckets) == len(p.buckets) {
+		d.buckets = make([]uint64, len(c.buckets))
+		for i := range c.buckets {
+			d.buckets[i] = c.buckets[i] - p.buckets[i]
+		}
+	}
+	if c.expo != nil {
+		d.expo = c.expo.Sub(p.expo)
+	}
+	return d
+}
@@ -0,0 +1,205 @@
+package metrics
+
+import (
+	"errors"
+	"math"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Field numbers of the OTLP metrics protocol, opentelemetry/proto/metrics/v1
+// and opentelemetry/proto/collector/metrics/v1. Messages are encoded by hand
+// so the exporter needs no generated OTLP code.
+const (
+	otlpRequestResourceMetrics = 1
+
+	otlpResourceMetricsResource     = 1
+	otlpResourceMetricsScopeMetrics = 2
+	otlpResourceAttributes          = 1
+	otlpScopeMetricsScope           = 1
+	otlpScopeMetricsMetrics         = 2
+	otlpScopeName                   = 1
+	otlpScopeVersion                = 2
+
+	otlpMetricName                 = 1
+	otlpMetricDescription          = 2
+	otlpMetricGauge                = 5
+	otlpMetricSum                  = 7
+	otlpMetricHistogram            = 9
+	otlpMetricExponentialHistogram = 10
+	otlpMetricSummary              = 11
+
+	otlpDataPoints  = 1 // Gauge, Sum, Histogram, ExponentialHistogram and Summary
+	otlpTemporality = 2 // Sum, Histogram and ExponentialHistogram
+	otlpMonotonic   = 3 // Sum
+
+	otlpPointStart = 2 // every data point
+	otlpPointTime  = 3
+	otlpPointCount = 4 // histogram and summary points
+	otlpPointSum   = 5
+
+	otlpNumberAttributes = 7
+	otlpNumberDouble     = 4
+
+	otlpHistogramAttributes = 9
+	otlpHistogramBuckets    = 6
+	otlpHistogramBounds     = 7
+
+	otlpExpoAttributes = 1
+	otlpExpoScale      = 6
+	otlpExpoZeroCount  = 7
+	otlpExpoPositive   = 8
+	otlpExpoNegative   = 9
+	otlpExpoOffset     = 1
+	otlpExpoCounts     = 2
+
+	otlpSummaryAttributes = 7
+	otlpSummaryQuantiles  = 6
+	otlpQuantile          = 1
+	otlpQuantileValue     = 2
+
+	otlpKeyValueKey    = 1
+	otlpKeyValueValue  = 2
+	otlpAnyValueString = 1
+
+	otlpResponsePartialSuccess = 1
+	otlpPartialRejected        = 1
+	otlpPartialMessage         = 2
+)
+
+func appendMessage(b []byte, num protowire.Number, msg []byte) []byte {
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, msg)
+}
+
+func appendString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+func appendVarint(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, v)
+}
+
+func appendSint32(b []byte, num protowire.Number, v int32) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, protowire.EncodeZigZag(int64(v)))
+}
+
+func appendFixed64(b []byte, num protowire.Number, v uint64) []byte {
+	if v == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, v)
+}
+
+// appendDouble always writes v, so a zero value is distinguishable from an unset oneof
+func appendDouble(b []byte, num protowire.Number, v float64) []byte {
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(v))
+}
+
+func appendPackedFixed64(b []byte, num protowire.Number, vs []uint64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	packed := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		packed = protowire.AppendFixed64(packed, v)
+	}
+	return appendMessage(b, num, packed)
+}
+
+func appendPackedDouble(b []byte, num protowire.Number, vs []float64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	packed := make([]byte, 0, 8*len(vs))
+	for _, v := range vs {
+		packed = protowire.AppendFixed64(packed, math.Float64bits(v))
+	}
+	return appendMessage(b, num, packed)
+}
+
+func appendPackedVarint(b []byte, num protowire.Number, vs []uint64) []byte {
+	if len(vs) == 0 {
+		return b
+	}
+	var packed []byte
+	for _, v := range vs {
+		packed = protowire.AppendVarint(packed, v)
+	}
+	return appendMessage(b, num, packed)
+}
+
+// appendAttributes appends a KeyValue with a string AnyValue per label
+func appendAttributes(b []byte, num protowire.Number, names, values []string) []byte {
+	for i, name := range names {
+		var value, kv []byte
+		value = appendString(value, otlpAnyValueString, values[i])
+		kv = appendString(kv, otlpKeyValueKey, name)
+		kv = appendMessage(kv, otlpKeyValueValue, value)
+		b = appendMessage(b, num, kv)
+	}
+	return b
+}
+
+// decodePartialSuccess reads the partial success of an
+// ExportMetricsServiceResponse, returning the rejected data points and message
+func decodePartialSuccess(b []byte) (int64, string, error) {
+	var rejected int64
+	var msg string
+	err := walkFields(b, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) {
+		if num != otlpResponsePartialSuccess || typ != protowire.BytesType {
+			return
+		}
+		walkFields(v, func(num protowire.Number, typ protowire.Type, v []byte, n uint64) {
+			switch {
+			case num == otlpPartialRejected && typ == protowire.VarintType:
+				rejected = int64(n)
+			case num == otlpPartialMessage && typ == protowire.BytesType:
+				msg = string(v)
+			}
+		})
+	})
+	return rejected, msg, err
+}
+
+// walkFields calls fn for each field of the message in b, with the bytes of
+// length-delimited fields or the value of varint fields
+func walkFields(b []byte, fn func(num protowire.Number, typ protowire.Type, v []byte, n uint64)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return errors.New("malformed protobuf tag")
+		}
+		b = b[n:]
+		var v []byte
+		var u uint64
+		switch typ {
+		case protowire.BytesType:
+			v, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			u, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return errors.New("malformed protobuf field")
+		}
+		b = b[n:]
+		fn(num, typ, v, u)
+	}
+	return nil
+}
@@ -0,0 +1,274 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	"google.golang.org/protobuf/proto"
+)
+
+// receiver is an OTLP/HTTP collector stub that decodes every push with the
+// reference protobuf definitions
+type receiver struct {
+	t  *testing.T
+	mu sync.Mutex
+	// requests are the decoded pushes, in order
+	requests []*colmetricspb.ExportMetricsServiceRequest
+	headers  []http.Header
+}
+
+func newReceiver(t *testing.T) (*receiver, string) {
+	rc := &receiver{t: t}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/metrics" {
+			t.Errorf("path = %q, want /v1/metrics", r.URL.Path)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/x-protobuf" {
+			t.Errorf("Content-Type = %q", ct)
+		}
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Errorf("read body: %v", err)
+		}
+		req := new(colmetricspb.ExportMetricsServiceRequest)
+		if err := proto.Unmarshal(body, req); err != nil {
+			t.Errorf("decode push: %v", err)
+		}
+		rc.mu.Lock()
+		rc.requests = append(rc.requests, req)
+		rc.headers = append(rc.headers, r.Header.Clone())
+		rc.mu.Unlock()
+		w.Header().Set("Content-Type", "application/x-protobuf")
+		resp, _ := proto.Marshal(&colmetricspb.ExportMetricsServiceResponse{})
+		w.Write(resp)
+	}))
+	t.Cleanup(srv.Close)
+	return rc, srv.URL
+}
+
+func (rc *receiver) last() *colmetricspb.ExportMetricsServiceRequest {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if len(rc.requests) == 0 {
+		rc.t.Fatal("no push received")
+	}
+	return rc.requests[len(rc.requests)-1]
+}
+
+func (rc *receiver) count() int {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	return len(rc.requests)
+}
+
+// metricsByName flattens a push, checking that it holds a single resource and scope
+func metricsByName(t *testing.T, req *colmetricspb.ExportMetricsServiceRequest) map[string]*metricspb.Metric {
+	t.Helper()
+	if len(req.ResourceMetrics) != 1 || len(req.ResourceMetrics[0].ScopeMetrics) != 1 {
+		t.Fatalf("want one resource and one scope, got %v", req)
+	}
+	sm := req.ResourceMetrics[0].ScopeMetrics[0]
+	if sm.Scope.GetName() != otlpScope {
+		t.Errorf("scope = %q, want %q", sm.Scope.GetName(), otlpScope)
+	}
+	byName := make(map[string]*metricspb.Metric)
+	for _, m := range sm.Metrics {
+		byName[m.Name] = m
+	}
+	return byName
+}
+
+func attrs(kvs []*commonpb.KeyValue) map[string]string {
+	out := make(map[string]string)
+	for _, kv := range kvs {
+		out[kv.Key] = kv.Value.GetStringValue()
+	}
+	return out
+}
+
+func TestOTLPExporterCumulative(t *testing.T) {
+	rc, url := newReceiver(t)
+	r := NewRegistry()
+	r.Counter("orders_total", 3, "status", "ok")
+	r.Gauge("queue_depth", 7)
+	r.Histogram("request_seconds", 0.25)
+	r.Histogram("request_seconds", 0.5)
+	r.Summary("payload_bytes", 100)
+
+	e := NewOTLPExporter(r, url,
+		WithOTLPHeaders(map[string]string{"Authorization": "Bearer t"}),
+		WithResource(map[string]string{"service.name": "orders"}))
+	if err := e.Export(context.Background()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	req := rc.last()
+	if got := rc.headers[0].Get("Authorization"); got != "Bearer t" {
+		t.Errorf("Authorization = %q", got)
+	}
+	if got := attrs(req.ResourceMetrics[0].Resource.Attributes); got["service.name"] != "orders" {
+		t.Errorf("resource attributes = %v", got)
+	}
+	byName := metricsByName(t, req)
+
+	sum := byName["orders_total"].GetSum()
+	if sum == nil || !sum.IsMonotonic || sum.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_CUMULATIVE {
+		t.Fatalf("orders_total = %v, want a cumulative monotonic sum", byName["orders_total"])
+	}
+	if p := sum.DataPoints[0]; p.GetAsDouble() != 3 || attrs(p.Attributes)["status"] != "ok" || p.StartTimeUnixNano == 0 || p.TimeUnixNano < p.StartTimeUnixNano {
+		t.Errorf("orders_total point = %v", p)
+	}
+
+	gauge := byName["queue_depth"].GetGauge()
+	if gauge == nil || gauge.DataPoints[0].GetAsDouble() != 7 {
+		t.Errorf("queue_depth = %v, want a gauge of 7", byName["queue_depth"])
+	}
+
+	hist := byName["request_seconds"].GetHistogram()
+	if hist == nil {
+		t.Fatalf("request_seconds = %v, want a histogram", byName["request_seconds"])
+	}
+	hp := hist.DataPoints[0]
+	if hp.Count != 2 || hp.GetSum() != 0.75 || len(hp.BucketCounts) != len(hp.ExplicitBounds)+1 {
+		t.Errorf("request_seconds point = %v", hp)
+	}
+	var total uint64
+	for _, c := range hp.BucketCounts {
+		total += c
+	}
+	if total != hp.Count {
+		t.Errorf("bucket counts add up to %d, want %d", total, hp.Count)
+	}
+
+	summary := byName["payload_bytes"].GetSummary()
+	if summary == nil || summary.DataPoints[0].Count != 1 || summary.DataPoints[0].Sum != 100 {
+		t.Errorf("payload_bytes = %v, want a summary of one observation", byName["payload_bytes"])
+	}
+}
+
+func TestOTLPExporterDelta(t *testing.T) {
+	rc, url := newReceiver(t)
+	r := NewRegistry()
+	r.Counter("orders_total", 3)
+	r.Histogram("request_seconds", 0.2)
+
+	e := NewOTLPExporter(r, url, WithTemporality(Delta))
+	if err := e.Export(context.Background()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	r.Counter("orders_total", 2)
+	r.Histogram("request_seconds", 0.4)
+	r.Histogram("request_seconds", 0.6)
+	if err := e.Export(context.Background()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+
+	first := metricsByName(t, rc.requests[0])
+	second := metricsByName(t, rc.last())
+	sum := second["orders_total"].GetSum()
+	if sum.AggregationTemporality != metricspb.AggregationTemporality_AGGREGATION_TEMPORALITY_DELTA {
+		t.Errorf("temporality = %v, want delta", sum.AggregationTemporality)
+	}
+	if got := sum.DataPoints[0].GetAsDouble(); got != 2 {
+		t.Errorf("second orders_total delta = %v, want 2", got)
+	}
+	if start, prev := sum.DataPoints[0].StartTimeUnixNano, first["orders_total"].GetSum().DataPoints[0].TimeUnixNano; start != prev {
+		t.Errorf("delta start = %d, want the previous push at %d", start, prev)
+	}
+	if hp := second["request_seconds"].GetHistogram().DataPoints[0]; hp.Count != 2 {
+		t.Errorf("second request_seconds delta count = %d, want 2", hp.Count)
+	}
+}
+
+func TestOTLPExporterExponential(t *testing.T) {
+	rc, url := newReceiver(t)
+	r := NewRegistry()
+	r.Histogram("early_seconds", 1)
+	e := NewOTLPExporter(r, url, WithExponentialHistograms())
+	for _, v := range []float64{0.5, 1, 2, 4} {
+		r.Histogram("request_seconds", v)
+	}
+
+	if err := e.Export(context.Background()); err != nil {
+		t.Fatalf("Export: %v", err)
+	}
+	eh := metricsByName(t, rc.last())["request_seconds"].GetExponentialHistogram()
+	if eh == nil {
+		t.Fatal("request_seconds is not an exponential histogram")
+	}
+	p := eh.DataPoints[0]
+	var total uint64 = p.ZeroCount
+	for _, c := range p.Positive.GetBucketCounts() {
+		total += c
+	}
+	for _, c := range p.Negative.GetBucketCounts() {
+		total += c
+	}
+	if p.Count != 4 || total != 4 || p.GetSum() != 7.5 {
+		t.Errorf("point = %v, want 4 observations summing to 7.5", p)
+	}
+	// Series observed before the exporter was created keep their explicit buckets
+	if metricsByName(t, rc.last())["early_seconds"].GetHistogram() == nil {
+		t.Error("early_seconds is not an explicit histogram")
+	}
+}
+
+func TestExponentialHistogramsOnlyWhenEnabled(t *testing.T) {
+	r := NewRegistry()
+	r.Histogram("request_seconds", 1)
+	NewOTLPExporter(r, "localhost:4318")
+	r.Histogram("db_seconds", 1, "table", "users")
+	for _, f := range r.Gather() {
+		for _, s := range f.Series {
+			if s.Exponential != nil {
+				t.Errorf("%s%v has exponential buckets without an exponential exporter", f.Name, s.LabelValues)
+			}
+		}
+	}
+}
+
+func TestOTLPExporterNonPositiveInterval(t *testing.T) {
+	rc, url := newReceiver(t)
+	r := NewRegistry()
+	r.Counter("orders_total", 1)
+
+	e := NewOTLPExporter(r, url, WithOTLPInterval(0), WithOTLPTimeout(-time.Second))
+	if e.opts.interval != time.Minute || e.opts.timeout != 10*time.Second {
+		t.Fatalf("interval, timeout = %v, %v; want the defaults", e.opts.interval, e.opts.timeout)
+	}
+	ctx := context.Background()
+	if err := e.OnStart(ctx); err != nil {
+		t.Fatalf("OnStart: %v", err)
+	}
+	if err := e.OnStop(ctx); err != nil {
+		t.Fatalf("OnStop: %v", err)
+	}
+	if rc.count() != 1 {
+		t.Errorf("pushes = %d, want the final one", rc.count())
+	}
+}
+
+func TestOTLPExporterPartialSuccess(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, _ := proto.Marshal(&colmetricspb.ExportMetricsServiceResponse{
+			PartialSuccess: &colmetricspb.ExportMetricsPartialSuccess{RejectedDataPoints: 2, ErrorMessage: "too old"},
+		})
+		w.Write(resp)
+	}))
+	defer srv.Close()
+	r := NewRegistry()
+	r.Counter("orders_total", 1)
+
+	err := NewOTLPExporter(r, srv.URL).Export(context.Background())
+	if err == nil || err.Error() != "otlp export: 2 data points rejected: too old" {
+		t.Errorf("Export error = %v, want the rejected data points", err)
+	}
+}
@@ -0,0 +1,120 @@
+package metrics
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// userHZ is the unit of CPU times in /proc/self/stat
+const userHZ = 100
+
+// processCollector reads /proc on every gather; outside Linux it reports nothing
+type processCollector struct {
+	cpu, rss, vsize, openFDs, maxFDs, startTime *family
+}
+
+// NewProcessCollector registers process metrics read from /proc in r: CPU
+// time, resident and virtual memory, open and maximum file descriptors and
+// start time. Add it with r.RegisterCollector.
+func NewProcessCollector(r *Registry) Collector {
+	return &processCollector{
+		cpu:       r.mustRegister(Desc{Kind: KindCounter, Name: "process_cpu_seconds_total", Help: "User and system CPU time spent in seconds."}),
+		rss:       r.mustRegister(Desc{Kind: KindGauge, Name: "process_resident_memory_bytes", Help: "Resident memory size in bytes."}),
+		vsize:     r.mustRegister(Desc{Kind: KindGauge, Name: "process_virtual_memory_bytes", Help: "Virtual memory size in bytes."}),
+		openFDs:   r.mustRegister(Desc{Kind: KindGauge, Name: "process_open_fds", Help: "Number of open file descriptors."}),
+		maxFDs:    r.mustRegister(Desc{Kind: KindGauge, Name: "process_max_fds", Help: "Maximum number of open file descriptors."}),
+		startTime: r.mustRegister(Desc{Kind: KindGauge, Name: "process_start_time_seconds", Help: "Start time of the process since the Unix epoch in seconds."}),
+	}
+}
+
+func (c *processCollector) Collect() {
+	if stat, err := readProcStat(); err == nil {
+		c.cpu.series(nil).set(float64(stat.utime+stat.stime) / userHZ)
+		c.rss.series(nil).set(float64(stat.rss * int64(os.Getpagesize())))
+		c.vsize.series(nil).set(float64(stat.vsize))
+		if boot, err := bootTime(); err == nil {
+			c.startTime.series(nil).set(float64(boot) + float64(stat.startTicks)/userHZ)
+		}
+	}
+	if fds, err := os.ReadDir("/proc/self/fd"); err == nil {
+		c.openFDs.series(nil).set(float64(len(fds)))
+	}
+	if max, err := maxOpenFiles(); err == nil {
+		c.maxFDs.series(nil).set(max)
+	}
+}
+
+type procStat struct {
+	utime, stime, startTicks, vsize, rss int64
+}
+
+// readProcStat parses the fields of /proc/self/stat after the command name,
+// which may itself contain spaces and parentheses
+func readProcStat() (procStat, error) {
+	data, err := os.ReadFile("/proc/self/stat")
+	if err != nil {
+		return procStat{}, err
+	}
+	rest := string(data)
+	if i := strings.LastIndexByte(rest, ')'); i >= 0 {
+		rest = rest[i+1:]
+	}
+	fields := strings.Fields(rest)
+	if len(fields) < 22 {
+		return procStat{}, os.ErrInvalid
+	}
+	// fields[0] is field 3 (state) of proc(5)
+	var stat procStat
+	for _, f := range []struct {
+		dst   *int64
+		index int
+	}{{&stat.utime, 11}, {&stat.stime, 12}, {&stat.startTicks, 19}, {&stat.vsize, 20}, {&stat.rss, 21}} {
+		if *f.dst, err = strconv.ParseInt(fields[f.index], 10, 64); err != nil {
+			return procStat{}, err
+		}
+	}
+	return stat, nil
+}
+
+// bootTime returns the system boot time in seconds since the Unix epoch
+func bootTime() (int64, error) {
+	f, err := os.Open("/proc/stat")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if v, ok := strings.CutPrefix(scanner.Text(), "btime "); ok {
+			return strconv.ParseInt(strings.TrimSpace(v), 10, 64)
+		}
+	}
+	return 0, os.ErrNotExist
+}
+
+// maxOpenFiles returns the soft limit on open files from /proc/self/limits
+func maxOpenFiles() (float64, error) {
+	f, err := os.Open("/proc/self/limits")
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		v, ok := strings.CutPrefix(scanner.Text(), "Max open files")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(v)
+		if len(fields) == 0 {
+			break
+		}
+		if fields[0] == "unlimited" {
+			return 0, os.ErrNotExist
+		}
+		return strconv.ParseFloat(fields[0], 64)
+	}
+	return 0, os.ErrNotExist
+}
@@ -0,0 +1,162 @@
+package metrics
+
+import (
+	"bufio"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// prometheusContentType is the Prometheus text exposition format, version 0.0.4
+	prometheusContentType = "text/plain; version=0.0.4; charset=utf-8"
+	// openMetricsContentType is the OpenMetrics text format, version 1.0.0
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+// Handler serves the registry in the Prometheus text exposition format, or in
+// the OpenMetrics text format with exemplars when the request accepts it
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.Contains(req.Header.Get("Accept"), "application/openmetrics-text") {
+			w.Header().Set("Content-Type", openMetricsContentType)
+			WriteOpenMetrics(w, r.Gather())
+			return
+		}
+		w.Header().Set("Content-Type", prometheusContentType)
+		WritePrometheus(w, r.Gather())
+	})
+}
+
+// WritePrometheus writes families in the Prometheus text exposition format
+func WritePrometheus(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		if f.Help != "" {
+			bw.WriteString("# HELP " + f.Name + " " + escapeHelp(f.Help) + "\n")
+		}
+		bw.WriteString("# TYPE " + f.Name + " " + string(f.Kind) + "\n")
+		for _, s := range f.Series {
+			writeSeries(bw, f, s, false)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteOpenMetrics writes families in the OpenMetrics text format, with the
+// exemplars of counters and histogram buckets
+func WriteOpenMetrics(w io.Writer, families []Family) error {
+	bw := bufio.NewWriter(w)
+	for _, f := range families {
+		name := f.Name
+		if f.Kind == KindCounter {
+			// OpenMetrics names the counter family without the _total suffix
+			name = strings.TrimSuffix(name, "_total")
+			f.Name = name + "_total"
+		}
+		if f.Help != "" {
+			bw.WriteString("# HELP " + name + " " + escapeLabelValue(f.Help) + "\n")
+		}
+		bw.WriteString("# TYPE " + name + " " + string(f.Kind) + "\n")
+		for _, s := range f.Series {
+			writeSeries(bw, f, s, true)
+		}
+	}
+	bw.WriteString("# EOF\n")
+	return bw.Flush()
+}
+
+// writeSeries writes the samples of s, with exemplars if withExemplars is set
+func writeSeries(w *bufio.Writer, f Family, s Series, withExemplars bool) {
+	switch f.Kind {
+	case KindCounter, KindGauge:
+		var ex *Exemplar
+		if withExemplars {
+			ex = s.Exemplar
+		}
+		writeSample(w, f.Name, f.LabelNames, s.LabelValues, "", "", s.Value, ex)
+	case KindHistogram:
+		var cumulative uint64
+		for i, n := range s.BucketCounts {
+			cumulative += n
+			le := math.Inf(1)
+			if i < len(f.Buckets) {
+				le = f.Buckets[i]
+			}
+			var ex *Exemplar
+			if withExemplars && i < len(s.BucketExemplars) {
+				ex = s.BucketExemplars[i]
+			}
+			writeSample(w, f.Name+"_bucket", f.LabelNames, s.LabelValues, "le", formatFloat(le), float64(cumulative), ex)
+		}
+		writeSample(w, f.Name+"_sum", f.LabelNames, s.LabelValues, "", "", s.Sum, nil)
+		writeSample(w, f.Name+"_count", f.LabelNames, s.LabelValues, "", "", float64(s.Count), nil)
+	case KindSummary:
+		for i, q := range f.Quantiles {
+			writeSample(w, f.Name, f.LabelNames, s.LabelValues, "quantile", formatFloat(q), s.QuantileValues[i], nil)
+		}
+		writeSample(w, f.Name+"_sum", f.LabelNames, s.LabelValues, "", "", s.Sum, nil)
+		writeSample(w, f.Name+"_count", f.LabelNames, s.LabelValues, "", "", float64(s.Count), nil)
+	}
+}
+
+// writeSample writes one line, with the extra label appended when set and
+// the exemplar when not nil
+func writeSample(w *bufio.Writer, name string, names, values []string, extraName, extraValue string, v float64, ex *Exemplar) {
+	w.WriteString(name)
+	if len(names) > 0 || extraName != "" {
+		w.WriteByte('{')
+		for i, n := range names {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(n + `="` + escapeLabelValue(values[i]) + `"`)
+		}
+		if extraName != "" {
+			if len(names) > 0 {
+				w.WriteByte(',')
+			}
+			w.WriteString(extraName + `="` + extraValue + `"`)
+		}
+		w.WriteByte('}')
+	}
+	w.WriteString(" " + formatFloat(v))
+	if ex != nil {
+		w.WriteString(` # {trace_id="` + ex.TraceID + `",span_id="` + ex.SpanID + `"} ` + formatFloat(ex.Value))
+		w.WriteString(" " + formatTimestamp(ex.Time))
+	}
+	w.WriteByte('\n')
+}
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatTimestamp formats t as seconds since the epoch
+func formatTimestamp(t time.Time) string {
+	return strconv.FormatFloat(float64(t.UnixNano())/1e9, 'f', 3, 64)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(s string) string       { return helpEscaper.Replace(s) }
+func escapeLabelValue(s string) string { return labelEscaper.Replace(s) }
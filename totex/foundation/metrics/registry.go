@@ -0,0 +1,600 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/foundation/tracing"
+)
+
+// Kind is the instrument type of a metric family
+type Kind string
+
+const (
+	KindCounter   Kind = "counter"
+	KindGauge     Kind = "gauge"
+	KindHistogram Kind = "histogram"
+	KindSummary   Kind = "summary"
+)
+
+const (
+	// DefaultMaxSeries is the number of label sets a family may hold before
+	// further ones are folded into its overflow series
+	DefaultMaxSeries = 1000
+	// OverflowLabelValue is every label value of a family's overflow series
+	OverflowLabelValue = "overflow"
+	// summaryWindow is how many recent observations a summary computes quantiles over
+	summaryWindow = 1024
+
+	overflowMetric = "metrics_cardinality_overflow_total"
+	invalidMetric  = "metrics_invalid_samples_total"
+)
+
+var (
+	// DefBuckets are the histogram buckets used when none are given, suited to
+	// request latencies in seconds
+	DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+	// DefQuantiles are the summary quantiles used when none are given
+	DefQuantiles = []float64{0.5, 0.9, 0.99}
+)
+
+var (
+	metricNameRE = regexp.MustCompile(`^[a-zA-Z_:][a-zA-Z0-9_:]*$`)
+	labelNameRE  = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+)
+
+// Desc describes a metric family
+type Desc struct {
+	Kind       Kind
+	Name       string
+	Help       string
+	LabelNames []string
+	Buckets    []float64 // histogram bucket upper bounds, without +Inf
+	Quantiles  []float64 // summary quantiles
+}
+
+// Family is a snapshot of a metric family and its series
+type Family struct {
+	Desc
+	Series []Series
+}
+
+// Series is a snapshot of one label set of a family
+type Series struct {
+	LabelValues []string
+	Value       float64 // counter or gauge value
+
+	Count          uint64    // histogram or summary observations
+	Sum            float64   // sum of histogram or summary observations
+	BucketCounts   []uint64  // observations per histogram bucket, not cumulative; the last is +Inf
+	QuantileValues []float64 // summary value per Desc.Quantiles
+
+	// Exponential holds histogram observations in exponential buckets; it is
+	// nil unless the registry has exponential histograms enabled, and for
+	// histograms filled by a collector
+	Exponential *ExponentialHistogram
+	Start       time.Time // when the series was created
+
+	Exemplar        *Exemplar   // latest counter exemplar
+	BucketExemplars []*Exemplar // latest exemplar per histogram bucket, nil where there is none
+}
+
+// Exemplar links a sample to the trace it was recorded in
+type Exemplar struct {
+	Value   float64
+	TraceID string
+	SpanID  string
+	Time    time.Time
+}
+
+// exemplarFrom returns an exemplar for v if ctx carries a sampled span context
+func exemplarFrom(ctx context.Context, v float64) *Exemplar {
+	if ctx == nil {
+		return nil
+	}
+	sc := tracing.SpanContextFromContext(ctx)
+	if !sc.IsValid() || !sc.Sampled {
+		return nil
+	}
+	return &Exemplar{Value: v, TraceID: sc.TraceID, SpanID: sc.SpanID, Time: time.Now()}
+}
+
+// Registry holds metric families and aggregates their samples in memory for
+// exposition. It implements Metrics, registering a family on the first call
+// for a name with the label names of that call.
+type Registry struct {
+	maxSeries   atomic.Int64
+	exponential atomic.Bool
+
+	mu         sync.RWMutex
+	families   map[string]*family
+	collectors map[string]Collector
+}
+
+// Collector updates instruments of a registry each time it is gathered, for
+// values read from elsewhere such as the Go runtime
+type Collector interface {
+	Collect()
+}
+
+// DefaultRegistry is the registry behind the package-level instrument
+// constructors. Apps have registries of their own and only use it when given
+// it explicitly.
+var DefaultRegistry = NewRegistry()
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	r := &Registry{families: make(map[string]*family), collectors: make(map[string]Collector)}
+	r.maxSeries.Store(DefaultMaxSeries)
+	return r
+}
+
+// SetMaxSeries sets the number of label sets each family may hold; values
+// of n below 1 restore DefaultMaxSeries
+func (r *Registry) SetMaxSeries(n int) {
+	if n < 1 {
+		n = DefaultMaxSeries
+	}
+	r.maxSeries.Store(int64(n))
+}
+
+// EnableExponentialHistograms makes histogram series created from now on
+// also aggregate into exponential buckets, as exported by an OTLPExporter
+// with WithExponentialHistograms. Series created earlier keep only their
+// explicit buckets.
+func (r *Registry) EnableExponentialHistograms() {
+	r.exponential.Store(true)
+}
+
+func (r *Registry) Name() string { return "registry" }
+
+// RegisterCollector adds c under name, replacing any collector already
+// registered under that name
+func (r *Registry) RegisterCollector(name string, c Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors[name] = c
+}
+
+func (r *Registry) Counter(name string, value float64, labels ...string) {
+	r.record(context.Background(), KindCounter, name, value, labels)
+}
+
+func (r *Registry) Gauge(name string, value float64, labels ...string) {
+	r.record(context.Background(), KindGauge, name, value, labels)
+}
+
+func (r *Registry) Histogram(name string, value float64, labels ...string) {
+	r.record(context.Background(), KindHistogram, name, value, labels)
+}
+
+func (r *Registry) Summary(name string, value float64, labels ...string) {
+	r.record(context.Background(), KindSummary, name, value, labels)
+}
+
+func (r *Registry) CounterContext(ctx context.Context, name string, value float64, labels ...string) {
+	r.record(ctx, KindCounter, name, value, labels)
+}
+
+func (r *Registry) HistogramContext(ctx context.Context, name string, value float64, labels ...string) {
+	r.record(ctx, KindHistogram, name, value, labels)
+}
+
+// record applies a sample from the Metrics interface, with an exemplar from
+// the sampled span in ctx, if any. Samples that do not fit the family registered under
+// name are dropped and counted.
+func (r *Registry) record(ctx context.Context, kind Kind, name string, value float64, labels []string) {
+	if len(labels)%2 != 0 || (kind == KindCounter && value < 0) {
+		r.invalid(name)
+		return
+	}
+	names := make([]string, 0, len(labels)/2)
+	values := make(map[string]string, len(labels)/2)
+	for i := 0; i < len(labels); i += 2 {
+		names = append(names, labels[i])
+		values[labels[i]] = labels[i+1]
+	}
+
+	f, err := r.register(Desc{Kind: kind, Name: name, LabelNames: names}, false)
+	if err != nil || f.desc.Kind != kind || len(f.desc.LabelNames) != len(values) {
+		r.invalid(name)
+		return
+	}
+	ordered := make([]string, len(f.desc.LabelNames))
+	for i, n := range f.desc.LabelNames {
+		v, ok := values[n]
+		if !ok {
+			r.invalid(name)
+			return
+		}
+		ordered[i] = v
+	}
+
+	s := f.series(ordered)
+	switch kind {
+	case KindCounter:
+		s.addExemplar(value, exemplarFrom(ctx, value))
+	case KindGauge:
+		s.set(value)
+	default:
+		s.observe(value, exemplarFrom(ctx, value), f.desc.Buckets...)
+	}
+}
+
+func (r *Registry) invalid(name string) {
+	if name == invalidMetric {
+		return
+	}
+	r.selfCounter(invalidMetric, "Samples dropped because they did not match their metric family").
+		series([]string{name}).add(1)
+}
+
+func (r *Registry) overflowed(name string) {
+	if name == overflowMetric {
+		return
+	}
+	r.selfCounter(overflowMetric, "Label sets folded into the overflow series of a family at its series limit").
+		series([]string{name}).add(1)
+}
+
+func (r *Registry) selfCounter(name, help string) *family {
+	f, _ := r.register(Desc{Kind: KindCounter, Name: name, Help: help, LabelNames: []string{"metric"}}, false)
+	return f
+}
+
+// register returns the family described by desc, creating it if needed. With
+// strict, an existing family must match desc exactly.
+func (r *Registry) register(desc Desc, strict bool) (*family, error) {
+	r.mu.RLock()
+	f, ok := r.families[desc.Name]
+	r.mu.RUnlock()
+	if !ok {
+		var err error
+		if desc, err = normalize(desc); err != nil {
+			return nil, err
+		}
+		r.mu.Lock()
+		if f, ok = r.families[desc.Name]; !ok {
+			f = &family{desc: desc, reg: r, byKey: make(map[string]*series)}
+			r.families[desc.Name] = f
+		}
+		r.mu.Unlock()
+		if !ok {
+			return f, nil
+		}
+	}
+	if strict {
+		if desc, err := normalize(desc); err != nil {
+			return nil, err
+		} else if !sameDesc(f.desc, desc) {
+			return nil, fmt.Errorf("metric %s already registered with a different kind, help, labels, buckets or quantiles", desc.Name)
+		}
+	}
+	return f, nil
+}
+
+// mustRegister registers desc for a typed instrument, panicking if it is invalid
+func (r *Registry) mustRegister(desc Desc) *family {
+	f, err := r.register(desc, true)
+	if err != nil {
+		panic("metrics: " + err.Error())
+	}
+	return f
+}
+
+// normalize validates desc and fills in default buckets and quantiles
+func normalize(desc Desc) (Desc, error) {
+	if !metricNameRE.MatchString(desc.Name) {
+		return desc, fmt.Errorf("invalid metric name %q", desc.Name)
+	}
+	seen := make(map[string]bool, len(desc.LabelNames))
+	for _, n := range desc.LabelNames {
+		switch {
+		case !labelNameRE.MatchString(n) || strings.HasPrefix(n, "__"):
+			return desc, fmt.Errorf("metric %s: invalid label name %q", desc.Name, n)
+		case seen[n]:
+			return desc, fmt.Errorf("metric %s: duplicate label name %q", desc.Name, n)
+		case n == "le" && desc.Kind == KindHistogram, n == "quantile" && desc.Kind == KindSummary:
+			return desc, fmt.Errorf("metric %s: label name %q is reserved for %ss", desc.Name, n, desc.Kind)
+		}
+		seen[n] = true
+	}
+	desc.LabelNames = append([]string(nil), desc.LabelNames...)
+
+	switch desc.Kind {
+	case KindHistogram:
+		if desc.Buckets == nil {
+			desc.Buckets = DefBuckets
+		}
+		buckets := append([]float64(nil), desc.Buckets...)
+		if n := len(buckets); n > 0 && math.IsInf(buckets[n-1], 1) {
+			buckets = buckets[:n-1]
+		}
+		for i, b := range buckets {
+			if math.IsNaN(b) || (i > 0 && b <= buckets[i-1]) {
+				return desc, fmt.Errorf("metric %s: buckets must be strictly increasing", desc.Name)
+			}
+		}
+		desc.Buckets = buckets
+	case KindSummary:
+		if desc.Quantiles == nil {
+			desc.Quantiles = DefQuantiles
+		}
+		quantiles := append([]float64(nil), desc.Quantiles...)
+		sort.Float64s(quantiles)
+		for _, q := range quantiles {
+			if !(q > 0 && q < 1) {
+				return desc, fmt.Errorf("metric %s: quantile %v is not between 0 and 1", desc.Name, q)
+			}
+		}
+		desc.Quantiles = quantiles
+	case KindCounter, KindGauge:
+		desc.Buckets, desc.Quantiles = nil, nil
+	default:
+		return desc, errors.New("unknown metric kind " + string(desc.Kind))
+	}
+	return desc, nil
+}
+
+func sameDesc(a, b Desc) bool {
+	return a.Kind == b.Kind && a.Name == b.Name && a.Help == b.Help &&
+		equalSlices(a.LabelNames, b.LabelNames) && equalSlices(a.Buckets, b.Buckets) && equalSlices(a.Quantiles, b.Quantiles)
+}
+
+func equalSlices[T comparable](a, b []T) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// Gather returns a snapshot of every family, sorted by name, with series
+// sorted by label values
+func (r *Registry) Gather() []Family {
+	r.mu.RLock()
+	collectors := make([]Collector, 0, len(r.collectors))
+	for _, c := range r.collectors {
+		collectors = append(collectors, c)
+	}
+	r.mu.RUnlock()
+	for _, c := range collectors {
+		c.Collect()
+	}
+
+	r.mu.RLock()
+	families := make([]*family, 0, len(r.families))
+	for _, f := range r.families {
+		families = append(families, f)
+	}
+	r.mu.RUnlock()
+	sort.Slice(families, func(i, j int) bool { return families[i].desc.Name < families[j].desc.Name })
+
+	out := make([]Family, 0, len(families))
+	for _, f := range families {
+		out = append(out, f.snapshot())
+	}
+	return out
+}
+
+// family is a registered metric and its series
+type family struct {
+	desc Desc
+	reg  *Registry
+
+	mu       sync.RWMutex
+	byKey    map[string]*series
+	overflow *series
+}
+
+// series returns the series for values, creating it if needed; once the
+// family holds its maximum number of series, new label sets share the
+// overflow series
+func (f *family) series(values []string) *series {
+	key := strings.Join(values, "\xff")
+	f.mu.RLock()
+	s, ok := f.byKey[key]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+
+	f.mu.Lock()
+	if s, ok = f.byKey[key]; ok {
+		f.mu.Unlock()
+		return s
+	}
+	if int64(len(f.byKey)) < f.reg.maxSeries.Load() {
+		s = newSeries(f.desc, append([]string(nil), values...), f.reg.exponential.Load())
+		f.byKey[key] = s
+		f.mu.Unlock()
+		return s
+	}
+	if f.overflow == nil {
+		overflow := make([]string, len(values))
+		for i := range overflow {
+			overflow[i] = OverflowLabelValue
+		}
+		f.overflow = newSeries(f.desc, overflow, f.reg.exponential.Load())
+	}
+	s = f.overflow
+	f.mu.Unlock()
+
+	f.reg.overflowed(f.desc.Name)
+	return s
+}
+
+// checkValues panics unless values has one value per label name
+func (f *family) checkValues(values []string) {
+	if len(values) != len(f.desc.LabelNames) {
+		panic(fmt.Sprintf("metrics: %s takes %d label values %v, got %d", f.desc.Name, len(f.desc.LabelNames), f.desc.LabelNames, len(values)))
+	}
+}
+
+func (f *family) snapshot() Family {
+	f.mu.RLock()
+	all := make([]*series, 0, len(f.byKey)+1)
+	for _, s := range f.byKey {
+		all = append(all, s)
+	}
+	if f.overflow != nil {
+		all = append(all, f.overflow)
+	}
+	f.mu.RUnlock()
+
+	out := Family{Desc: f.desc, Series: make([]Series, 0, len(all))}
+	for _, s := range all {
+		out.Series = append(out.Series, s.snapshot(f.desc))
+	}
+	sort.Slice(out.Series, func(i, j int) bool {
+		return strings.Join(out.Series[i].LabelValues, "\xff") < strings.Join(out.Series[j].LabelValues, "\xff")
+	})
+	return out
+}
+
+// series holds the aggregated samples of one label set
+type series struct {
+	labelValues []string
+	start       time.Time
+
+	mu      sync.Mutex
+	value   float64
+	count   uint64
+	sum     float64
+	buckets []uint64
+	window  []float64
+	next    int
+	expo    *expoHistogram
+
+	exemplar        *Exemplar
+	bucketExemplars []*Exemplar
+}
+
+// newSeries creates a series of desc; histograms get exponential buckets
+// only when exponential is set
+func newSeries(desc Desc, values []string, exponential bool) *series {
+	s := &series{labelValues: values, start: time.Now()}
+	if desc.Kind == KindHistogram {
+		s.buckets = make([]uint64, len(desc.Buckets)+1)
+		if exponential {
+			s.expo = newExpoHistogram()
+		}
+	}
+	return s
+}
+
+func (s *series) add(v float64) {
+	s.mu.Lock()
+	s.value += v
+	s.mu.Unlock()
+}
+
+// addExemplar adds v, keeping ex as the latest exemplar unless it is nil
+func (s *series) addExemplar(v float64, ex *Exemplar) {
+	s.mu.Lock()
+	s.value += v
+	if ex != nil {
+		s.exemplar = ex
+	}
+	s.mu.Unlock()
+}
+
+func (s *series) set(v float64) {
+	s.mu.Lock()
+	s.value = v
+	s.mu.Unlock()
+}
+
+// observe records v in the histogram buckets, keeping ex as the latest
+// exemplar of its bucket unless it is nil, or in the summary window
+func (s *series) observe(v float64, ex *Exemplar, bounds ...float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.count++
+	s.sum += v
+	if s.buckets != nil {
+		i := sort.SearchFloat64s(bounds, v)
+		s.buckets[i]++
+		if ex != nil {
+			if s.bucketExemplars == nil {
+				s.bucketExemplars = make([]*Exemplar, len(s.buckets))
+			}
+			s.bucketExemplars[i] = ex
+		}
+		if s.expo != nil {
+			s.expo.observe(v)
+		}
+		return
+	}
+	if len(s.window) < summaryWindow {
+		s.window = append(s.window, v)
+		return
+	}
+	s.window[s.next] = v
+	s.next = (s.next + 1) % summaryWindow
+}
+
+// setHistogram replaces the histogram state, for collectors converting
+// histograms kept elsewhere
+func (s *series) setHistogram(buckets []uint64, sum float64, count uint64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	copy(s.buckets, buckets)
+	s.sum = sum
+	s.count = count
+	s.expo = nil
+}
+
+func (s *series) snapshot(desc Desc) Series {
+	s.mu.Lock()
+	out := Series{
+		LabelValues:  s.labelValues,
+		Value:        s.value,
+		Count:        s.count,
+		Sum:          s.sum,
+		BucketCounts: append([]uint64(nil), s.buckets...),
+		Start:        s.start,
+		Exemplar:     s.exemplar,
+	}
+	if s.bucketExemplars != nil {
+		out.BucketExemplars = append([]*Exemplar(nil), s.bucketExemplars...)
+	}
+	if s.expo != nil {
+		out.Exponential = s.expo.snapshot()
+	}
+	window := append([]float64(nil), s.window...)
+	s.mu.Unlock()
+
+	if desc.Kind == KindSummary {
+		sort.Float64s(window)
+		out.QuantileValues = make([]float64, len(desc.Quantiles))
+		for i, q := range desc.Quantiles {
+			out.QuantileValues[i] = quantile(window, q)
+		}
+	}
+	return out
+}
+
+// quantile returns the nearest-rank q-quantile of sorted, or NaN if it is empty
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return math.NaN()
+	}
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -0,0 +1,127 @@
+package metrics
+
+import (
+	"math"
+	goruntime "runtime"
+	rtmetrics "runtime/metrics"
+	"sort"
+)
+
+// runtimeBuckets are the histogram buckets for runtime latencies: 1µs to about 4s
+var runtimeBuckets = ExponentialBuckets(1e-6, 4, 12)
+
+// runtimeValues maps runtime/metrics samples to gauges and counters
+var runtimeValues = []struct {
+	name, help, sample string
+	counter            bool
+}{
+	{"go_goroutines", "Number of live goroutines.", "/sched/goroutines:goroutines", false},
+	{"go_gomaxprocs", "Value of GOMAXPROCS.", "/sched/gomaxprocs:threads", false},
+	{"go_heap_objects", "Number of live or unswept objects on the heap.", "/gc/heap/objects:objects", false},
+	{"go_heap_objects_bytes", "Memory occupied by live or unswept heap objects.", "/memory/classes/heap/objects:bytes", false},
+	{"go_heap_goal_bytes", "Heap size target for the end of the GC cycle.", "/gc/heap/goal:bytes", false},
+	{"go_memory_total_bytes", "Memory mapped by the Go runtime.", "/memory/classes/total:bytes", false},
+	{"go_heap_allocs_bytes_total", "Cumulative bytes allocated on the heap.", "/gc/heap/allocs:bytes", true},
+	{"go_gc_cycles_total", "Completed GC cycles.", "/gc/cycles/total:gc-cycles", true},
+}
+
+// runtimeHistograms maps runtime/metrics histograms to histograms
+var runtimeHistograms = []struct {
+	name, help, sample string
+}{
+	{"go_gc_pause_seconds", "Stop-the-world pauses caused by the GC.", "/sched/pauses/total/gc:seconds"},
+	{"go_sched_latency_seconds", "Time goroutines spent runnable before running.", "/sched/latencies:seconds"},
+}
+
+// runtimeCollector reads runtime/metrics on every gather
+type runtimeCollector struct {
+	samples    []rtmetrics.Sample
+	values     []*series
+	histograms []*series
+}
+
+// NewRuntimeCollector registers Go runtime metrics in r: goroutines,
+// GOMAXPROCS, heap and GC statistics, and GC pause and scheduler latency
+// histograms. Add it with r.RegisterCollector.
+func NewRuntimeCollector(r *Registry) Collector {
+	supported := make(map[string]bool)
+	for _, d := range rtmetrics.All() {
+		supported[d.Name] = true
+	}
+
+	c := &runtimeCollector{}
+	for _, v := range runtimeValues {
+		if !supported[v.sample] {
+			continue
+		}
+		kind := KindGauge
+		if v.counter {
+			kind = KindCounter
+		}
+		f := r.mustRegister(Desc{Kind: kind, Name: v.name, Help: v.help})
+		c.samples = append(c.samples, rtmetrics.Sample{Name: v.sample})
+		c.values = append(c.values, f.series(nil))
+	}
+	for _, h := range runtimeHistograms {
+		if !supported[h.sample] {
+			continue
+		}
+		f := r.mustRegister(Desc{Kind: KindHistogram, Name: h.name, Help: h.help, Buckets: runtimeBuckets})
+		c.samples = append(c.samples, rtmetrics.Sample{Name: h.sample})
+		c.histograms = append(c.histograms, f.series(nil))
+	}
+	return c
+}
+
+func (c *runtimeCollector) Collect() {
+	samples := append([]rtmetrics.Sample(nil), c.samples...)
+	rtmetrics.Read(samples)
+
+	for i, s := range c.values {
+		switch v := samples[i].Value; v.Kind() {
+		case rtmetrics.KindUint64:
+			s.set(float64(v.Uint64()))
+		case rtmetrics.KindFloat64:
+			s.set(v.Float64())
+		}
+	}
+	for i, s := range c.histograms {
+		v := samples[len(c.values)+i].Value
+		if v.Kind() == rtmetrics.KindFloat64Histogram {
+			s.setHistogram(rebucket(v.Float64Histogram(), runtimeBuckets))
+		}
+	}
+}
+
+// rebucket converts a runtime histogram to counts per bucket of bounds, with
+// a final +Inf bucket. Each runtime bucket is counted under the first bound at
+// or above its upper edge; the sum is estimated from bucket midpoints.
+func rebucket(h *rtmetrics.Float64Histogram, bounds []float64) ([]uint64, float64, uint64) {
+	counts := make([]uint64, len(bounds)+1)
+	var sum float64
+	var total uint64
+	for i, n := range h.Counts {
+		if n == 0 {
+			continue
+		}
+		lo, hi := h.Buckets[i], h.Buckets[i+1]
+		counts[sort.SearchFloat64s(bounds, hi)] += n
+		total += n
+
+		mid := (lo + hi) / 2
+		switch {
+		case math.IsInf(hi, 1):
+			mid = lo
+		case math.IsInf(lo, -1):
+			mid = hi
+		}
+		sum += mid * float64(n)
+	}
+	return counts, sum, total
+}
+
+// RegisterBuildInfo sets app_build_info{name,version,go_version} to 1 in r
+func RegisterBuildInfo(r *Registry, name, version string) {
+	r.NewGauge("app_build_info", "Build information of the app, always 1.", "name", "version", "go_version").
+		With(name, version, goruntime.Version()).Set(1)
+}
@@ -0,0 +1,32 @@
+package foundation
+
+import (
+	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// Option configures an App beyond what AppConfig covers
+type Option func(*options)
+
+type options struct {
+	logger  logging.Logger
+	metrics metrics.Metrics
+	tracer  tracing.Tracer
+}
+
+// WithLogger replaces the logger built from LoggerConfig; the app still adds
+// its service and version to every line
+func WithLogger(logger logging.Logger) Option {
+	return func(o *options) { o.logger = logger }
+}
+
+// WithMetrics replaces the metrics built from MetricsConfig
+func WithMetrics(m metrics.Metrics) Option {
+	return func(o *options) { o.metrics = m }
+}
+
+// WithTracer replaces the tracer built from TracerConfig; span tags are still redacted
+func WithTracer(tracer tracing.Tracer) Option {
+	return func(o *options) { o.tracer = tracer }
+}
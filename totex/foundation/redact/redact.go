@@ -0,0 +1,206 @@
+package redact
+
+import (
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Mask replaces redacted values
+const Mask = "[REDACTED]"
+
+// SensitiveOption is the full name of the protobuf field option that marks a
+// field as sensitive, defined in schema/foundation/v1/options.proto
+const SensitiveOption protoreflect.FullName = "foundation.v1.sensitive"
+
+// DefaultKeys are the attribute keys treated as sensitive unless configured otherwise
+var DefaultKeys = []string{
+	"password", "passwd", "secret", "token", "api_key", "apikey",
+	"authorization", "cookie", "set_cookie", "private_key", "credentials",
+}
+
+// Redactor masks sensitive values. A key is sensitive if, ignoring case,
+// underscores and dashes, it ends with one of the configured keys, so
+// "password" also covers "new_password" and "DB-Password".
+type Redactor struct {
+	keys []string
+	text *regexp.Regexp
+}
+
+// New creates a Redactor for the given sensitive keys
+func New(keys ...string) *Redactor {
+	r := &Redactor{}
+	var patterns []string
+	for _, key := range keys {
+		if k := normalize(key); k != "" {
+			r.keys = append(r.keys, k)
+			patterns = append(patterns, keyPattern(k))
+		}
+	}
+	if len(patterns) > 0 {
+		// key=value, key: value and "key":"value" pairs in free text
+		r.text = regexp.MustCompile(`(?i)([\w-]*(?:` + strings.Join(patterns, "|") + `)"?\s*[=:]\s*"?(?:(?:bearer|basic)\s+)?)([^\s"&,;]+)`)
+	}
+	return r
+}
+
+// Default returns a Redactor for DefaultKeys
+func Default() *Redactor { return New(DefaultKeys...) }
+
+// Sensitive reports whether values under key must be masked
+func (r *Redactor) Sensitive(key string) bool {
+	if r == nil {
+		return false
+	}
+	k := normalize(key)
+	for _, sensitive := range r.keys {
+		if strings.HasSuffix(k, sensitive) {
+			return true
+		}
+	}
+	return false
+}
+
+// Value returns v as it may be recorded under key: masked if key is
+// sensitive, with sensitive fields masked if v is a protobuf message, and
+// with key=value pairs masked in error messages
+func (r *Redactor) Value(key string, v any) any {
+	if r == nil {
+		return v
+	}
+	if r.Sensitive(key) {
+		return Mask
+	}
+	switch v := v.(type) {
+	case proto.Message:
+		return r.Message(v)
+	case error:
+		if s := r.String(v.Error()); s != v.Error() {
+			return s
+		}
+	}
+	return v
+}
+
+// String masks the values of sensitive key=value, key: value and "key":"value"
+// pairs in free text such as error messages and URLs
+func (r *Redactor) String(s string) string {
+	if r == nil || r.text == nil {
+		return s
+	}
+	return r.text.ReplaceAllString(s, "${1}"+Mask)
+}
+
+// Message returns a copy of m with sensitive fields masked: string fields are
+// replaced by Mask and other fields cleared. A field is sensitive if it
+// carries the foundation.v1.sensitive option or its name is a sensitive key.
+// m is returned as is if it has no sensitive fields set.
+func (r *Redactor) Message(m proto.Message) proto.Message {
+	if r == nil || m == nil || !m.ProtoReflect().IsValid() {
+		return m
+	}
+	if !r.needsRedaction(m.ProtoReflect()) {
+		return m
+	}
+	clone := proto.Clone(m)
+	r.redact(clone.ProtoReflect())
+	return clone
+}
+
+// SensitiveField reports whether fd carries the foundation.v1.sensitive option
+func SensitiveField(fd protoreflect.FieldDescriptor) bool {
+	opts := fd.Options()
+	if opts == nil {
+		return false
+	}
+	sensitive := false
+	opts.ProtoReflect().Range(func(xd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if xd.IsExtension() && xd.FullName() == SensitiveOption {
+			sensitive = v.Bool()
+			return false
+		}
+		return true
+	})
+	return sensitive
+}
+
+func (r *Redactor) sensitiveField(fd protoreflect.FieldDescriptor) bool {
+	return SensitiveField(fd) || r.Sensitive(string(fd.Name()))
+}
+
+func (r *Redactor) needsRedaction(m protoreflect.Message) bool {
+	found := false
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		if r.sensitiveField(fd) {
+			found = true
+		} else {
+			eachMessage(fd, v, func(child protoreflect.Message) {
+				found = found || r.needsRedaction(child)
+			})
+		}
+		return !found
+	})
+	return found
+}
+
+func (r *Redactor) redact(m protoreflect.Message) {
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		switch {
+		case !r.sensitiveField(fd):
+			eachMessage(fd, v, r.redact)
+		case fd.Kind() == protoreflect.StringKind && !fd.IsList() && !fd.IsMap():
+			m.Set(fd, protoreflect.ValueOfString(Mask))
+		default:
+			m.Clear(fd)
+		}
+		return true
+	})
+}
+
+// eachMessage calls fn for every message held by the field fd with value v
+func eachMessage(fd protoreflect.FieldDescriptor, v protoreflect.Value, fn func(protoreflect.Message)) {
+	switch {
+	case fd.IsMap():
+		if fd.MapValue().Message() == nil {
+			return
+		}
+		v.Map().Range(func(_ protoreflect.MapKey, mv protoreflect.Value) bool {
+			fn(mv.Message())
+			return true
+		})
+	case fd.IsList():
+		if fd.Message() == nil {
+			return
+		}
+		list := v.List()
+		for i := 0; i < list.Len(); i++ {
+			fn(list.Get(i).Message())
+		}
+	case fd.Message() != nil:
+		fn(v.Message())
+	}
+}
+
+// normalize lowercases key and drops underscores and dashes
+func normalize(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '_' || r == '-' {
+			return -1
+		}
+		return r
+	}, strings.ToLower(key))
+}
+
+// keyPattern matches a normalized key with optional underscores or dashes between its letters
+func keyPattern(key string) string {
+	var b strings.Builder
+	for i, r := range key {
+		if i > 0 {
+			b.WriteString("[_-]?")
+		}
+		b.WriteString(regexp.QuoteMeta(string(r)))
+	}
+	return b.String()
+}
@@ -0,0 +1,106 @@
+package redact
+
+import (
+	"errors"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/yourusername/foundation/internal/testpb"
+)
+
+func TestSensitive(t *testing.T) {
+	r := New("password", "api_key")
+	tests := map[string]bool{
+		"password":     true,
+		"new_password": true,
+		"DB-Password":  true,
+		"apiKey":       true,
+		"X-API-KEY":    true,
+		"password_set": false,
+		"user":         false,
+		"":             false,
+	}
+	for key, want := range tests {
+		if got := r.Sensitive(key); got != want {
+			t.Errorf("Sensitive(%q) = %v, want %v", key, got, want)
+		}
+	}
+	var none *Redactor
+	if none.Sensitive("password") {
+		t.Error("a nil Redactor found a sensitive key")
+	}
+}
+
+func TestString(t *testing.T) {
+	r := Default()
+	tests := []struct{ in, want string }{
+		{"login failed: password=hunter2 user=ada", "login failed: password=[REDACTED] user=ada"},
+		{"dial postgres://db?user=ada&db_password=hunter2&ssl=on", "dial postgres://db?user=ada&db_password=[REDACTED]&ssl=on"},
+		{`{"api_key":"abc123","user":"ada"}`, `{"api_key":"[REDACTED]","user":"ada"}`},
+		{"Authorization: Bearer eyJhbGci.x.y", "Authorization: Bearer [REDACTED]"},
+		{"secret: s3cr3t, next", "secret: [REDACTED], next"},
+		{"no secrets here", "no secrets here"},
+	}
+	for _, tt := range tests {
+		if got := r.String(tt.in); got != tt.want {
+			t.Errorf("String(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+	if got := New().String("password=x"); got != "password=x" {
+		t.Errorf("a Redactor without keys masked %q", got)
+	}
+}
+
+func TestValue(t *testing.T) {
+	r := Default()
+	if got := r.Value("token", "abc"); got != Mask {
+		t.Errorf("Value under a sensitive key = %v", got)
+	}
+	if got := r.Value("user", "ada"); got != "ada" {
+		t.Errorf("Value under another key = %v", got)
+	}
+	err := errors.New("connect: password=hunter2")
+	if got := r.Value("error", err); got != "connect: password=[REDACTED]" {
+		t.Errorf("Value of an error = %v", got)
+	}
+	plain := errors.New("not found")
+	if got := r.Value("error", plain); got != plain {
+		t.Errorf("Value of an error without secrets = %v, want the error itself", got)
+	}
+	var none *Redactor
+	if got := none.Value("password", "hunter2"); got != "hunter2" {
+		t.Errorf("a nil Redactor masked %v", got)
+	}
+}
+
+func TestMessage(t *testing.T) {
+	// The fields marked foundation.v1.sensitive are masked even when their
+	// names are not configured keys
+	r := New("token")
+	referrer := testpb.Account("grace", "s3cr3t", 4321, nil)
+	m := testpb.Account("ada", "hunter2", 1234, referrer)
+
+	got := r.Message(m)
+	if testpb.Field(got, "name") != "ada" || testpb.Field(got, "password") != Mask || testpb.Field(got, "pin") != int64(0) {
+		t.Errorf("redacted message = %v", got)
+	}
+	ref := testpb.Field(got, "referrer").(proto.Message)
+	if testpb.Field(ref, "name") != "grace" || testpb.Field(ref, "password") != Mask {
+		t.Errorf("nested message not redacted: %v", ref)
+	}
+
+	// The original is left alone
+	if testpb.Field(m, "password") != "hunter2" || testpb.Field(referrer, "password") != "s3cr3t" {
+		t.Errorf("Message modified the original: %v", m)
+	}
+
+	// Messages without sensitive values are returned as is
+	clean := testpb.Account("ada", "", 0, nil)
+	if r.Message(clean) != clean {
+		t.Error("a message without sensitive values was copied")
+	}
+	if r.Value("request", m) == m {
+		t.Error("Value did not redact a message")
+	}
+}
@@ -0,0 +1,264 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule computes when a job runs next
+type Schedule interface {
+	// Next returns the first activation strictly after t, or the zero time if there is none
+	Next(t time.Time) time.Time
+}
+
+// intervalSchedule runs at a fixed interval
+type intervalSchedule struct {
+	interval time.Duration
+}
+
+// Every returns a Schedule that fires at a fixed interval
+func Every(interval time.Duration) Schedule {
+	return intervalSchedule{interval: interval}
+}
+
+func (s intervalSchedule) Next(t time.Time) time.Time {
+	return t.Add(s.interval)
+}
+
+// cronSchedule is a parsed five-field cron expression; each field is a bitset
+type cronSchedule struct {
+	minute, hour, dom, month, dow uint64
+	// domStar and dowStar record unrestricted day fields, since cron matches
+	// either day field when both are restricted
+	domStar, dowStar bool
+	loc              *time.Location
+}
+
+type cronField struct {
+	min, max int
+	names    map[string]int
+}
+
+var (
+	minuteField = cronField{min: 0, max: 59}
+	hourField   = cronField{min: 0, max: 23}
+	domField    = cronField{min: 1, max: 31}
+	monthField  = cronField{min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}}
+	dowField = cronField{min: 0, max: 6, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}}
+)
+
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// Cron parses a standard five-field cron expression (minute hour day-of-month
+// month day-of-week), or one of @yearly, @monthly, @weekly, @daily, @hourly or
+// "@every <duration>". Times are evaluated in the local time zone; times a
+// DST change skips do not fire, and those it repeats fire once unless the job
+// runs every hour.
+func Cron(expr string) (Schedule, error) {
+	return CronIn(expr, time.Local)
+}
+
+// CronIn is like Cron but evaluates the expression in loc
+func CronIn(expr string, loc *time.Location) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		d, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid interval in %q", expr)
+		}
+		return Every(d), nil
+	}
+	if full, ok := descriptors[expr]; ok {
+		expr = full
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron expression %q must have 5 fields, got %d", expr, len(fields))
+	}
+
+	s := &cronSchedule{loc: loc}
+	var err error
+	if s.minute, _, err = parseField(fields[0], minuteField); err != nil {
+		return nil, fmt.Errorf("minute: %w", err)
+	}
+	if s.hour, _, err = parseField(fields[1], hourField); err != nil {
+		return nil, fmt.Errorf("hour: %w", err)
+	}
+	if s.dom, s.domStar, err = parseField(fields[2], domField); err != nil {
+		return nil, fmt.Errorf("day of month: %w", err)
+	}
+	if s.month, _, err = parseField(fields[3], monthField); err != nil {
+		return nil, fmt.Errorf("month: %w", err)
+	}
+	// Accept 7 as Sunday
+	dow := cronField{min: 0, max: 7, names: dowField.names}
+	if s.dow, s.dowStar, err = parseField(fields[4], dow); err != nil {
+		return nil, fmt.Errorf("day of week: %w", err)
+	}
+	if s.dow&(1<<7) != 0 {
+		s.dow |= 1
+	}
+	return s, nil
+}
+
+// parseField parses a comma-separated list of "*", "a", "a-b" with optional "/step"
+func parseField(field string, f cronField) (uint64, bool, error) {
+	var bits uint64
+	star := false
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepStr); err != nil || step <= 0 {
+				return 0, false, fmt.Errorf("invalid step in %q", part)
+			}
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = f.min, f.max
+			star = star || !hasStep
+		case strings.Contains(rng, "-"):
+			a, b, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = f.value(a); err != nil {
+				return 0, false, err
+			}
+			if hi, err = f.value(b); err != nil {
+				return 0, false, err
+			}
+		default:
+			var err error
+			if lo, err = f.value(rng); err != nil {
+				return 0, false, err
+			}
+			hi = lo
+			if hasStep {
+				hi = f.max
+			}
+		}
+		if lo > hi {
+			return 0, false, fmt.Errorf("invalid range %q", part)
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, star, nil
+}
+
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < f.min || v > f.max {
+		return 0, fmt.Errorf("value %d out of range [%d, %d]", v, f.min, f.max)
+	}
+	return v, nil
+}
+
+// allHours is the hour bitset of a schedule running every hour
+const allHours = 1<<24 - 1
+
+// Next walks forward field by field, from month down to minute, resetting the
+// smaller fields whenever a larger one advances. Wall clock times skipped when
+// clocks go forward do not fire; those repeated when clocks go back fire once,
+// unless the job runs every hour.
+func (s *cronSchedule) Next(t time.Time) time.Time {
+	origLoc := t.Location()
+	// Truncate in absolute time: rebuilding the wall clock time with
+	// time.Date could land on the other side of a DST transition
+	t = t.In(s.loc).Truncate(time.Minute).Add(time.Minute)
+	yearLimit := t.Year() + 5
+
+wrap:
+	if t.Year() > yearLimit {
+		return time.Time{}
+	}
+
+	for s.month&(1<<uint(t.Month())) == 0 {
+		year := t.Year()
+		t = after(t, time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, s.loc))
+		if t.Year() != year {
+			goto wrap
+		}
+	}
+	for !s.dayMatches(t) {
+		month := t.Month()
+		t = after(t, time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, s.loc))
+		if t.Month() != month {
+			goto wrap
+		}
+	}
+	for s.hour&(1<<uint(t.Hour())) == 0 {
+		day := t.Day()
+		t = after(t, time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, s.loc))
+		if t.Day() != day {
+			goto wrap
+		}
+	}
+	for s.minute&(1<<uint(t.Minute())) == 0 {
+		t = t.Add(time.Minute)
+		if t.Minute() == 0 {
+			goto wrap
+		}
+	}
+	if s.hour != allHours && repeated(t) {
+		t = t.Add(time.Minute)
+		goto wrap
+	}
+	return t.In(origLoc)
+}
+
+// after returns next, moved forward by whole hours if it is not after t. A
+// wall clock time that a DST transition skips may resolve to an earlier instant.
+func after(t, next time.Time) time.Time {
+	for !next.After(t) {
+		next = next.Add(time.Hour)
+	}
+	return next
+}
+
+// repeated reports whether the wall clock time of t occurred before, in the
+// hour repeated when clocks go back
+func repeated(t time.Time) bool {
+	_, offset := t.Zone()
+	_, earlier := t.Add(-3 * time.Hour).Zone()
+	shift := time.Duration(earlier-offset) * time.Second
+	if shift <= 0 {
+		return false
+	}
+	_, o := t.Add(-shift).Zone()
+	return o == earlier
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domStar || s.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,179 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronParse(t *testing.T) {
+	valid := []string{
+		"* * * * *",
+		"*/5 * * * *",
+		"0 9-17 * * mon-fri",
+		"0,30 * 1,15 jan,JUL *",
+		"5/10 * * * 7",
+		"@daily",
+		" @hourly ",
+		"@every 30s",
+	}
+	for _, expr := range valid {
+		if _, err := CronIn(expr, time.UTC); err != nil {
+			t.Errorf("Cron(%q): %v", expr, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"*/x * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"* * * foo *",
+		"@every",
+		"@every -1s",
+		"@every soon",
+		"@fortnightly",
+	}
+	for _, expr := range invalid {
+		if _, err := CronIn(expr, time.UTC); err == nil {
+			t.Errorf("Cron(%q) succeeded, want an error", expr)
+		}
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, min, sec int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, 0, time.UTC)
+	}
+	tests := []struct {
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"*/15 * * * *", at(2024, 5, 1, 10, 7, 45), at(2024, 5, 1, 10, 15, 0)},
+		{"*/15 * * * *", at(2024, 5, 1, 10, 15, 0), at(2024, 5, 1, 10, 30, 0)}, // strictly after
+		{"5/20 * * * *", at(2024, 5, 1, 10, 45, 0), at(2024, 5, 1, 11, 5, 0)},
+		{"0 0 * * *", at(2024, 5, 1, 23, 59, 30), at(2024, 5, 2, 0, 0, 0)},
+		{"@hourly", at(2024, 5, 1, 10, 0, 0), at(2024, 5, 1, 11, 0, 0)},
+		{"@yearly", at(2024, 5, 1, 10, 0, 0), at(2025, 1, 1, 0, 0, 0)},
+		{"0 0 31 * *", at(2024, 4, 1, 0, 0, 0), at(2024, 5, 31, 0, 0, 0)},
+		{"30 9 * * mon-fri", at(2024, 5, 3, 10, 0, 0), at(2024, 5, 6, 9, 30, 0)}, // Friday to Monday
+		{"0 0 * * 7", at(2024, 5, 1, 0, 0, 0), at(2024, 5, 5, 0, 0, 0)},          // 7 is Sunday
+		{"0 0 * * sun", at(2024, 5, 1, 0, 0, 0), at(2024, 5, 5, 0, 0, 0)},
+		{"0 0 29 2 *", at(2024, 3, 1, 0, 0, 0), at(2028, 2, 29, 0, 0, 0)},
+		{"0 0 30 2 *", at(2024, 1, 1, 0, 0, 0), time.Time{}}, // never
+		{"0 0 1 */3 *", at(2024, 5, 1, 0, 0, 0), at(2024, 7, 1, 0, 0, 0)},
+
+		// With both day fields restricted either may match: the 13th or a Friday
+		{"0 12 13 * fri", at(2024, 5, 1, 0, 0, 0), at(2024, 5, 3, 12, 0, 0)},
+		{"0 12 13 * fri", at(2024, 5, 10, 12, 0, 0), at(2024, 5, 13, 12, 0, 0)},
+		// With one of them *, only the other restricts the day
+		{"0 12 13 * *", at(2024, 5, 1, 0, 0, 0), at(2024, 5, 13, 12, 0, 0)},
+		{"0 12 * * fri", at(2024, 5, 1, 0, 0, 0), at(2024, 5, 3, 12, 0, 0)},
+		// A stepped * restricts the field
+		{"0 12 */10 * fri", at(2024, 5, 4, 0, 0, 0), at(2024, 5, 10, 12, 0, 0)},
+	}
+	for _, tt := range tests {
+		s, err := CronIn(tt.expr, time.UTC)
+		if err != nil {
+			t.Fatalf("Cron(%q): %v", tt.expr, err)
+		}
+		if got := s.Next(tt.from); !got.Equal(tt.want) {
+			t.Errorf("%q.Next(%v) = %v, want %v", tt.expr, tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestCronNextKeepsLocation(t *testing.T) {
+	s, _ := CronIn("0 12 * * *", time.UTC)
+	tokyo := time.FixedZone("JST", 9*60*60)
+	got := s.Next(time.Date(2024, 5, 1, 0, 0, 0, 0, tokyo))
+	if got.Location() != tokyo || !got.Equal(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)) {
+		t.Errorf("Next = %v, want 12:00 UTC in the caller's zone", got)
+	}
+}
+
+// fires returns the activations of expr in loc from from until to
+func fires(t *testing.T, expr string, loc *time.Location, from, to time.Time) []string {
+	t.Helper()
+	s, err := CronIn(expr, loc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for next := s.Next(from); next.Before(to); next = s.Next(next) {
+		if len(out) > 100 {
+			t.Fatalf("%q fires too often: %v", expr, out)
+		}
+		out = append(out, next.Format("01-02 15:04 MST"))
+	}
+	return out
+}
+
+func TestCronNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("time zone database not available:", err)
+	}
+	tests := []struct {
+		name     string
+		expr     string
+		from, to time.Time
+		want     []string
+	}{
+		{
+			// 02:30 does not exist on March 10, so that day has no run
+			"spring forward skipped time", "30 2 * * *",
+			time.Date(2024, 3, 9, 0, 0, 0, 0, ny), time.Date(2024, 3, 12, 0, 0, 0, 0, ny),
+			[]string{"03-09 02:30 EST", "03-11 02:30 EDT"},
+		},
+		{
+			"spring forward hourly", "30 * * * *",
+			time.Date(2024, 3, 10, 0, 0, 0, 0, ny), time.Date(2024, 3, 10, 4, 0, 0, 0, ny),
+			[]string{"03-10 00:30 EST", "03-10 01:30 EST", "03-10 03:30 EDT"},
+		},
+		{
+			// 01:30 happens twice on November 3, and the job runs once
+			"fall back repeated time", "30 1 * * *",
+			time.Date(2024, 11, 2, 0, 0, 0, 0, ny), time.Date(2024, 11, 5, 0, 0, 0, 0, ny),
+			[]string{"11-02 01:30 EDT", "11-03 01:30 EDT", "11-04 01:30 EST"},
+		},
+		{
+			// A job running every hour runs in both copies of the repeated hour
+			"fall back hourly", "30 * * * *",
+			time.Date(2024, 11, 3, 0, 0, 0, 0, ny), time.Date(2024, 11, 3, 3, 0, 0, 0, ny),
+			[]string{"11-03 00:30 EDT", "11-03 01:30 EDT", "11-03 01:30 EST", "11-03 02:30 EST"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fires(t, tt.expr, ny, tt.from, tt.to)
+			if len(got) != len(tt.want) {
+				t.Fatalf("fires at %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("fires at %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestEvery(t *testing.T) {
+	s, err := CronIn("@every 90s", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from := time.Date(2024, 5, 1, 10, 0, 7, 0, time.UTC)
+	if got := s.Next(from); !got.Equal(from.Add(90 * time.Second)) {
+		t.Errorf("Next = %v, want 90s later", got)
+	}
+}
@@ -0,0 +1,308 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/yourusername/foundation/logging"
+	"github.com/yourusername/foundation/metrics"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// Job is a unit of scheduled work
+type Job func(ctx context.Context) error
+
+// OverlapPolicy decides what happens when a job is due while a previous run is still going
+type OverlapPolicy int
+
+const (
+	// OverlapSkip drops the new run
+	OverlapSkip OverlapPolicy = iota
+	// OverlapQueue runs the new run after the current one finishes
+	OverlapQueue
+	// OverlapConcurrent runs the new run alongside the current one
+	OverlapConcurrent
+)
+
+// maxQueued bounds how many runs an OverlapQueue job can have waiting
+const maxQueued = 1
+
+// JobOption configures a scheduled job
+type JobOption func(*job)
+
+// WithJitter delays each run by a random duration in [0, jitter)
+func WithJitter(jitter time.Duration) JobOption {
+	return func(j *job) { j.jitter = jitter }
+}
+
+// WithOverlap sets the overlap policy; the default is OverlapSkip
+func WithOverlap(policy OverlapPolicy) JobOption {
+	return func(j *job) { j.overlap = policy }
+}
+
+// WithTimeout bounds each run of the job
+func WithTimeout(timeout time.Duration) JobOption {
+	return func(j *job) { j.timeout = timeout }
+}
+
+// job is a registered job and its run state
+type job struct {
+	name     string
+	schedule Schedule
+	fn       Job
+	jitter   time.Duration
+	overlap  OverlapPolicy
+	timeout  time.Duration
+
+	running atomic.Bool
+	queue   chan struct{}
+}
+
+// Scheduler runs jobs on cron expressions or fixed intervals. It implements
+// foundation.Component, so adding it to an App starts it with the App and
+// stops it, waiting for running jobs, on Stop.
+type Scheduler struct {
+	logger    logging.Logger
+	tracer    tracing.Tracer
+	metrics   metrics.Metrics
+	dependsOn []string
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+	started bool
+}
+
+// New creates a Scheduler. dependsOn names the App components the jobs need,
+// so the scheduler is started after and stopped before them.
+func New(logger logging.Logger, tracer tracing.Tracer, m metrics.Metrics, dependsOn ...string) *Scheduler {
+	return &Scheduler{
+		logger:    logger,
+		tracer:    tracer,
+		metrics:   m,
+		dependsOn: dependsOn,
+		jobs:      make(map[string]*job),
+	}
+}
+
+// Cron registers a job on a cron expression
+func (s *Scheduler) Cron(name, expr string, fn Job, opts ...JobOption) error {
+	schedule, err := Cron(expr)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", name, err)
+	}
+	return s.Add(name, schedule, fn, opts...)
+}
+
+// Every registers a job on a fixed interval
+func (s *Scheduler) Every(name string, interval time.Duration, fn Job, opts ...JobOption) error {
+	if interval <= 0 {
+		return fmt.Errorf("job %s: interval must be positive", name)
+	}
+	return s.Add(name, Every(interval), fn, opts...)
+}
+
+// Add registers a job on an arbitrary schedule. Jobs added after the
+// scheduler started are scheduled immediately.
+func (s *Scheduler) Add(name string, schedule Schedule, fn Job, opts ...JobOption) error {
+	j := &job{name: name, schedule: schedule, fn: fn, overlap: OverlapSkip}
+	for _, opt := range opts {
+		opt(j)
+	}
+	if j.overlap == OverlapQueue {
+		j.queue = make(chan struct{}, maxQueued)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, dup := s.jobs[name]; dup {
+		return fmt.Errorf("job %s already registered", name)
+	}
+	s.jobs[name] = j
+	if s.started {
+		s.launch(j)
+	}
+	return nil
+}
+
+// Name returns the component name
+func (s *Scheduler) Name() string { return "scheduler" }
+
+// DependsOn returns the components the scheduler's jobs need
+func (s *Scheduler) DependsOn() []string { return s.dependsOn }
+
+// OnStart starts scheduling all registered jobs
+func (s *Scheduler) OnStart(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started {
+		return errors.New("scheduler already started")
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.started = true
+	for _, j := range s.jobs {
+		s.launch(j)
+	}
+	s.logger.Info("Scheduler started", "jobs", len(s.jobs))
+	return nil
+}
+
+// OnStop stops scheduling and waits for running jobs until ctx is done
+func (s *Scheduler) OnStop(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.started {
+		s.mu.Unlock()
+		return nil
+	}
+	s.cancel()
+	s.started = false
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		s.logger.Info("Scheduler stopped")
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler jobs still running: %w", ctx.Err())
+	}
+}
+
+// launch starts the timer loop for j, plus the queue runner for queued jobs;
+// the caller holds s.mu
+func (s *Scheduler) launch(j *job) {
+	ctx := s.ctx
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		s.loop(ctx, j)
+	}()
+	if j.queue != nil {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-j.queue:
+					s.run(ctx, j)
+				}
+			}
+		}()
+	}
+}
+
+// loop waits for each activation of j's schedule and dispatches it
+func (s *Scheduler) loop(ctx context.Context, j *job) {
+	for {
+		now := time.Now()
+		next := j.schedule.Next(now)
+		if next.IsZero() {
+			s.logger.Warn("Job has no further activations", "job", j.name)
+			return
+		}
+		if j.jitter > 0 {
+			next = next.Add(rand.N(j.jitter))
+		}
+
+		timer := time.NewTimer(next.Sub(now))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+		s.dispatch(ctx, j)
+	}
+}
+
+// dispatch applies the overlap policy to a due run
+func (s *Scheduler) dispatch(ctx context.Context, j *job) {
+	switch j.overlap {
+	case OverlapQueue:
+		select {
+		case j.queue <- struct{}{}:
+		default:
+			s.skip(j)
+		}
+	case OverlapConcurrent:
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.run(ctx, j)
+		}()
+	default:
+		if !j.running.CompareAndSwap(false, true) {
+			s.skip(j)
+			return
+		}
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			defer j.running.Store(false)
+			s.run(ctx, j)
+		}()
+	}
+}
+
+func (s *Scheduler) skip(j *job) {
+	s.logger.Warn("Skipping job run, previous run still in progress", "job", j.name)
+	s.metrics.Counter("scheduler_job_skipped_total", 1, "job", j.name)
+}
+
+// run executes one run of j with its timeout, span and metrics
+func (s *Scheduler) run(ctx context.Context, j *job) {
+	if j.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, j.timeout)
+		defer cancel()
+	}
+
+	// Spans and context-aware logs of the job parent to its span
+	span, ctx := tracing.StartSpanFromContext(ctx, s.tracer, "scheduler.job")
+	span.SetTag("job", j.name)
+	defer span.Finish()
+
+	start := time.Now()
+	err := s.call(ctx, j)
+	duration := time.Since(start)
+
+	result := "success"
+	switch {
+	case err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result = "timeout"
+	case err != nil:
+		result = "failure"
+	}
+	s.metrics.Counter("scheduler_job_runs_total", 1, "job", j.name, "result", result)
+	s.metrics.Histogram("scheduler_job_duration_seconds", duration.Seconds(), "job", j.name)
+
+	if err != nil {
+		span.SetError(err)
+		s.logger.Error("Job failed", "job", j.name, "result", result, "duration", duration, "error", err)
+		return
+	}
+	s.logger.Debug("Job succeeded", "job", j.name, "duration", duration)
+}
+
+// call runs the job function, turning a panic into an error
+func (s *Scheduler) call(ctx context.Context, j *job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return j.fn(ctx)
+}
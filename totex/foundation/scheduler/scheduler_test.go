@@ -0,0 +1,258 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/foundationtest"
+	"github.com/yourusername/foundation/tracing"
+)
+
+// tick is the interval of the jobs under test
+const tick = 5 * time.Millisecond
+
+type testScheduler struct {
+	*Scheduler
+	logger  *foundationtest.Logger
+	tracer  *foundationtest.Tracer
+	metrics *foundationtest.Metrics
+}
+
+// startScheduler starts a scheduler with recording doubles, stopped at the end of the test
+func startScheduler(t *testing.T, register func(s *Scheduler)) *testScheduler {
+	t.Helper()
+	ts := &testScheduler{
+		logger:  foundationtest.NewLogger(),
+		tracer:  foundationtest.NewTracer(),
+		metrics: foundationtest.NewMetrics(),
+	}
+	ts.Scheduler = New(ts.logger, ts.tracer, ts.metrics)
+	register(ts.Scheduler)
+	if err := ts.OnStart(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ts.OnStop(context.Background()) })
+	return ts
+}
+
+// eventually polls cond until it holds or a second has passed
+func eventually(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// blockingJob counts its runs and their concurrency, blocking until released
+type blockingJob struct {
+	release       chan struct{}
+	runs, running atomic.Int64
+	maxRunning    atomic.Int64
+}
+
+func newBlockingJob() *blockingJob {
+	return &blockingJob{release: make(chan struct{})}
+}
+
+func (j *blockingJob) run(ctx context.Context) error {
+	j.runs.Add(1)
+	n := j.running.Add(1)
+	defer j.running.Add(-1)
+	for {
+		max := j.maxRunning.Load()
+		if n <= max || j.maxRunning.CompareAndSwap(max, n) {
+			break
+		}
+	}
+	select {
+	case <-j.release:
+	case <-ctx.Done():
+	}
+	return nil
+}
+
+func TestOverlapSkip(t *testing.T) {
+	job := newBlockingJob()
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("sync", tick, job.run)
+	})
+
+	eventually(t, "runs are skipped", func() bool {
+		return s.metrics.CounterValue("scheduler_job_skipped_total", "job", "sync") >= 3
+	})
+	if job.runs.Load() != 1 {
+		t.Errorf("%d runs while the first was blocked, want 1", job.runs.Load())
+	}
+	s.logger.AssertLogged(t, "Skipping job run, previous run still in progress", "job", "sync")
+
+	// Once the run finishes the job runs again
+	close(job.release)
+	eventually(t, "the job runs again", func() bool { return job.runs.Load() >= 2 })
+	if job.maxRunning.Load() != 1 {
+		t.Errorf("%d runs overlapped", job.maxRunning.Load())
+	}
+}
+
+func TestOverlapQueue(t *testing.T) {
+	job := newBlockingJob()
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("sync", tick, job.run, WithOverlap(OverlapQueue))
+	})
+
+	// One run waits behind the blocked one, the others are skipped
+	eventually(t, "runs are skipped", func() bool {
+		return s.metrics.CounterValue("scheduler_job_skipped_total", "job", "sync") >= 3
+	})
+	if job.runs.Load() != 1 {
+		t.Errorf("%d runs while the first was blocked, want 1", job.runs.Load())
+	}
+
+	job.release <- struct{}{}
+	// The queued run starts as soon as the first finishes
+	eventually(t, "the queued run starts", func() bool { return job.runs.Load() == 2 })
+	close(job.release)
+	if job.maxRunning.Load() != 1 {
+		t.Errorf("%d queued runs overlapped", job.maxRunning.Load())
+	}
+}
+
+func TestOverlapConcurrent(t *testing.T) {
+	job := newBlockingJob()
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("sync", tick, job.run, WithOverlap(OverlapConcurrent))
+	})
+
+	eventually(t, "runs overlap", func() bool { return job.running.Load() >= 3 })
+	close(job.release)
+	if got := s.metrics.CounterValue("scheduler_job_skipped_total"); got != 0 {
+		t.Errorf("%v runs skipped", got)
+	}
+}
+
+func TestJobTimeout(t *testing.T) {
+	done := make(chan struct{}, 1)
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("slow", tick, func(ctx context.Context) error {
+			<-ctx.Done()
+			select {
+			case done <- struct{}{}:
+			default:
+			}
+			return ctx.Err()
+		}, WithTimeout(10*time.Millisecond))
+	})
+
+	<-done
+	eventually(t, "the run is recorded", func() bool {
+		return s.metrics.CounterValue("scheduler_job_runs_total", "job", "slow", "result", "timeout") >= 1
+	})
+	span := s.tracer.AssertSpan(t, "scheduler.job", "job", "slow")
+	if !errors.Is(span.Err(), context.DeadlineExceeded) {
+		t.Errorf("span error = %v, want the deadline", span.Err())
+	}
+	s.logger.AssertLogged(t, "Job failed", "job", "slow", "result", "timeout")
+}
+
+func TestJobResults(t *testing.T) {
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("ok", tick, func(ctx context.Context) error { return nil })
+		s.Every("failing", tick, func(ctx context.Context) error { return errors.New("boom") })
+		s.Every("panicking", tick, func(ctx context.Context) error { panic("oops") })
+	})
+
+	eventually(t, "every job ran", func() bool {
+		return s.metrics.CounterValue("scheduler_job_runs_total", "job", "ok", "result", "success") >= 1 &&
+			s.metrics.CounterValue("scheduler_job_runs_total", "job", "failing", "result", "failure") >= 1 &&
+			s.metrics.CounterValue("scheduler_job_runs_total", "job", "panicking", "result", "failure") >= 1
+	})
+	s.logger.AssertLogged(t, "Job failed", "job", "panicking", "error", "panic: oops")
+	s.metrics.AssertObserved(t, "scheduler_job_duration_seconds", "job", "ok")
+}
+
+func TestJobSpanInContext(t *testing.T) {
+	children := make(chan struct{}, 1)
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("traced", tick, func(ctx context.Context) error {
+			span, _ := tracing.StartSpanFromContext(ctx, s.tracer, "db.query")
+			span.Finish()
+			select {
+			case children <- struct{}{}:
+			default:
+			}
+			return nil
+		})
+	})
+	<-children
+	eventually(t, "the job span finishes", func() bool { return s.tracer.Find("scheduler.job", "job", "traced") != nil })
+	s.tracer.AssertPath(t, "scheduler.job", "db.query")
+}
+
+func TestAddAfterStart(t *testing.T) {
+	s := startScheduler(t, func(s *Scheduler) {})
+	var runs atomic.Int64
+	if err := s.Every("late", tick, func(ctx context.Context) error { runs.Add(1); return nil }); err != nil {
+		t.Fatal(err)
+	}
+	eventually(t, "the late job runs", func() bool { return runs.Load() > 0 })
+
+	if err := s.Every("late", tick, func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("registered a duplicate job name")
+	}
+	if err := s.Every("never", 0, func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("registered a job with a zero interval")
+	}
+	if err := s.Cron("bad", "* * *", func(ctx context.Context) error { return nil }); err == nil {
+		t.Error("registered a job with an invalid cron expression")
+	}
+}
+
+func TestStopWaitsForRunningJobs(t *testing.T) {
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("drain", tick, func(ctx context.Context) error {
+			close(started)
+			<-ctx.Done()
+			time.Sleep(20 * time.Millisecond)
+			close(finished)
+			return nil
+		})
+	})
+	<-started
+
+	if err := s.OnStop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-finished:
+	default:
+		t.Error("OnStop returned before the running job finished")
+	}
+}
+
+func TestStopTimeout(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	defer close(release)
+	s := startScheduler(t, func(s *Scheduler) {
+		s.Every("stuck", tick, func(ctx context.Context) error {
+			close(started)
+			<-release // ignores cancellation
+			return nil
+		})
+	})
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.OnStop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("OnStop = %v, want the context's error", err)
+	}
+}
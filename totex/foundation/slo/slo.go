@@ -0,0 +1,349 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/foundation/metrics"
+)
+
+// DefaultWindows are the burn-rate windows of the multiwindow alerts in the
+// Google SRE workbook: 5m and 1h, 30m and 6h, 1d and 3d
+var DefaultWindows = []time.Duration{
+	5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour, 24 * time.Hour, 72 * time.Hour,
+}
+
+// slotsPerWindow is how many slots the shortest window spans; it sets the
+// resolution of every window
+const slotsPerWindow = 10
+
+// serverErrors are the codes that count against availability; the others are
+// caused by the client and count as good events
+var serverErrors = map[string]bool{
+	"unknown":           true,
+	"deadline_exceeded": true,
+	"unimplemented":     true,
+	"internal":          true,
+	"unavailable":       true,
+	"data_loss":         true,
+}
+
+// Objective is the SLO of one ConnectRPC procedure. An event is good if the
+// RPC did not fail with a server error and, when Latency is set, completed
+// within Latency.
+type Objective struct {
+	Procedure    string        // e.g. /user.v1.UserService/CreateUser
+	Availability float64       // target ratio of good events, e.g. 0.999
+	Latency      time.Duration // slower RPCs are bad events; 0 only counts errors
+}
+
+// Validate reports whether the objective can be tracked
+func (o Objective) Validate() error {
+	if !strings.HasPrefix(o.Procedure, "/") {
+		return fmt.Errorf("slo: procedure %q must start with /", o.Procedure)
+	}
+	if o.Availability <= 0 || o.Availability >= 1 {
+		return fmt.Errorf("slo: availability of %s must be between 0 and 1, got %v", o.Procedure, o.Availability)
+	}
+	if o.Latency < 0 {
+		return fmt.Errorf("slo: latency of %s must not be negative", o.Procedure)
+	}
+	return nil
+}
+
+// ParseObjective parses "procedure=availability" or
+// "procedure=availability:latency", where availability is a ratio or a
+// percentage, e.g. "/user.v1.UserService/CreateUser=99.9%:250ms"
+func ParseObjective(s string) (Objective, error) {
+	procedure, target, ok := strings.Cut(s, "=")
+	if !ok {
+		return Objective{}, fmt.Errorf("slo: %q is not procedure=availability[:latency]", s)
+	}
+	o := Objective{Procedure: strings.TrimSpace(procedure)}
+	availability, latency, hasLatency := strings.Cut(strings.TrimSpace(target), ":")
+	scale := 1.0
+	if strings.HasSuffix(availability, "%") {
+		availability, scale = strings.TrimSuffix(availability, "%"), 100
+	}
+	v, err := strconv.ParseFloat(availability, 64)
+	if err != nil {
+		return Objective{}, fmt.Errorf("slo: invalid availability in %q: %w", s, err)
+	}
+	// Rounding keeps 99.9% from becoming 0.9990000000000001
+	o.Availability = math.Round(v/scale*1e12) / 1e12
+	if hasLatency {
+		if o.Latency, err = time.ParseDuration(latency); err != nil {
+			return Objective{}, fmt.Errorf("slo: invalid latency in %q: %w", s, err)
+		}
+	}
+	return o, o.Validate()
+}
+
+// Option configures a Tracker
+type Option func(*options)
+
+type options struct {
+	windows  []time.Duration
+	interval time.Duration
+}
+
+// WithWindows sets the burn-rate windows, DefaultWindows by default
+func WithWindows(windows ...time.Duration) Option {
+	return func(o *options) {
+		o.windows = windows
+	}
+}
+
+// WithInterval sets how often the burn-rate gauges are updated, 15s by default
+func WithInterval(d time.Duration) Option {
+	return func(o *options) {
+		o.interval = d
+	}
+}
+
+// Tracker counts good and total events per objective from completed RPCs
+// and computes error-budget burn rates over several windows. It records
+// slo_events_total and slo_good_events_total{procedure} as RPCs complete, and
+// periodically sets slo_burn_rate{procedure,window},
+// slo_error_budget_remaining{procedure} and slo_objective{procedure}. It is
+// an App component: OnStart starts the periodic updates.
+type Tracker struct {
+	metrics    metrics.Metrics
+	opts       options
+	resolution time.Duration
+	procedures []string
+	objectives map[string]*objective
+	now        func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// objective holds the events of one procedure in a ring of time slots
+type objective struct {
+	Objective
+	mu    sync.Mutex
+	slots []slot
+}
+
+type slot struct {
+	index       int64 // time slot the counts belong to
+	total, good uint64
+}
+
+// NewTracker creates a tracker for objectives recording into m. It fails if
+// an objective is invalid or a procedure has more than one.
+func NewTracker(objectives []Objective, m metrics.Metrics, opts ...Option) (*Tracker, error) {
+	o := options{windows: DefaultWindows, interval: 15 * time.Second}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		return nil, errors.New("slo: update interval must be positive")
+	}
+	if len(o.windows) == 0 {
+		return nil, errors.New("slo: no burn-rate windows")
+	}
+	windows := append([]time.Duration(nil), o.windows...)
+	sort.Slice(windows, func(i, j int) bool { return windows[i] < windows[j] })
+	if windows[0] <= 0 {
+		return nil, errors.New("slo: burn-rate windows must be positive")
+	}
+	o.windows = windows
+
+	t := &Tracker{
+		metrics:    m,
+		opts:       o,
+		resolution: max(windows[0]/slotsPerWindow, time.Second),
+		objectives: make(map[string]*objective),
+		now:        time.Now,
+	}
+	slots := int(windows[len(windows)-1]/t.resolution) + 1
+	for _, obj := range objectives {
+		if err := obj.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := t.objectives[obj.Procedure]; ok {
+			return nil, fmt.Errorf("slo: duplicate objective for %s", obj.Procedure)
+		}
+		t.objectives[obj.Procedure] = &objective{Objective: obj, slots: make([]slot, slots)}
+		t.procedures = append(t.procedures, obj.Procedure)
+	}
+	sort.Strings(t.procedures)
+	return t, nil
+}
+
+// ObserveRPC counts a completed RPC against the objective of its procedure,
+// if there is one; it is called by the ConnectRPC server instrumentation
+func (t *Tracker) ObserveRPC(ctx context.Context, procedure, code string, duration time.Duration) {
+	obj, ok := t.objectives[procedure]
+	if !ok {
+		return
+	}
+	good := !serverErrors[code] && (obj.Latency == 0 || duration <= obj.Latency)
+
+	index := t.now().UnixNano() / int64(t.resolution)
+	obj.mu.Lock()
+	s := &obj.slots[index%int64(len(obj.slots))]
+	if s.index != index {
+		*s = slot{index: index}
+	}
+	s.total++
+	if good {
+		s.good++
+	}
+	obj.mu.Unlock()
+
+	t.metrics.CounterContext(ctx, "slo_events_total", 1, "procedure", procedure)
+	if good {
+		t.metrics.CounterContext(ctx, "slo_good_events_total", 1, "procedure", procedure)
+	}
+}
+
+// Status is the state of an objective, as served by Handler
+type Status struct {
+	Procedure    string  `json:"procedure"`
+	Availability float64 `json:"availability"`
+	Latency      string  `json:"latency,omitempty"`
+	// ErrorBudgetRemaining is the share of the error budget of the longest
+	// window left unspent; it is negative once the budget is exhausted
+	ErrorBudgetRemaining float64        `json:"error_budget_remaining"`
+	Windows              []WindowStatus `json:"windows"`
+}
+
+// WindowStatus holds the events and burn rate of an objective over one window
+type WindowStatus struct {
+	Window string `json:"window"`
+	Total  uint64 `json:"total"`
+	Good   uint64 `json:"good"`
+	// BurnRate is the error rate divided by the rate the objective allows: at
+	// 1 the budget lasts exactly the window, above 1 it runs out early
+	BurnRate float64 `json:"burn_rate"`
+}
+
+// Status returns the current state of every objective, ordered by procedure
+func (t *Tracker) Status() []Status {
+	now := t.now().UnixNano() / int64(t.resolution)
+	statuses := make([]Status, 0, len(t.procedures))
+	for _, procedure := range t.procedures {
+		obj := t.objectives[procedure]
+		st := Status{Procedure: procedure, Availability: obj.Availability, ErrorBudgetRemaining: 1}
+		if obj.Latency > 0 {
+			st.Latency = obj.Latency.String()
+		}
+		for _, w := range t.opts.windows {
+			ws := obj.window(now, int64(w/t.resolution))
+			ws.Window = formatWindow(w)
+			st.Windows = append(st.Windows, ws)
+		}
+		if n := len(st.Windows); n > 0 {
+			st.ErrorBudgetRemaining = 1 - st.Windows[n-1].BurnRate
+		}
+		statuses = append(statuses, st)
+	}
+	return statuses
+}
+
+// window sums the slots of the last n slot indexes up to now
+func (o *objective) window(now, n int64) WindowStatus {
+	var ws WindowStatus
+	o.mu.Lock()
+	for _, s := range o.slots {
+		if s.index > now-n && s.index <= now {
+			ws.Total += s.total
+			ws.Good += s.good
+		}
+	}
+	o.mu.Unlock()
+	if ws.Total > 0 {
+		errorRate := float64(ws.Total-ws.Good) / float64(ws.Total)
+		ws.BurnRate = errorRate / (1 - o.Availability)
+	}
+	return ws
+}
+
+// Update sets the burn-rate, error-budget and objective gauges
+func (t *Tracker) Update() {
+	for _, st := range t.Status() {
+		t.metrics.Gauge("slo_objective", st.Availability, "procedure", st.Procedure)
+		t.metrics.Gauge("slo_error_budget_remaining", st.ErrorBudgetRemaining, "procedure", st.Procedure)
+		for _, ws := range st.Windows {
+			t.metrics.Gauge("slo_burn_rate", ws.BurnRate, "procedure", st.Procedure, "window", ws.Window)
+		}
+	}
+}
+
+// Handler serves the status of every objective as JSON
+func (t *Tracker) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.Header().Set("Allow", "GET, HEAD")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(t.Status())
+	})
+}
+
+func (t *Tracker) Name() string        { return "slo-tracker" }
+func (t *Tracker) DependsOn() []string { return nil }
+
+// OnStart sets the gauges and starts updating them on the configured interval
+func (t *Tracker) OnStart(ctx context.Context) error {
+	t.Update()
+	t.stop = make(chan struct{})
+	t.done = make(chan struct{})
+	go t.run()
+	return nil
+}
+
+// OnStop stops the periodic updates
+func (t *Tracker) OnStop(ctx context.Context) error {
+	if t.stop == nil {
+		return nil
+	}
+	close(t.stop)
+	select {
+	case <-t.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (t *Tracker) run() {
+	defer close(t.done)
+	ticker := time.NewTicker(t.opts.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-t.stop:
+			return
+		case <-ticker.C:
+			t.Update()
+		}
+	}
+}
+
+// formatWindow formats a window in the largest whole unit, e.g. 5m, 6h or 3d
+func formatWindow(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return strconv.FormatInt(int64(d/(24*time.Hour)), 10) + "d"
+	case d%time.Hour == 0:
+		return strconv.FormatInt(int64(d/time.Hour), 10) + "h"
+	case d%time.Minute == 0:
+		return strconv.FormatInt(int64(d/time.Minute), 10) + "m"
+	}
+	return d.String()
+}
@@ -0,0 +1,281 @@
+package slo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/metrics"
+)
+
+// gaugeRecorder keeps the last value of every gauge and counts counters
+type gaugeRecorder struct {
+	metrics.Metrics
+	mu       sync.Mutex
+	gauges   map[string]float64
+	counters map[string]float64
+}
+
+func newGaugeRecorder() *gaugeRecorder {
+	return &gaugeRecorder{
+		Metrics:  metrics.NewNoopMetrics("test"),
+		gauges:   make(map[string]float64),
+		counters: make(map[string]float64),
+	}
+}
+
+func seriesKey(name string, labels []string) string {
+	return name + fmt.Sprint(labels)
+}
+
+func (r *gaugeRecorder) Gauge(name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gauges[seriesKey(name, labels)] = value
+}
+
+func (r *gaugeRecorder) CounterContext(ctx context.Context, name string, value float64, labels ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.counters[seriesKey(name, labels)] += value
+}
+
+// clock is a settable time source for a Tracker
+type clock struct{ now time.Time }
+
+func (c *clock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+// observe reports n RPCs of procedure to t
+func observe(t *Tracker, procedure, code string, duration time.Duration, n int) {
+	for i := 0; i < n; i++ {
+		t.ObserveRPC(context.Background(), procedure, code, duration)
+	}
+}
+
+// approx reports whether two ratios are equal up to rounding
+func approx(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+const procedure = "/user.v1.UserService/CreateUser"
+
+func TestParseObjective(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Objective
+	}{
+		{procedure + "=99.9%:250ms", Objective{procedure, 0.999, 250 * time.Millisecond}},
+		{procedure + "=0.99", Objective{procedure, 0.99, 0}},
+		{" " + procedure + " = 99.95% ", Objective{procedure, 0.9995, 0}},
+	}
+	for _, tt := range tests {
+		got, err := ParseObjective(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseObjective(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+		}
+	}
+
+	invalid := []string{
+		"",
+		procedure,                        // no target
+		procedure + "=",                  // empty availability
+		procedure + "=high",              // not a number
+		procedure + "=99.9%:fast",        // bad latency
+		procedure + "=99.9%:-1s",         // negative latency
+		procedure + "=100%",              // no error budget
+		procedure + "=0",                 // no objective
+		"user.v1.UserService/Create=0.9", // no leading /
+	}
+	for _, in := range invalid {
+		if o, err := ParseObjective(in); err == nil {
+			t.Errorf("ParseObjective(%q) = %+v, want an error", in, o)
+		}
+	}
+}
+
+func TestNewTrackerValidation(t *testing.T) {
+	m := metrics.NewNoopMetrics("test")
+	obj := Objective{Procedure: procedure, Availability: 0.99}
+	if _, err := NewTracker([]Objective{obj, obj}, m); err == nil {
+		t.Error("accepted two objectives for one procedure")
+	}
+	if _, err := NewTracker([]Objective{{Procedure: procedure, Availability: 1}}, m); err == nil {
+		t.Error("accepted an invalid objective")
+	}
+	if _, err := NewTracker(nil, m, WithWindows()); err == nil {
+		t.Error("accepted no windows")
+	}
+	if _, err := NewTracker(nil, m, WithWindows(time.Hour, 0)); err == nil {
+		t.Error("accepted a zero window")
+	}
+	if _, err := NewTracker(nil, m, WithInterval(0)); err == nil {
+		t.Error("accepted a zero interval")
+	}
+}
+
+// newTestTracker tracks procedure at 99% over 1m and 5m windows, resolved to 6s slots
+func newTestTracker(t *testing.T, latency time.Duration) (*Tracker, *clock, *gaugeRecorder) {
+	t.Helper()
+	m := newGaugeRecorder()
+	tr, err := NewTracker([]Objective{{Procedure: procedure, Availability: 0.99, Latency: latency}}, m,
+		WithWindows(5*time.Minute, time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	clk := &clock{now: time.Unix(6_000_000, 0)}
+	tr.now = func() time.Time { return clk.now }
+	return tr, clk, m
+}
+
+func windows(tr *Tracker) map[string]WindowStatus {
+	out := make(map[string]WindowStatus)
+	for _, ws := range tr.Status()[0].Windows {
+		out[ws.Window] = ws
+	}
+	return out
+}
+
+func TestBurnRate(t *testing.T) {
+	tr, _, _ := newTestTracker(t, 250*time.Millisecond)
+	observe(tr, procedure, "ok", 10*time.Millisecond, 94)
+	observe(tr, procedure, "not_found", 10*time.Millisecond, 2) // the client's fault
+	observe(tr, procedure, "internal", 10*time.Millisecond, 3)
+	observe(tr, procedure, "ok", time.Second, 1) // too slow
+	observe(tr, "/other.v1.Other/Call", "internal", 0, 5)
+
+	st := tr.Status()
+	if len(st) != 1 || st[0].Latency != "250ms" || st[0].Availability != 0.99 {
+		t.Fatalf("Status = %+v", st)
+	}
+	ws := windows(tr)["1m"]
+	if ws.Total != 100 || ws.Good != 96 {
+		t.Errorf("1m window has %d good of %d, want 96 of 100", ws.Good, ws.Total)
+	}
+	// 4% errors against a 1% budget
+	if !approx(ws.BurnRate, 4) {
+		t.Errorf("burn rate = %v, want 4", ws.BurnRate)
+	}
+	if !approx(st[0].ErrorBudgetRemaining, -3) {
+		t.Errorf("error budget remaining = %v, want -3", st[0].ErrorBudgetRemaining)
+	}
+}
+
+func TestErrorBudgetRemaining(t *testing.T) {
+	tr, clk, _ := newTestTracker(t, 0)
+	observe(tr, procedure, "unavailable", 0, 1)
+	observe(tr, procedure, "ok", 0, 99)
+	clk.advance(2 * time.Minute)
+	observe(tr, procedure, "ok", 0, 100)
+
+	ws := windows(tr)
+	if ws["1m"].Total != 100 || ws["1m"].BurnRate != 0 {
+		t.Errorf("1m window = %+v, want only the last 100 good events", ws["1m"])
+	}
+	// 1 error in 200 events spends half of the 1% budget
+	if ws["5m"].Total != 200 || !approx(ws["5m"].BurnRate, 0.5) {
+		t.Errorf("5m window = %+v, want a burn rate of 0.5", ws["5m"])
+	}
+	if got := tr.Status()[0].ErrorBudgetRemaining; !approx(got, 0.5) {
+		t.Errorf("error budget remaining = %v, want 0.5", got)
+	}
+}
+
+func TestSlotsExpire(t *testing.T) {
+	tr, clk, _ := newTestTracker(t, 0)
+	observe(tr, procedure, "internal", 0, 10)
+
+	clk.advance(time.Minute - time.Second)
+	if ws := windows(tr); ws["1m"].Total != 10 || ws["5m"].Total != 10 {
+		t.Errorf("before a minute: %+v", ws)
+	}
+	clk.advance(time.Second)
+	if ws := windows(tr); ws["1m"].Total != 0 || ws["5m"].Total != 10 {
+		t.Errorf("after a minute the events should leave the 1m window only: %+v", ws)
+	}
+	clk.advance(4 * time.Minute)
+	if ws := windows(tr); ws["5m"].Total != 0 || ws["5m"].BurnRate != 0 {
+		t.Errorf("after five minutes: %+v", ws)
+	}
+	if got := tr.Status()[0].ErrorBudgetRemaining; got != 1 {
+		t.Errorf("error budget remaining = %v with no events, want 1", got)
+	}
+}
+
+func TestSlotsReusedAcrossRing(t *testing.T) {
+	tr, clk, _ := newTestTracker(t, 0)
+	observe(tr, procedure, "internal", 0, 10)
+
+	// Once the ring wraps around, the slot of the old events is reset before it is reused
+	slots := len(tr.objectives[procedure].slots)
+	clk.advance(time.Duration(slots) * tr.resolution)
+	observe(tr, procedure, "ok", 0, 1)
+	if ws := windows(tr); ws["5m"].Total != 1 || ws["5m"].Good != 1 {
+		t.Errorf("5m window = %+v, want only the new event", ws["5m"])
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	tr, _, m := newTestTracker(t, 0)
+	observe(tr, procedure, "internal", 0, 1)
+	observe(tr, procedure, "ok", 0, 49)
+	tr.Update()
+
+	want := map[string]float64{
+		seriesKey("slo_objective", []string{"procedure", procedure}):                 0.99,
+		seriesKey("slo_error_budget_remaining", []string{"procedure", procedure}):    -1,
+		seriesKey("slo_burn_rate", []string{"procedure", procedure, "window", "1m"}): 2,
+		seriesKey("slo_burn_rate", []string{"procedure", procedure, "window", "5m"}): 2,
+	}
+	for key, v := range want {
+		if got, ok := m.gauges[key]; !ok || !approx(got, v) {
+			t.Errorf("%s = %v, want %v", key, got, v)
+		}
+	}
+	if got := m.counters[seriesKey("slo_events_total", []string{"procedure", procedure})]; got != 50 {
+		t.Errorf("slo_events_total = %v, want 50", got)
+	}
+	if got := m.counters[seriesKey("slo_good_events_total", []string{"procedure", procedure})]; got != 49 {
+		t.Errorf("slo_good_events_total = %v, want 49", got)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	tr, _, _ := newTestTracker(t, 0)
+	observe(tr, procedure, "ok", 0, 3)
+
+	rec := httptest.NewRecorder()
+	tr.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/admin/slo", nil))
+	var st []Status
+	if err := json.NewDecoder(rec.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if len(st) != 1 || st[0].Procedure != procedure || len(st[0].Windows) != 2 ||
+		st[0].Windows[0].Window != "1m" || st[0].Windows[0].Good != 3 {
+		t.Errorf("served %+v", st)
+	}
+
+	rec = httptest.NewRecorder()
+	tr.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/admin/slo", strings.NewReader("{}")))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d", rec.Code)
+	}
+}
+
+func TestFormatWindow(t *testing.T) {
+	tests := map[time.Duration]string{
+		5 * time.Minute:  "5m",
+		6 * time.Hour:    "6h",
+		72 * time.Hour:   "3d",
+		90 * time.Second: "1m30s",
+	}
+	for d, want := range tests {
+		if got := formatWindow(d); got != want {
+			t.Errorf("formatWindow(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
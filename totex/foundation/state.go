@@ -0,0 +1,166 @@
+package foundation
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// State is a stage in the App lifecycle
+type State int
+
+const (
+	StateCreated State = iota
+	StateStarting
+	StateRunning
+	StateStopping
+	StateStopped
+	StateFailed
+)
+
+// states lists every State, in order, for metrics export
+var states = []State{StateCreated, StateStarting, StateRunning, StateStopping, StateStopped, StateFailed}
+
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	case StateFailed:
+		return "failed"
+	default:
+		return fmt.Sprintf("State(%d)", int(s))
+	}
+}
+
+// terminal reports whether no further transitions can happen from s
+func (s State) terminal() bool {
+	return s == StateStopped || s == StateFailed
+}
+
+// ErrInvalidState matches every StateError via errors.Is
+var ErrInvalidState = errors.New("invalid app state")
+
+// StateError is returned when an operation is not allowed in the App's current state
+type StateError struct {
+	Op    string
+	State State
+}
+
+func (e *StateError) Error() string {
+	return fmt.Sprintf("cannot %s app in state %s", e.Op, e.State)
+}
+
+// Is makes errors.Is(err, ErrInvalidState) true for any StateError
+func (e *StateError) Is(target error) bool {
+	return target == ErrInvalidState
+}
+
+// StateEvent describes a single App state transition
+type StateEvent struct {
+	From State
+	To   State
+	Time time.Time
+	Err  error // set when the transition was caused by a failure
+}
+
+// stateEventBuffer is how many events a slow subscriber may fall behind before events are dropped
+const stateEventBuffer = 16
+
+// State returns the current lifecycle state
+func (a *App) State() State {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	return a.state
+}
+
+// Done is closed once the App reaches StateStopped or StateFailed
+func (a *App) Done() <-chan struct{} {
+	return a.done
+}
+
+// Subscribe returns a channel of state transitions and a function that
+// cancels the subscription. Events are dropped if the subscriber falls behind.
+func (a *App) Subscribe() (<-chan StateEvent, func()) {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+
+	ch := make(chan StateEvent, stateEventBuffer)
+	id := a.nextSubscriber
+	a.nextSubscriber++
+	a.subscribers[id] = ch
+
+	return ch, func() {
+		a.stateMu.Lock()
+		defer a.stateMu.Unlock()
+		if _, ok := a.subscribers[id]; ok {
+			delete(a.subscribers, id)
+			close(ch)
+		}
+	}
+}
+
+// guard moves to next if the current state is one of from, otherwise it
+// returns a StateError for op
+func (a *App) guard(op string, next State, from ...State) error {
+	a.stateMu.Lock()
+	current := a.state
+	a.stateMu.Unlock()
+
+	for _, s := range from {
+		if current == s {
+			a.transition(next, nil)
+			return nil
+		}
+	}
+	return &StateError{Op: op, State: current}
+}
+
+// requireState returns a StateError for op unless the App is in state want
+func (a *App) requireState(op string, want State) error {
+	a.stateMu.Lock()
+	defer a.stateMu.Unlock()
+	if a.state != want {
+		return &StateError{Op: op, State: a.state}
+	}
+	return nil
+}
+
+// transition records a state change, notifies subscribers and updates the state gauge
+func (a *App) transition(to State, cause error) {
+	a.stateMu.Lock()
+	event := StateEvent{From: a.state, To: to, Time: time.Now(), Err: cause}
+	a.state = to
+	for _, ch := range a.subscribers {
+		select {
+		case ch <- event:
+		default:
+			a.logger.Warn("Dropping app state event for slow subscriber", "from", event.From, "to", event.To)
+		}
+	}
+	if to.terminal() {
+		close(a.done)
+	}
+	a.stateMu.Unlock()
+
+	a.logger.Info("App state changed", "from", event.From, "to", event.To)
+	a.exportState(to)
+}
+
+// exportState sets the app_state gauge to 1 for the current state and 0 for all others
+func (a *App) exportState(current State) {
+	for _, s := range states {
+		value := 0.0
+		if s == current {
+			value = 1
+		}
+		a.metrics.Gauge("app_state", value, "state", s.String())
+	}
+}
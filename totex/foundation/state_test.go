@@ -0,0 +1,147 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// assertStateError checks that err is a StateError for op in state
+func assertStateError(t *testing.T, err error, op string, state State) {
+	t.Helper()
+	var se *StateError
+	if !errors.As(err, &se) || se.Op != op || se.State != state {
+		t.Errorf("got %v, want a StateError for %q in state %s", err, op, state)
+		return
+	}
+	if !errors.Is(err, ErrInvalidState) {
+		t.Errorf("%v does not match ErrInvalidState", err)
+	}
+}
+
+func TestStateErrors(t *testing.T) {
+	ctx := context.Background()
+	app := newTestApp(t)
+	assertStateError(t, app.Stop(ctx), "stop", StateCreated)
+
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertStateError(t, app.Start(ctx), "start", StateRunning)
+	assertStateError(t, app.AddServer(&fakeServer{name: "late", ev: &events{}}), "add server to", StateRunning)
+	assertStateError(t, app.AddComponent(NewComponent("late", nil, nil)), "add component to", StateRunning)
+
+	if err := app.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	assertStateError(t, app.Stop(ctx), "stop", StateStopped)
+	assertStateError(t, app.Start(ctx), "start", StateStopped)
+
+	// A failed app can be neither started nor stopped
+	failed := newTestApp(t)
+	failed.AddComponent(NewComponent("db", func(ctx context.Context) error { return errors.New("unreachable") }, nil))
+	if err := failed.Start(ctx); err == nil {
+		t.Fatal("Start succeeded")
+	}
+	assertStateError(t, failed.Start(ctx), "start", StateFailed)
+	assertStateError(t, failed.Stop(ctx), "stop", StateFailed)
+}
+
+// receive returns the next n events of ch
+func receive(t *testing.T, ch <-chan StateEvent, n int) []StateEvent {
+	t.Helper()
+	var got []StateEvent
+	for range n {
+		select {
+		case e := <-ch:
+			got = append(got, e)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("got %d of %d events: %v", len(got), n, got)
+		}
+	}
+	return got
+}
+
+func TestSubscribe(t *testing.T) {
+	ctx := context.Background()
+	app := newTestApp(t)
+	ch, cancel := app.Subscribe()
+	defer cancel()
+
+	if err := app.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-app.Done():
+		t.Fatal("Done closed while running")
+	default:
+	}
+	if err := app.Stop(ctx); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-app.Done():
+	default:
+		t.Fatal("Done not closed after Stop")
+	}
+
+	want := []State{StateCreated, StateStarting, StateRunning, StateStopping, StateStopped}
+	for i, e := range receive(t, ch, len(want)-1) {
+		if e.From != want[i] || e.To != want[i+1] || e.Err != nil || e.Time.IsZero() {
+			t.Errorf("event %d = %+v, want %s -> %s", i, e, want[i], want[i+1])
+		}
+	}
+}
+
+func TestSubscribeFailure(t *testing.T) {
+	startErr := errors.New("unreachable")
+	app := newTestApp(t)
+	app.AddComponent(NewComponent("db", func(ctx context.Context) error { return startErr }, nil))
+	ch, cancel := app.Subscribe()
+	if err := app.Start(context.Background()); err == nil {
+		t.Fatal("Start succeeded")
+	}
+	<-app.Done()
+
+	events := receive(t, ch, 2)
+	if e := events[1]; e.From != StateStarting || e.To != StateFailed || !errors.Is(e.Err, startErr) {
+		t.Errorf("event = %+v, want starting -> failed caused by %v", e, startErr)
+	}
+
+	// Cancelling closes the channel, and cancelling again is harmless
+	cancel()
+	cancel()
+	if _, ok := <-ch; ok {
+		t.Error("channel still open after cancel")
+	}
+}
+
+func TestSubscribeSlowSubscriber(t *testing.T) {
+	// A subscriber that never reads does not block the App
+	app := newTestApp(t)
+	_, cancel := app.Subscribe()
+	defer cancel()
+	for range stateEventBuffer + 1 {
+		app.transition(StateCreated, nil)
+	}
+	if app.State() != StateCreated {
+		t.Errorf("state = %s", app.State())
+	}
+}
+
+func TestStateString(t *testing.T) {
+	for s, want := range map[State]string{
+		StateCreated:  "created",
+		StateStarting: "starting",
+		StateRunning:  "running",
+		StateStopping: "stopping",
+		StateStopped:  "stopped",
+		StateFailed:   "failed",
+		State(42):     "State(42)",
+	} {
+		if got := s.String(); got != want {
+			t.Errorf("State(%d).String() = %q, want %q", int(s), got, want)
+		}
+	}
+}
@@ -0,0 +1,199 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// BaggageHeader is the W3C Baggage header
+const BaggageHeader = "Baggage"
+
+// Limits of the W3C baggage header; members past them are not propagated
+const (
+	maxBaggageMembers = 64
+	maxBaggageBytes   = 8192
+)
+
+// Baggage is a set of key/value pairs that travels with a request across
+// services, such as a tenant ID or an experiment bucket. It is immutable:
+// Set and Delete return a modified copy. The zero value is empty baggage.
+type Baggage struct {
+	members map[string]string
+}
+
+// Get returns the value under key and whether it is set
+func (b Baggage) Get(key string) (string, bool) {
+	v, ok := b.members[key]
+	return v, ok
+}
+
+// Set returns a copy of b with key set to value. Keys must be HTTP header
+// tokens; b is returned unchanged for any other key.
+func (b Baggage) Set(key, value string) Baggage {
+	if !isToken(key) {
+		return b
+	}
+	members := maps.Clone(b.members)
+	if members == nil {
+		members = make(map[string]string, 1)
+	}
+	members[key] = value
+	return Baggage{members: members}
+}
+
+// Delete returns a copy of b without key
+func (b Baggage) Delete(key string) Baggage {
+	if _, ok := b.members[key]; !ok {
+		return b
+	}
+	members := maps.Clone(b.members)
+	delete(members, key)
+	return Baggage{members: members}
+}
+
+// Len returns the number of members
+func (b Baggage) Len() int { return len(b.members) }
+
+// Members returns a copy of the key/value pairs
+func (b Baggage) Members() map[string]string {
+	members := make(map[string]string, len(b.members))
+	maps.Copy(members, b.members)
+	return members
+}
+
+// String formats b as a baggage header value, in key order. Values are
+// percent-encoded, and members that would take the header past 64 members
+// or 8192 bytes are left out.
+func (b Baggage) String() string {
+	var sb strings.Builder
+	n := 0
+	for _, k := range slices.Sorted(maps.Keys(b.members)) {
+		member := k + "=" + url.PathEscape(b.members[k])
+		if n == maxBaggageMembers || sb.Len()+len(member)+1 > maxBaggageBytes {
+			break
+		}
+		if n > 0 {
+			sb.WriteByte(',')
+		}
+		sb.WriteString(member)
+		n++
+	}
+	return sb.String()
+}
+
+// ParseBaggage parses a baggage header value. Member properties are
+// accepted but dropped.
+func ParseBaggage(v string) (Baggage, error) {
+	var b Baggage
+	for _, member := range strings.Split(v, ",") {
+		member, _, _ = strings.Cut(member, ";")
+		if strings.TrimSpace(member) == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(member, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || !isToken(key) {
+			return Baggage{}, fmt.Errorf("tracing: invalid baggage member %q", member)
+		}
+		value, err := url.PathUnescape(value)
+		if err != nil {
+			return Baggage{}, fmt.Errorf("tracing: invalid baggage value for %q: %w", key, err)
+		}
+		b = b.Set(key, value)
+	}
+	return b, nil
+}
+
+// isToken reports whether s is a non-empty HTTP header token
+func isToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c <= ' ' || c >= 0x7f || strings.IndexByte(`"(),/:;<=>?@[\]{}`, c) >= 0 {
+			return false
+		}
+	}
+	return true
+}
+
+type baggageKey struct{}
+
+// ContextWithBaggage returns a copy of ctx carrying b
+func ContextWithBaggage(ctx context.Context, b Baggage) context.Context {
+	return context.WithValue(ctx, baggageKey{}, b)
+}
+
+// BaggageFromContext returns the baggage carried by ctx, or empty baggage
+func BaggageFromContext(ctx context.Context) Baggage {
+	b, _ := ctx.Value(baggageKey{}).(Baggage)
+	return b
+}
+
+// ContextWithBaggageValue returns a copy of ctx whose baggage has key set to value
+//
+//	ctx = tracing.ContextWithBaggageValue(ctx, "tenant_id", tenantID)
+func ContextWithBaggageValue(ctx context.Context, key, value string) context.Context {
+	return ContextWithBaggage(ctx, BaggageFromContext(ctx).Set(key, value))
+}
+
+// BaggageValue returns the value under key in the baggage carried by ctx, or ""
+func BaggageValue(ctx context.Context, key string) string {
+	v, _ := BaggageFromContext(ctx).Get(key)
+	return v
+}
+
+// InjectBaggage writes the baggage carried by ctx to the baggage header of h,
+// if there is any. Unlike Tracer.Inject it works without a span, so baggage
+// propagates when tracing is disabled.
+func InjectBaggage(ctx context.Context, h http.Header) {
+	if b := BaggageFromContext(ctx); b.Len() > 0 {
+		h.Set(BaggageHeader, b.String())
+	}
+}
+
+// ExtractBaggage returns a copy of ctx carrying the baggage read from the
+// baggage header of h. ctx is returned as is if h has no valid baggage header.
+func ExtractBaggage(ctx context.Context, h http.Header) context.Context {
+	b, ok := baggageFromHeader(h)
+	if !ok {
+		return ctx
+	}
+	return ContextWithBaggage(ctx, b)
+}
+
+// baggageFromHeader parses the baggage header of h, which may be split over
+// several lines
+func baggageFromHeader(h http.Header) (Baggage, bool) {
+	values := h.Values(BaggageHeader)
+	if len(values) == 0 {
+		return Baggage{}, false
+	}
+	b, err := ParseBaggage(strings.Join(values, ","))
+	if err != nil || b.Len() == 0 {
+		return Baggage{}, false
+	}
+	return b, true
+}
+
+// BaggageSetter is implemented by spans that carry baggage, so that Inject
+// propagates it
+type BaggageSetter interface {
+	SetBaggage(b Baggage)
+}
+
+// WithBaggage attaches b to the new span, for tracers whose spans carry
+// baggage. StartSpanFromContext attaches the baggage of its context.
+func WithBaggage(b Baggage) SpanOption {
+	return func(s Span) {
+		if bs, ok := s.(BaggageSetter); ok {
+			bs.SetBaggage(b)
+		}
+	}
+}
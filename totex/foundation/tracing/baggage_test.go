@@ -0,0 +1,133 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBaggageRoundTrip(t *testing.T) {
+	b := Baggage{}.
+		Set("tenant_id", "acme corp, eu; 100%").
+		Set("user", "zoë").
+		Set("bucket", "b")
+	v := b.String()
+	if v != "bucket=b,tenant_id=acme%20corp%2C%20eu%3B%20100%25,user=zo%C3%AB" {
+		t.Errorf("String() = %q", v)
+	}
+	parsed, err := ParseBaggage(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Len() != 3 {
+		t.Fatalf("parsed %v", parsed.Members())
+	}
+	for k, want := range b.Members() {
+		if got, _ := parsed.Get(k); got != want {
+			t.Errorf("%s = %q, want %q", k, got, want)
+		}
+	}
+}
+
+func TestParseBaggage(t *testing.T) {
+	b, err := ParseBaggage(" tenant_id = acme ;ttl=60;flag, ,user=u1;p ,empty=")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Properties are accepted but dropped, and spaces around members trimmed
+	want := map[string]string{"tenant_id": "acme", "user": "u1", "empty": ""}
+	got := b.Members()
+	if len(got) != len(want) {
+		t.Fatalf("parsed %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+	if b.String() != "empty=,tenant_id=acme,user=u1" {
+		t.Errorf("String() = %q, want the members without their properties", b.String())
+	}
+
+	for _, v := range []string{"tenant_id", "bad key=1", "(key)=1", "=v", "k=%zz"} {
+		if _, err := ParseBaggage(v); err == nil {
+			t.Errorf("ParseBaggage(%q) succeeded", v)
+		}
+	}
+}
+
+func TestBaggageLimits(t *testing.T) {
+	var many Baggage
+	for i := range 70 {
+		many = many.Set(fmt.Sprintf("k%02d", i), "v")
+	}
+	v := many.String()
+	if n := strings.Count(v, ",") + 1; n != 64 {
+		t.Errorf("String() has %d members, want 64", n)
+	}
+	if !strings.HasPrefix(v, "k00=v,") || !strings.HasSuffix(v, ",k63=v") {
+		t.Errorf("String() = %q, want the first 64 keys", v)
+	}
+
+	var large Baggage
+	for i := range 10 {
+		large = large.Set(fmt.Sprintf("k%d", i), strings.Repeat("x", 1000))
+	}
+	v = large.String()
+	if len(v) > 8192 {
+		t.Errorf("String() is %d bytes, over the 8192 byte limit", len(v))
+	}
+	if n := strings.Count(v, ",") + 1; n != 8 {
+		t.Errorf("String() has %d members of 1003 bytes, want the 8 that fit", n)
+	}
+}
+
+func TestBaggageImmutable(t *testing.T) {
+	a := Baggage{}.Set("k", "1")
+	b := a.Set("k", "2").Set("other", "x")
+	c := b.Delete("k")
+	if v, _ := a.Get("k"); v != "1" || a.Len() != 1 {
+		t.Errorf("Set modified the original: %v", a.Members())
+	}
+	if _, ok := c.Get("k"); ok || b.Len() != 2 {
+		t.Errorf("Delete: %v from %v", c.Members(), b.Members())
+	}
+	if a.Set("bad key", "v").Len() != 1 {
+		t.Error("Set accepted a key that is not a token")
+	}
+	members := a.Members()
+	members["k"] = "changed"
+	if v, _ := a.Get("k"); v != "1" {
+		t.Error("Members returned the baggage's own map")
+	}
+}
+
+func TestBaggageHeaders(t *testing.T) {
+	ctx := ContextWithBaggageValue(context.Background(), "tenant_id", "acme")
+	ctx = ContextWithBaggageValue(ctx, "user", "u1")
+	h := make(http.Header)
+	InjectBaggage(ctx, h)
+	if got := h.Get(BaggageHeader); got != "tenant_id=acme,user=u1" {
+		t.Errorf("injected %q", got)
+	}
+	InjectBaggage(context.Background(), h)
+	if len(h.Values(BaggageHeader)) != 1 {
+		t.Error("empty baggage overwrote the header")
+	}
+
+	// The header may be split over several lines
+	h = http.Header{BaggageHeader: {"tenant_id=acme", "user=u1;prop"}}
+	ctx = ExtractBaggage(context.Background(), h)
+	if BaggageValue(ctx, "tenant_id") != "acme" || BaggageValue(ctx, "user") != "u1" {
+		t.Errorf("extracted %v", BaggageFromContext(ctx).Members())
+	}
+
+	// An invalid header is ignored
+	parent := ContextWithBaggageValue(context.Background(), "kept", "yes")
+	ctx = ExtractBaggage(parent, http.Header{BaggageHeader: {"not baggage"}})
+	if BaggageValue(ctx, "kept") != "yes" {
+		t.Error("an invalid header replaced the context's baggage")
+	}
+}
@@ -0,0 +1,261 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ExporterOption configures the batching, queueing and retries of a span exporter
+type ExporterOption func(*exporterOptions)
+
+type exporterOptions struct {
+	queueSize  int
+	batchSize  int
+	interval   time.Duration
+	timeout    time.Duration
+	maxRetries int
+	backoff    time.Duration
+	onError    func(error)
+}
+
+// WithQueueSize bounds the spans waiting to be sent, 2048 by default; spans
+// finishing while the queue is full are dropped and reported
+func WithQueueSize(n int) ExporterOption {
+	return func(o *exporterOptions) {
+		o.queueSize = n
+	}
+}
+
+// WithBatchSize sets the most spans sent at once, 512 by default
+func WithBatchSize(n int) ExporterOption {
+	return func(o *exporterOptions) {
+		o.batchSize = n
+	}
+}
+
+// WithBatchInterval sets how long spans wait for a batch to fill, 5s by default
+func WithBatchInterval(d time.Duration) ExporterOption {
+	return func(o *exporterOptions) {
+		o.interval = d
+	}
+}
+
+// WithExportTimeout bounds each attempt to send a batch, 10s by default
+func WithExportTimeout(d time.Duration) ExporterOption {
+	return func(o *exporterOptions) {
+		o.timeout = d
+	}
+}
+
+// WithRetry sets how often a batch is retried after a temporary failure, 3
+// times by default, and the backoff before the first retry, which doubles
+// with each retry and is jittered
+func WithRetry(maxRetries int, backoff time.Duration) ExporterOption {
+	return func(o *exporterOptions) {
+		o.maxRetries = maxRetries
+		o.backoff = backoff
+	}
+}
+
+// WithExportErrorHandler sets the function called when a batch cannot be
+// sent or spans are dropped
+func WithExportErrorHandler(fn func(error)) ExporterOption {
+	return func(o *exporterOptions) {
+		o.onError = fn
+	}
+}
+
+// retryableError marks a failure worth retrying, such as a network error or
+// an overloaded collector
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string { return e.err.Error() }
+func (e *retryableError) Unwrap() error { return e.err }
+
+func retryable(err error) error { return &retryableError{err} }
+
+// partialError is a failure after some spans of a batch were sent; only the
+// unsent spans are retried
+type partialError struct {
+	unsent []SpanData
+	err    error
+}
+
+func (e *partialError) Error() string { return e.err.Error() }
+func (e *partialError) Unwrap() error { return e.err }
+
+// batcher queues spans and sends them in batches from a single goroutine
+type batcher struct {
+	opts    exporterOptions
+	send    func(ctx context.Context, spans []SpanData) error
+	queue   chan SpanData
+	flushes chan chan struct{}
+	dropped atomic.Uint64
+
+	stopOnce  sync.Once
+	abortOnce sync.Once
+	stop      chan struct{} // closed by Shutdown
+	abort     chan struct{} // closed when Shutdown gives up, to cut retries short
+	done      chan struct{}
+}
+
+func newBatcher(send func(ctx context.Context, spans []SpanData) error, opts []ExporterOption) *batcher {
+	o := exporterOptions{
+		queueSize:  2048,
+		batchSize:  512,
+		interval:   5 * time.Second,
+		timeout:    10 * time.Second,
+		maxRetries: 3,
+		backoff:    100 * time.Millisecond,
+	}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.onError == nil {
+		o.onError = func(error) {}
+	}
+	o.queueSize = max(o.queueSize, 1)
+	o.batchSize = max(o.batchSize, 1)
+	o.backoff = max(o.backoff, time.Millisecond)
+	if o.interval <= 0 {
+		o.interval = 5 * time.Second
+	}
+
+	b := &batcher{
+		opts:    o,
+		send:    send,
+		queue:   make(chan SpanData, o.queueSize),
+		flushes: make(chan chan struct{}),
+		stop:    make(chan struct{}),
+		abort:   make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// ExportSpans queues spans without blocking, dropping those that do not fit
+func (b *batcher) ExportSpans(spans []SpanData) {
+	for _, s := range spans {
+		select {
+		case <-b.stop:
+			b.dropped.Add(1)
+		case b.queue <- s:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Flush sends the queued spans and waits until they are sent or ctx is done
+func (b *batcher) Flush(ctx context.Context) error {
+	reply := make(chan struct{})
+	select {
+	case b.flushes <- reply:
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	select {
+	case <-reply:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Shutdown sends the queued spans and stops the exporter. If ctx is done
+// first, pending retries are abandoned.
+func (b *batcher) Shutdown(ctx context.Context) error {
+	b.stopOnce.Do(func() { close(b.stop) })
+	select {
+	case <-b.done:
+		return nil
+	case <-ctx.Done():
+		b.abortOnce.Do(func() { close(b.abort) })
+		<-b.done
+		return ctx.Err()
+	}
+}
+
+func (b *batcher) run() {
+	defer close(b.done)
+	ticker := time.NewTicker(b.opts.interval)
+	defer ticker.Stop()
+	batch := make([]SpanData, 0, b.opts.batchSize)
+	add := func(s SpanData) {
+		if batch = append(batch, s); len(batch) >= b.opts.batchSize {
+			b.sendWithRetry(batch)
+			batch = make([]SpanData, 0, b.opts.batchSize)
+		}
+	}
+	flush := func() {
+		// Drain what is queued so a flush covers every span exported before it
+		for drained := false; !drained; {
+			select {
+			case s := <-b.queue:
+				add(s)
+			default:
+				drained = true
+			}
+		}
+		if n := b.dropped.Swap(0); n > 0 {
+			b.opts.onError(fmt.Errorf("dropped %d spans: export queue full", n))
+		}
+		if len(batch) > 0 {
+			b.sendWithRetry(batch)
+			batch = make([]SpanData, 0, b.opts.batchSize)
+		}
+	}
+
+	for {
+		select {
+		case s := <-b.queue:
+			add(s)
+		case <-ticker.C:
+			flush()
+		case reply := <-b.flushes:
+			flush()
+			close(reply)
+		case <-b.stop:
+			flush()
+			return
+		}
+	}
+}
+
+func (b *batcher) sendWithRetry(spans []SpanData) {
+	backoff := b.opts.backoff
+	for attempt := 0; ; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), b.opts.timeout)
+		err := b.send(ctx, spans)
+		cancel()
+		if err == nil {
+			return
+		}
+		var p *partialError
+		if errors.As(err, &p) {
+			spans = p.unsent
+		}
+		var r *retryableError
+		if !errors.As(err, &r) || attempt >= b.opts.maxRetries {
+			b.opts.onError(fmt.Errorf("export %d spans: %w", len(spans), err))
+			return
+		}
+		// Full jitter keeps the exporters of many instances from retrying in step
+		wait := time.Duration(rand.Int64N(int64(backoff))) + 1
+		select {
+		case <-time.After(wait):
+		case <-b.abort:
+			b.opts.onError(fmt.Errorf("export %d spans: %w", len(spans), err))
+			return
+		}
+		backoff *= 2
+	}
+}
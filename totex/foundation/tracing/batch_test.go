@@ -0,0 +1,174 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// errorRecorder collects the errors reported by an exporter
+type errorRecorder struct {
+	mu   sync.Mutex
+	errs []error
+}
+
+func (r *errorRecorder) handle(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.errs = append(r.errs, err)
+}
+
+func (r *errorRecorder) get() []error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]error(nil), r.errs...)
+}
+
+func TestExporterRetries(t *testing.T) {
+	tests := []struct {
+		status   int
+		requests int64
+	}{
+		{http.StatusServiceUnavailable, 3}, // the first attempt and two retries
+		{http.StatusTooManyRequests, 3},
+		{http.StatusBadRequest, 1},
+	}
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			var requests atomic.Int64
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				http.Error(w, "collector says no", tt.status)
+			}))
+			defer srv.Close()
+
+			var errs errorRecorder
+			e := NewZipkinExporter(srv.URL, "users", WithRetry(2, time.Millisecond), WithExportErrorHandler(errs.handle))
+			e.ExportSpans(testSpans)
+			e.Shutdown(context.Background())
+
+			if got := requests.Load(); got != tt.requests {
+				t.Errorf("%d requests, want %d", got, tt.requests)
+			}
+			got := errs.get()
+			if len(got) != 1 || !strings.Contains(got[0].Error(), "collector says no") {
+				t.Errorf("reported %v, want one error with the response body", got)
+			}
+		})
+	}
+}
+
+func TestExporterRecoversAfterRetry(t *testing.T) {
+	var requests atomic.Int64
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	var errs errorRecorder
+	e := NewZipkinExporter(srv.URL, "users", WithRetry(2, time.Millisecond), WithExportErrorHandler(errs.handle))
+	e.ExportSpans(testSpans)
+	e.Shutdown(context.Background())
+
+	if requests.Load() != 2 || len(errs.get()) != 0 {
+		t.Errorf("%d requests, errors %v; want a successful retry", requests.Load(), errs.get())
+	}
+}
+
+// blockingSend sends nothing until released, reporting each batch it is given
+type blockingSend struct {
+	started chan int
+	release chan struct{}
+}
+
+func (s *blockingSend) send(ctx context.Context, spans []SpanData) error {
+	s.started <- len(spans)
+	<-s.release
+	return nil
+}
+
+func TestBatcherDropsWhenQueueFull(t *testing.T) {
+	s := &blockingSend{started: make(chan int, 10), release: make(chan struct{})}
+	var errs errorRecorder
+	b := newBatcher(s.send, []ExporterOption{
+		WithQueueSize(2), WithBatchSize(1), WithBatchInterval(time.Hour), WithExportErrorHandler(errs.handle),
+	})
+
+	b.ExportSpans(testSpans[:1])
+	<-s.started // the batcher is now stuck sending the first span
+	b.ExportSpans(largeSpans(5))
+	close(s.release)
+	if err := b.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := 1
+	for len(s.started) > 0 {
+		sent += <-s.started
+	}
+	if sent != 3 {
+		t.Errorf("sent %d spans, want the first and the two that fit the queue", sent)
+	}
+	got := errs.get()
+	if len(got) != 1 || got[0].Error() != "dropped 3 spans: export queue full" {
+		t.Errorf("reported %v", got)
+	}
+	b.Shutdown(context.Background())
+}
+
+func TestBatcherShutdownFlushes(t *testing.T) {
+	var mu sync.Mutex
+	var sent []SpanData
+	send := func(ctx context.Context, spans []SpanData) error {
+		mu.Lock()
+		defer mu.Unlock()
+		sent = append(sent, spans...)
+		return nil
+	}
+	b := newBatcher(send, []ExporterOption{WithBatchSize(100), WithBatchInterval(time.Hour)})
+
+	b.ExportSpans(testSpans)
+	if err := b.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	b.ExportSpans(testSpans) // after Shutdown, dropped
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(sent) != 2 || sent[0].Name != "GET /users" {
+		t.Errorf("sent %v, want the two queued spans", sent)
+	}
+	if err := b.Flush(context.Background()); err != nil {
+		t.Errorf("Flush after Shutdown = %v", err)
+	}
+}
+
+func TestBatcherShutdownAbandonsRetries(t *testing.T) {
+	send := func(ctx context.Context, spans []SpanData) error {
+		return retryable(errors.New("collector unreachable"))
+	}
+	var errs errorRecorder
+	b := newBatcher(send, []ExporterOption{WithRetry(5, time.Hour), WithExportErrorHandler(errs.handle)})
+	b.ExportSpans(testSpans)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	if err := b.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want the context's error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Shutdown took %v waiting for a retry", elapsed)
+	}
+	if got := errs.get(); len(got) != 1 {
+		t.Errorf("reported %v, want the abandoned batch", got)
+	}
+}
@@ -0,0 +1,74 @@
+package tracing
+
+import (
+	"context"
+)
+
+// SpanContext identifies a span within a trace
+type SpanContext struct {
+	TraceID string
+	SpanID  string
+	Sampled bool
+}
+
+// IsValid reports whether the span context carries a trace and span ID
+func (sc SpanContext) IsValid() bool {
+	return sc.TraceID != "" && sc.SpanID != ""
+}
+
+type spanContextKey struct{}
+
+// ContextWithSpanContext returns a copy of ctx carrying sc
+func ContextWithSpanContext(ctx context.Context, sc SpanContext) context.Context {
+	return context.WithValue(ctx, spanContextKey{}, sc)
+}
+
+// SpanContextFromContext returns the span context carried by ctx, or the zero value
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	sc, _ := ctx.Value(spanContextKey{}).(SpanContext)
+	return sc
+}
+
+type spanKey struct{}
+
+// ContextWithSpan returns a copy of ctx carrying span as the current span
+func ContextWithSpan(ctx context.Context, span Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, span)
+}
+
+// SpanFromContext returns the current span carried by ctx, or nil
+func SpanFromContext(ctx context.Context) Span {
+	span, _ := ctx.Value(spanKey{}).(Span)
+	return span
+}
+
+// ParentSetter is implemented by spans that record their parent
+type ParentSetter interface {
+	SetParent(parent Span)
+}
+
+// ChildOf makes the new span a child of parent, for tracers whose spans record parents
+func ChildOf(parent Span) SpanOption {
+	return func(s Span) {
+		if ps, ok := s.(ParentSetter); ok && parent != nil {
+			ps.SetParent(parent)
+		}
+	}
+}
+
+// StartSpanFromContext starts a span as a child of the current span in ctx,
+// if any, carrying the baggage of ctx, and returns it with a copy of ctx
+// carrying it and its span context
+func StartSpanFromContext(ctx context.Context, t Tracer, name string, opts ...SpanOption) (Span, context.Context) {
+	if b := BaggageFromContext(ctx); b.Len() > 0 {
+		opts = append([]SpanOption{WithBaggage(b)}, opts...)
+	}
+	if parent := SpanFromContext(ctx); parent != nil {
+		opts = append([]SpanOption{ChildOf(parent)}, opts...)
+	}
+	span := t.StartSpan(name, opts...)
+	if sc := SpanContextFromContext(span.Context()); sc.IsValid() {
+		ctx = ContextWithSpanContext(ctx, sc)
+	}
+	return span, ContextWithSpan(ctx, span)
+}
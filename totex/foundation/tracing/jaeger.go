@@ -0,0 +1,262 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	// jaegerCollectorPath is where the Jaeger collector accepts Thrift batches over HTTP
+	jaegerCollectorPath = "/api/traces"
+	// jaegerMaxPacketSize bounds the UDP packets sent to the agent
+	jaegerMaxPacketSize = 65000
+)
+
+// Field ids of the Jaeger Thrift IDL, jaeger.thrift and agent.thrift
+const (
+	jaegerBatchProcess = 1
+	jaegerBatchSpans   = 2
+
+	jaegerProcessServiceName = 1
+
+	jaegerSpanTraceIDLow    = 1
+	jaegerSpanTraceIDHigh   = 2
+	jaegerSpanSpanID        = 3
+	jaegerSpanParentSpanID  = 4
+	jaegerSpanOperationName = 5
+	jaegerSpanFlags         = 7
+	jaegerSpanStartTime     = 8
+	jaegerSpanDuration      = 9
+	jaegerSpanTags          = 10
+	jaegerSpanLogs          = 11
+
+	jaegerTagKey   = 1
+	jaegerTagVType = 2
+	jaegerTagVStr  = 3
+	jaegerTagVBool = 5
+
+	jaegerTagString = 0
+	jaegerTagBool   = 2
+
+	jaegerLogTimestamp = 1
+	jaegerLogFields    = 2
+
+	jaegerEmitBatchBatch = 1
+)
+
+// JaegerExporter sends spans to Jaeger as Thrift, to an agent over UDP or
+// to a collector over HTTP. Spans are queued, sent in batches and retried
+// when the collector is unreachable or overloaded.
+type JaegerExporter struct {
+	*batcher
+	service string
+
+	url    string // collector URL, empty for the agent
+	client *http.Client
+	conn   net.Conn // agent connection
+}
+
+// NewJaegerExporter creates an exporter sending the spans of service to
+// endpoint: an http:// or https:// URL of a collector, where a URL without a
+// path gets /api/traces, or the host:port of an agent, optionally prefixed
+// with udp://
+func NewJaegerExporter(endpoint, service string, opts ...ExporterOption) (*JaegerExporter, error) {
+	e := &JaegerExporter{service: service}
+	if strings.HasPrefix(endpoint, "http://") || strings.HasPrefix(endpoint, "https://") {
+		e.url = endpointURL(endpoint, jaegerCollectorPath)
+		e.client = &http.Client{}
+	} else {
+		conn, err := net.Dial("udp", strings.TrimPrefix(endpoint, "udp://"))
+		if err != nil {
+			return nil, fmt.Errorf("jaeger agent %s: %w", endpoint, err)
+		}
+		e.conn = conn
+	}
+	e.batcher = newBatcher(e.send, opts)
+	return e, nil
+}
+
+func (e *JaegerExporter) send(ctx context.Context, spans []SpanData) error {
+	if e.conn == nil {
+		w := &thriftWriter{}
+		e.writeBatch(w, spans)
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(w.buf))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/x-thrift")
+		return doExport(e.client, req)
+	}
+	return e.sendUDP(spans)
+}
+
+// sendUDP emits spans to the agent, splitting them over several packets
+// when they do not fit in one. A failure carries the spans of the packets
+// that were not sent, so a retry does not duplicate the others.
+func (e *JaegerExporter) sendUDP(spans []SpanData) error {
+	var errs []error
+	var unsent []SpanData
+	for _, p := range e.packets(spans, &errs) {
+		if _, err := e.conn.Write(p.buf); err != nil {
+			errs = append(errs, err)
+			unsent = append(unsent, p.spans...)
+		}
+	}
+	if len(unsent) == 0 {
+		return errors.Join(errs...)
+	}
+	return &partialError{unsent: unsent, err: retryable(errors.Join(errs...))}
+}
+
+// jaegerPacket is an emitBatch call to the agent and the spans it holds
+type jaegerPacket struct {
+	spans []SpanData
+	buf   []byte
+}
+
+// packets encodes spans as emitBatch calls that fit in a packet, halving
+// batches that are too large. Spans too large on their own are dropped and
+// reported in errs.
+func (e *JaegerExporter) packets(spans []SpanData, errs *[]error) []jaegerPacket {
+	w := &thriftWriter{compact: true}
+	w.onewayMessageBegin("emitBatch", 0)
+	w.structBegin()
+	w.field(jaegerEmitBatchBatch, thriftStruct)
+	e.writeBatch(w, spans)
+	w.structEnd()
+
+	if len(w.buf) <= jaegerMaxPacketSize {
+		return []jaegerPacket{{spans: spans, buf: w.buf}}
+	}
+	if len(spans) == 1 {
+		*errs = append(*errs, fmt.Errorf("span %s of %d bytes exceeds the agent packet size", spans[0].Name, len(w.buf)))
+		return nil
+	}
+	half := len(spans) / 2
+	return append(e.packets(spans[:half], errs), e.packets(spans[half:], errs)...)
+}
+
+// writeBatch writes a Batch struct holding spans
+func (e *JaegerExporter) writeBatch(w *thriftWriter, spans []SpanData) {
+	w.structBegin()
+	w.field(jaegerBatchProcess, thriftStruct)
+	w.structBegin()
+	w.field(jaegerProcessServiceName, thriftString)
+	w.string(e.service)
+	w.structEnd()
+
+	w.field(jaegerBatchSpans, thriftList)
+	w.listBegin(thriftStruct, len(spans))
+	for _, s := range spans {
+		writeJaegerSpan(w, s)
+	}
+	w.structEnd()
+}
+
+func writeJaegerSpan(w *thriftWriter, s SpanData) {
+	high, low := parseTraceID(s.TraceID)
+	w.structBegin()
+	w.field(jaegerSpanTraceIDLow, thriftI64)
+	w.i64(low)
+	w.field(jaegerSpanTraceIDHigh, thriftI64)
+	w.i64(high)
+	w.field(jaegerSpanSpanID, thriftI64)
+	w.i64(parseID(s.SpanID))
+	w.field(jaegerSpanParentSpanID, thriftI64)
+	w.i64(parseID(s.ParentID))
+	w.field(jaegerSpanOperationName, thriftString)
+	w.string(s.Name)
+	w.field(jaegerSpanFlags, thriftI32)
+	var flags int32
+	if s.Sampled {
+		flags = 1
+	}
+	w.i32(flags)
+	w.field(jaegerSpanStartTime, thriftI64)
+	w.i64(s.Start.UnixMicro())
+	w.field(jaegerSpanDuration, thriftI64)
+	w.i64(s.Duration().Microseconds())
+
+	if len(s.Tags) > 0 || s.Error != "" {
+		n := len(s.Tags)
+		if s.Error != "" {
+			n++
+		}
+		w.field(jaegerSpanTags, thriftList)
+		w.listBegin(thriftStruct, n)
+		for _, k := range slices.Sorted(maps.Keys(s.Tags)) {
+			writeJaegerTag(w, k, s.Tags[k])
+		}
+		if s.Error != "" {
+			// Jaeger marks failed spans with error=true and logs the message
+			w.structBegin()
+			w.field(jaegerTagKey, thriftString)
+			w.string("error")
+			w.field(jaegerTagVType, thriftI32)
+			w.i32(jaegerTagBool)
+			w.boolField(jaegerTagVBool, true)
+			w.structEnd()
+		}
+	}
+	if s.Error != "" {
+		w.field(jaegerSpanLogs, thriftList)
+		w.listBegin(thriftStruct, 1)
+		w.structBegin()
+		w.field(jaegerLogTimestamp, thriftI64)
+		w.i64(s.End.UnixMicro())
+		w.field(jaegerLogFields, thriftList)
+		w.listBegin(thriftStruct, 2)
+		writeJaegerTag(w, "event", "error")
+		writeJaegerTag(w, "message", s.Error)
+		w.structEnd()
+	}
+	w.structEnd()
+}
+
+func writeJaegerTag(w *thriftWriter, key, value string) {
+	w.structBegin()
+	w.field(jaegerTagKey, thriftString)
+	w.string(key)
+	w.field(jaegerTagVType, thriftI32)
+	w.i32(jaegerTagString)
+	w.field(jaegerTagVStr, thriftString)
+	w.string(value)
+	w.structEnd()
+}
+
+// parseTraceID splits a 32 hex digit trace ID into its high and low halves;
+// shorter IDs only have a low half
+func parseTraceID(id string) (high, low int64) {
+	if len(id) > 16 {
+		return parseID(id[:len(id)-16]), parseID(id[len(id)-16:])
+	}
+	return 0, parseID(id)
+}
+
+// parseID parses up to 16 hex digits, returning 0 for an invalid or empty ID
+func parseID(id string) int64 {
+	v, err := strconv.ParseUint(id, 16, 64)
+	if err != nil {
+		return 0
+	}
+	return int64(v)
+}
+
+// Shutdown sends the queued spans, stops the exporter and closes its connections
+func (e *JaegerExporter) Shutdown(ctx context.Context) error {
+	err := e.batcher.Shutdown(ctx)
+	if e.conn != nil {
+		e.conn.Close()
+	} else {
+		e.client.CloseIdleConnections()
+	}
+	return err
+}
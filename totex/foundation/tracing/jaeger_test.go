@@ -0,0 +1,259 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var (
+	testStart = time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	testSpans = []SpanData{
+		{
+			TraceID: "0123456789abcdef0011223344556677",
+			SpanID:  "00000000000000aa",
+			Name:    "GET /users",
+			Start:   testStart,
+			End:     testStart.Add(1500 * time.Microsecond),
+			Tags:    map[string]string{"http.method": "GET", SpanKindTag: "server"},
+			Sampled: true,
+		},
+		{
+			TraceID:  "0123456789abcdef0011223344556677",
+			SpanID:   "00000000000000bb",
+			ParentID: "00000000000000aa",
+			Name:     "db.query",
+			Start:    testStart.Add(100 * time.Microsecond),
+			End:      testStart.Add(900 * time.Microsecond),
+			Error:    "connection reset",
+			Sampled:  true,
+		},
+	}
+)
+
+// checkJaegerBatch checks a decoded Batch against testSpans
+func checkJaegerBatch(t *testing.T, batch map[int16]any) {
+	t.Helper()
+	process := batch[jaegerBatchProcess].(map[int16]any)
+	if process[jaegerProcessServiceName] != "users" {
+		t.Errorf("service = %v, want users", process[jaegerProcessServiceName])
+	}
+	spans := batch[jaegerBatchSpans].([]any)
+	if len(spans) != 2 {
+		t.Fatalf("batch holds %d spans, want 2", len(spans))
+	}
+
+	root := spans[0].(map[int16]any)
+	want := map[int16]any{
+		jaegerSpanTraceIDHigh:   int64(0x0123456789abcdef),
+		jaegerSpanTraceIDLow:    int64(0x0011223344556677),
+		jaegerSpanSpanID:        int64(0xaa),
+		jaegerSpanParentSpanID:  int64(0),
+		jaegerSpanOperationName: "GET /users",
+		jaegerSpanFlags:         int32(1),
+		jaegerSpanStartTime:     testStart.UnixMicro(),
+		jaegerSpanDuration:      int64(1500),
+	}
+	for id, v := range want {
+		if root[id] != v {
+			t.Errorf("root field %d = %v, want %v", id, root[id], v)
+		}
+	}
+	tags := root[jaegerSpanTags].([]any)
+	if len(tags) != 2 {
+		t.Fatalf("root tags = %v", tags)
+	}
+	// Tags are sorted by key
+	if tag := tags[0].(map[int16]any); tag[jaegerTagKey] != "http.method" || tag[jaegerTagVType] != int32(jaegerTagString) || tag[jaegerTagVStr] != "GET" {
+		t.Errorf("first tag = %v", tag)
+	}
+	if _, ok := root[jaegerSpanLogs]; ok {
+		t.Error("span without error has logs")
+	}
+
+	child := spans[1].(map[int16]any)
+	if child[jaegerSpanParentSpanID] != int64(0xaa) || child[jaegerSpanDuration] != int64(800) {
+		t.Errorf("child = %v", child)
+	}
+	tag := child[jaegerSpanTags].([]any)[0].(map[int16]any)
+	if tag[jaegerTagKey] != "error" || tag[jaegerTagVType] != int32(jaegerTagBool) || tag[jaegerTagVBool] != true {
+		t.Errorf("error tag = %v", tag)
+	}
+	logs := child[jaegerSpanLogs].([]any)
+	log := logs[0].(map[int16]any)
+	fields := log[jaegerLogFields].([]any)
+	if log[jaegerLogTimestamp] != testSpans[1].End.UnixMicro() || len(fields) != 2 ||
+		fields[1].(map[int16]any)[jaegerTagVStr] != "connection reset" {
+		t.Errorf("error log = %v", log)
+	}
+}
+
+func TestJaegerCollector(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != jaegerCollectorPath || r.Header.Get("Content-Type") != "application/x-thrift" {
+			t.Errorf("request to %s with %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer srv.Close()
+
+	e, err := NewJaegerExporter(srv.URL, "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.ExportSpans(testSpans)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	r := &thriftReader{t: t, buf: <-bodies}
+	checkJaegerBatch(t, r.readStruct())
+	if len(r.buf) != 0 {
+		t.Errorf("%d trailing bytes", len(r.buf))
+	}
+}
+
+// listenAgent listens for UDP packets like a Jaeger agent
+func listenAgent(t *testing.T) net.PacketConn {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { pc.Close() })
+	return pc
+}
+
+// readEmitBatch reads one packet and returns the Batch of its emitBatch call
+func readEmitBatch(t *testing.T, pc net.PacketConn) map[int16]any {
+	t.Helper()
+	buf := make([]byte, 1<<16)
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := &thriftReader{t: t, buf: buf[:n], compact: true}
+	if method, typ, _ := r.messageBegin(); method != "emitBatch" || typ != 4 {
+		t.Fatalf("message %s of type %d, want a oneway emitBatch", method, typ)
+	}
+	args := r.readStruct()
+	if len(r.buf) != 0 {
+		t.Errorf("%d trailing bytes", len(r.buf))
+	}
+	return args[jaegerEmitBatchBatch].(map[int16]any)
+}
+
+func TestJaegerAgent(t *testing.T) {
+	pc := listenAgent(t)
+	e, err := NewJaegerExporter("udp://"+pc.LocalAddr().String(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Shutdown(context.Background())
+	e.ExportSpans(testSpans)
+	if err := e.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	checkJaegerBatch(t, readEmitBatch(t, pc))
+}
+
+// largeSpans returns n spans that only fit in a packet on their own
+func largeSpans(n int) []SpanData {
+	spans := make([]SpanData, n)
+	for i := range spans {
+		spans[i] = SpanData{
+			TraceID: "1", SpanID: string(rune('a' + i)), Name: "large", Start: testStart, End: testStart,
+			Tags: map[string]string{"payload": strings.Repeat("x", jaegerMaxPacketSize/2)},
+		}
+	}
+	return spans
+}
+
+// readSpanIDs reads the given number of packets from pc and counts their spans by id
+func readSpanIDs(t *testing.T, pc net.PacketConn, packets int) map[int64]int {
+	t.Helper()
+	ids := make(map[int64]int)
+	for range packets {
+		for _, s := range readEmitBatch(t, pc)[jaegerBatchSpans].([]any) {
+			ids[s.(map[int16]any)[jaegerSpanSpanID].(int64)]++
+		}
+	}
+	return ids
+}
+
+func TestJaegerAgentSplitsLargeBatches(t *testing.T) {
+	pc := listenAgent(t)
+	e, err := NewJaegerExporter(pc.LocalAddr().String(), "users")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Shutdown(context.Background())
+	e.ExportSpans(largeSpans(3))
+	e.Flush(context.Background())
+
+	ids := readSpanIDs(t, pc, 3)
+	if len(ids) != 3 {
+		t.Errorf("received spans %v, want 3 in separate packets", ids)
+	}
+}
+
+// flakyConn fails its second write once
+type flakyConn struct {
+	net.Conn
+	writes atomic.Int64
+}
+
+func (c *flakyConn) Write(b []byte) (int, error) {
+	if c.writes.Add(1) == 2 {
+		return 0, errors.New("no buffer space available")
+	}
+	return c.Conn.Write(b)
+}
+
+func TestJaegerAgentRetriesOnlyUnsentPackets(t *testing.T) {
+	pc := listenAgent(t)
+	var mu sync.Mutex
+	var exportErrs []error
+	e, err := NewJaegerExporter(pc.LocalAddr().String(), "users",
+		WithRetry(1, time.Millisecond),
+		WithExportErrorHandler(func(err error) {
+			mu.Lock()
+			exportErrs = append(exportErrs, err)
+			mu.Unlock()
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer e.Shutdown(context.Background())
+	conn := &flakyConn{Conn: e.conn}
+	e.conn = conn
+
+	e.ExportSpans(largeSpans(3))
+	e.Flush(context.Background())
+
+	ids := readSpanIDs(t, pc, 3)
+	for id, n := range ids {
+		if n != 1 {
+			t.Errorf("span %d received %d times", id, n)
+		}
+	}
+	if len(ids) != 3 || conn.writes.Load() != 4 {
+		t.Errorf("received %v in %d writes, want 3 spans in 3 packets and one retry", ids, conn.writes.Load())
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(exportErrs) != 0 {
+		t.Errorf("retried export reported %v", exportErrs)
+	}
+}
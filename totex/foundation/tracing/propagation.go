@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// HTTPHeaders is the Inject and Extract format for http.Header carriers,
+// which carry the span context in the W3C traceparent header and its
+// baggage in the W3C baggage header
+const HTTPHeaders = "http_headers"
+
+// TraceparentHeader is the W3C Trace Context header
+const TraceparentHeader = "Traceparent"
+
+var (
+	// ErrUnsupportedFormat is returned for a format or carrier the tracer cannot handle
+	ErrUnsupportedFormat = errors.New("tracing: unsupported format")
+	// ErrSpanContextNotFound is returned by Extract when the carrier holds no span context
+	ErrSpanContextNotFound = errors.New("tracing: span context not found")
+)
+
+// FormatTraceparent formats sc as a version 00 traceparent header value
+func FormatTraceparent(sc SpanContext) string {
+	flags := "00"
+	if sc.Sampled {
+		flags = "01"
+	}
+	return "00-" + sc.TraceID + "-" + sc.SpanID + "-" + flags
+}
+
+// ParseTraceparent parses a traceparent header value
+func ParseTraceparent(v string) (SpanContext, error) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" || (parts[0] == "00" && len(parts) != 4) {
+		return SpanContext{}, fmt.Errorf("tracing: invalid traceparent %q", v)
+	}
+	traceID, spanID, flags := parts[1], parts[2], parts[3]
+	if !isHex(traceID, 32) || !isHex(spanID, 16) || !isHex(flags, 2) ||
+		traceID == strings.Repeat("0", 32) || spanID == strings.Repeat("0", 16) {
+		return SpanContext{}, fmt.Errorf("tracing: invalid traceparent %q", v)
+	}
+	sampled := strings.IndexByte("13579bdf", flags[1]) >= 0
+	return SpanContext{TraceID: traceID, SpanID: spanID, Sampled: sampled}, nil
+}
+
+// isHex reports whether s is n lowercase hex digits
+func isHex(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// Inject writes the span context and baggage of span to an http.Header carrier
+func (t *StandardTracer) Inject(span Span, format interface{}, carrier interface{}) error {
+	h, ok := carrier.(http.Header)
+	if format != HTTPHeaders || !ok {
+		return ErrUnsupportedFormat
+	}
+	sc := SpanContextFromContext(span.Context())
+	if !sc.IsValid() {
+		return ErrSpanContextNotFound
+	}
+	h.Set(TraceparentHeader, FormatTraceparent(sc))
+	InjectBaggage(span.Context(), h)
+	return nil
+}
+
+// Extract reads a span context and its baggage from an http.Header carrier.
+// The returned span stands for the remote parent: pass it to ChildOf, or put
+// it in a context with ContextWithSpan, to continue the trace. Baggage sent
+// without a traceparent header is read with ExtractBaggage.
+func (t *StandardTracer) Extract(format interface{}, carrier interface{}) (Span, error) {
+	h, ok := carrier.(http.Header)
+	if format != HTTPHeaders || !ok {
+		return nil, ErrUnsupportedFormat
+	}
+	v := h.Get(TraceparentHeader)
+	if v == "" {
+		return nil, ErrSpanContextNotFound
+	}
+	sc, err := ParseTraceparent(v)
+	if err != nil {
+		return nil, err
+	}
+	baggage, _ := baggageFromHeader(h)
+	return &span{tracer: t, data: SpanData{TraceID: sc.TraceID, SpanID: sc.SpanID, Sampled: sc.Sampled}, baggage: baggage, remote: true}, nil
+}
@@ -0,0 +1,99 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+const (
+	testTraceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	testSpanID  = "00f067aa0ba902b7"
+)
+
+func TestParseTraceparent(t *testing.T) {
+	valid := []struct {
+		v       string
+		sampled bool
+	}{
+		{"00-" + testTraceID + "-" + testSpanID + "-01", true},
+		{"00-" + testTraceID + "-" + testSpanID + "-00", false},
+		{" 00-" + testTraceID + "-" + testSpanID + "-03 ", true},
+		// Later versions may add fields
+		{"01-" + testTraceID + "-" + testSpanID + "-01-extra", true},
+	}
+	for _, tt := range valid {
+		sc, err := ParseTraceparent(tt.v)
+		if err != nil || sc.TraceID != testTraceID || sc.SpanID != testSpanID || sc.Sampled != tt.sampled {
+			t.Errorf("ParseTraceparent(%q) = %+v, %v", tt.v, sc, err)
+		}
+	}
+
+	invalid := []string{
+		"",
+		"00-" + testTraceID + "-" + testSpanID, // no flags
+		"00-" + testTraceID + "-" + testSpanID + "-01-extra",        // version 00 has four fields
+		"ff-" + testTraceID + "-" + testSpanID + "-01",              // forbidden version
+		"0-" + testTraceID + "-" + testSpanID + "-01",               // short version
+		"00-" + testTraceID[:31] + "-" + testSpanID + "-01",         // short trace ID
+		"00-" + testTraceID + "-" + testSpanID[:15] + "-01",         // short span ID
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-" + testSpanID + "-01", // uppercase
+		"00-00000000000000000000000000000000-" + testSpanID + "-01", // zero trace ID
+		"00-" + testTraceID + "-0000000000000000-01",                // zero span ID
+		"00-" + testTraceID + "-" + testSpanID + "-0g",              // bad flags
+		"00-" + testTraceID + "-" + testSpanID + "-1",               // short flags
+		"00_" + testTraceID + "_" + testSpanID + "_01",              // wrong separator
+	}
+	for _, v := range invalid {
+		if sc, err := ParseTraceparent(v); err == nil {
+			t.Errorf("ParseTraceparent(%q) = %+v, want an error", v, sc)
+		}
+	}
+}
+
+func TestFormatTraceparent(t *testing.T) {
+	sc := SpanContext{TraceID: testTraceID, SpanID: testSpanID, Sampled: true}
+	v := FormatTraceparent(sc)
+	if v != "00-"+testTraceID+"-"+testSpanID+"-01" {
+		t.Errorf("FormatTraceparent = %q", v)
+	}
+	if parsed, err := ParseTraceparent(v); err != nil || parsed != sc {
+		t.Errorf("round trip = %+v, %v", parsed, err)
+	}
+}
+
+func TestInjectExtract(t *testing.T) {
+	tracer := NewTracer("users")
+	root := tracer.StartSpan("GET /users", WithBaggage(Baggage{}.Set("tenant_id", "acme")))
+	h := make(http.Header)
+	if err := tracer.Inject(root, HTTPHeaders, h); err != nil {
+		t.Fatal(err)
+	}
+	if h.Get(BaggageHeader) != "tenant_id=acme" {
+		t.Errorf("injected baggage %q", h.Get(BaggageHeader))
+	}
+
+	remote, err := tracer.Extract(HTTPHeaders, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := tracer.StartSpan("db.query", ChildOf(remote))
+	want := SpanContextFromContext(root.Context())
+	got := SpanContextFromContext(child.Context())
+	if got.TraceID != want.TraceID || child.(*span).data.ParentID != want.SpanID || !got.Sampled {
+		t.Errorf("child of the extracted span = %+v, want a child of %+v", got, want)
+	}
+	if BaggageValue(child.Context(), "tenant_id") != "acme" {
+		t.Error("the baggage was not extracted")
+	}
+
+	if _, err := tracer.Extract(HTTPHeaders, http.Header{}); !errors.Is(err, ErrSpanContextNotFound) {
+		t.Errorf("Extract without traceparent = %v", err)
+	}
+	if _, err := tracer.Extract("text_map", h); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Extract of another format = %v", err)
+	}
+	if err := tracer.Inject(root, HTTPHeaders, map[string]string{}); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("Inject into another carrier = %v", err)
+	}
+}
@@ -0,0 +1,77 @@
+package tracing
+
+import (
+	"errors"
+
+	"github.com/yourusername/foundation/redact"
+)
+
+// NewRedactingTracer wraps t so that span tags under sensitive keys are
+// masked, and sensitive key=value pairs in tag values and span errors are masked
+func NewRedactingTracer(t Tracer, r *redact.Redactor) Tracer {
+	if r == nil {
+		return t
+	}
+	return &redactingTracer{Tracer: t, redactor: r}
+}
+
+type redactingTracer struct {
+	Tracer
+	redactor *redact.Redactor
+}
+
+func (t *redactingTracer) StartSpan(name string, opts ...SpanOption) Span {
+	// Options run on the wrapped span, so tracers that sample on start see
+	// the parent; spans unwrap parents with Unwrap
+	return &redactingSpan{Span: t.Tracer.StartSpan(name, opts...), redactor: t.redactor}
+}
+
+func (t *redactingTracer) Inject(span Span, format interface{}, carrier interface{}) error {
+	if rs, ok := span.(*redactingSpan); ok {
+		span = rs.Span
+	}
+	return t.Tracer.Inject(span, format, carrier)
+}
+
+func (t *redactingTracer) Extract(format interface{}, carrier interface{}) (Span, error) {
+	span, err := t.Tracer.Extract(format, carrier)
+	if span != nil {
+		span = &redactingSpan{Span: span, redactor: t.redactor}
+	}
+	return span, err
+}
+
+type redactingSpan struct {
+	Span
+	redactor *redact.Redactor
+}
+
+// Unwrap returns the wrapped span
+func (s *redactingSpan) Unwrap() Span { return s.Span }
+
+// SetParent passes the parent on to the wrapped span
+func (s *redactingSpan) SetParent(parent Span) {
+	if rs, ok := parent.(*redactingSpan); ok {
+		parent = rs.Span
+	}
+	ChildOf(parent)(s.Span)
+}
+
+func (s *redactingSpan) SetTag(key, value string) {
+	if s.redactor.Sensitive(key) {
+		value = redact.Mask
+	} else {
+		value = s.redactor.String(value)
+	}
+	s.Span.SetTag(key, value)
+}
+
+func (s *redactingSpan) SetError(err error) {
+	// The original error is not wrapped, so exporters cannot reach its message
+	if err != nil {
+		if msg := s.redactor.String(err.Error()); msg != err.Error() {
+			err = errors.New(msg)
+		}
+	}
+	s.Span.SetError(err)
+}
@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/yourusername/foundation/redact"
+)
+
+func TestRedactingTracer(t *testing.T) {
+	var rec exportRecorder
+	tracer := NewRedactingTracer(NewTracer("users", WithSpanExporter(&rec)), redact.New("password", "token"))
+
+	root := tracer.StartSpan("POST /login")
+	child, _ := StartSpanFromContext(root.Context(), tracer, "db.query")
+	child.SetTag("db.password", "hunter2")
+	child.SetTag("db.statement", "SET token=abc123")
+	child.SetTag("db.user", "ada")
+	child.SetError(errors.New("auth failed: password=hunter2"))
+	child.Finish()
+	root.Finish()
+
+	got := rec.get()
+	if len(got) != 2 {
+		t.Fatalf("exported %v", got)
+	}
+	s := got[0][0]
+	if s.Tags["db.password"] != redact.Mask || s.Tags["db.statement"] != "SET token="+redact.Mask || s.Tags["db.user"] != "ada" {
+		t.Errorf("tags = %v", s.Tags)
+	}
+	if s.Error != "auth failed: password="+redact.Mask {
+		t.Errorf("error = %q", s.Error)
+	}
+	// The redacting wrapper keeps the parent of spans it starts
+	if s.TraceID != got[1][0].TraceID || s.ParentID != got[1][0].SpanID {
+		t.Errorf("child %s/%s is not a child of %s/%s", s.TraceID, s.ParentID, got[1][0].TraceID, got[1][0].SpanID)
+	}
+}
+
+func TestRedactingTracerPropagates(t *testing.T) {
+	tracer := NewRedactingTracer(NewTracer("users"), redact.Default())
+	root := tracer.StartSpan("GET /users")
+	h := make(http.Header)
+	if err := tracer.Inject(root, HTTPHeaders, h); err != nil {
+		t.Fatal(err)
+	}
+	remote, err := tracer.Extract(HTTPHeaders, h)
+	if err != nil {
+		t.Fatal(err)
+	}
+	child := tracer.StartSpan("handler", ChildOf(remote))
+	if got, want := SpanContextFromContext(child.Context()).TraceID, SpanContextFromContext(root.Context()).TraceID; got != want {
+		t.Errorf("child of the extracted span is in trace %s, want %s", got, want)
+	}
+	if NewRedactingTracer(tracer, nil) != tracer {
+		t.Error("a nil Redactor wrapped the tracer")
+	}
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// SamplingParameters describe a span being started, for a Sampler to decide
+// whether its trace is sampled
+type SamplingParameters struct {
+	TraceID string
+	Name    string
+	// Parent is the span context of the parent, the zero value for a root span
+	Parent SpanContext
+	// ParentRemote reports whether the parent was extracted from another process
+	ParentRemote bool
+}
+
+// Sampler decides whether a new span is sampled. Spans of a sampled trace are
+// exported; the decision is propagated to child spans and other services.
+type Sampler interface {
+	ShouldSample(p SamplingParameters) bool
+}
+
+// SamplerFunc adapts a function to a Sampler
+type SamplerFunc func(p SamplingParameters) bool
+
+func (f SamplerFunc) ShouldSample(p SamplingParameters) bool { return f(p) }
+
+// AlwaysSample samples every span
+func AlwaysSample() Sampler {
+	return SamplerFunc(func(SamplingParameters) bool { return true })
+}
+
+// NeverSample samples no span
+func NeverSample() Sampler {
+	return SamplerFunc(func(SamplingParameters) bool { return false })
+}
+
+// TraceIDRatio samples the given share of traces, between 0 and 1. The
+// decision depends only on the trace ID, so every service using the same ratio
+// makes the same decision for a trace.
+func TraceIDRatio(ratio float64) Sampler {
+	if ratio >= 1 {
+		return AlwaysSample()
+	}
+	if ratio <= 0 {
+		return NeverSample()
+	}
+	threshold := uint64(ratio * (1 << 63))
+	return SamplerFunc(func(p SamplingParameters) bool {
+		// The low 64 bits of the trace ID are random in W3C trace IDs
+		id := p.TraceID
+		if len(id) > 16 {
+			id = id[len(id)-16:]
+		}
+		x, err := strconv.ParseUint(id, 16, 64)
+		if err != nil {
+			return false
+		}
+		return x>>1 < threshold
+	})
+}
+
+// ParentBased follows the decision of the parent span, and asks root for
+// spans without a parent
+func ParentBased(root Sampler) Sampler {
+	return SamplerFunc(func(p SamplingParameters) bool {
+		if p.Parent.IsValid() {
+			return p.Parent.Sampled
+		}
+		return root.ShouldSample(p)
+	})
+}
+
+// PerProcedure asks the sampler of rules registered for the span name, such
+// as "/user.v1.UserService/CreateUser" for the server span of that
+// procedure, and fallback for other spans. A leading "/" is ignored on both.
+func PerProcedure(rules map[string]Sampler, fallback Sampler) Sampler {
+	byName := make(map[string]Sampler, len(rules))
+	for name, s := range rules {
+		byName[strings.TrimPrefix(name, "/")] = s
+	}
+	return SamplerFunc(func(p SamplingParameters) bool {
+		if s, ok := byName[strings.TrimPrefix(p.Name, "/")]; ok {
+			return s.ShouldSample(p)
+		}
+		return fallback.ShouldSample(p)
+	})
+}
+
+// RateLimited samples what inner samples, up to perSecond traces per second
+// with bursts of up to burst traces, using a token bucket
+func RateLimited(perSecond float64, burst int, inner Sampler) Sampler {
+	b := &tokenBucket{rate: perSecond, burst: math.Max(float64(burst), 1), now: time.Now}
+	b.tokens = b.burst
+	return SamplerFunc(func(p SamplingParameters) bool {
+		return inner.ShouldSample(p) && b.take()
+	})
+}
+
+// tokenBucket refills rate tokens per second up to burst
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	now    func() time.Time
+}
+
+func (b *tokenBucket) take() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	}
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
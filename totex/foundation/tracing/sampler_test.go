@@ -0,0 +1,158 @@
+package tracing
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestTraceIDRatioThreshold(t *testing.T) {
+	half := TraceIDRatio(0.5)
+	tests := []struct {
+		traceID string
+		want    bool
+	}{
+		// Only the low 64 bits count, compared against ratio * 2^64
+		{"ffffffffffffffff7fffffffffffffff", true},
+		{"00000000000000008000000000000000", false},
+		{"0000000000000000", true},
+		{"7ffffffffffffffe", true},
+		{"ffffffffffffffff", false},
+		{"not-a-trace-id", false},
+	}
+	for _, tt := range tests {
+		if got := half.ShouldSample(SamplingParameters{TraceID: tt.traceID}); got != tt.want {
+			t.Errorf("TraceIDRatio(0.5) on %s = %v, want %v", tt.traceID, got, tt.want)
+		}
+	}
+
+	id := newTraceID()
+	if !TraceIDRatio(1.5).ShouldSample(SamplingParameters{TraceID: id}) {
+		t.Error("a ratio above 1 did not sample")
+	}
+	if TraceIDRatio(0).ShouldSample(SamplingParameters{TraceID: "0000000000000000"}) {
+		t.Error("a ratio of 0 sampled")
+	}
+}
+
+func TestTraceIDRatioAgreement(t *testing.T) {
+	// Two services with their own sampler decide alike on every trace, and a
+	// trace sampled at a low ratio is also sampled at a higher one
+	users, orders := TraceIDRatio(0.25), TraceIDRatio(0.25)
+	wider := TraceIDRatio(0.5)
+	const n = 4000
+	sampled := 0
+	for range n {
+		p := SamplingParameters{TraceID: newTraceID()}
+		got := users.ShouldSample(p)
+		if got != orders.ShouldSample(p) {
+			t.Fatalf("services disagree on trace %s", p.TraceID)
+		}
+		if got {
+			sampled++
+			if !wider.ShouldSample(p) {
+				t.Fatalf("trace %s sampled at 0.25 but not at 0.5", p.TraceID)
+			}
+		}
+	}
+	if ratio := float64(sampled) / n; math.Abs(ratio-0.25) > 0.05 {
+		t.Errorf("sampled %.3f of traces, want about 0.25", ratio)
+	}
+}
+
+func TestParentBased(t *testing.T) {
+	s := ParentBased(TraceIDRatio(0.5))
+	sampledRoot := "00000000000000000000000000000000"
+	unsampledRoot := "0000000000000000ffffffffffffffff"
+	tests := []struct {
+		name string
+		p    SamplingParameters
+		want bool
+	}{
+		{"sampled parent", SamplingParameters{TraceID: unsampledRoot, Parent: SpanContext{TraceID: unsampledRoot, SpanID: "01", Sampled: true}}, true},
+		{"unsampled parent", SamplingParameters{TraceID: sampledRoot, Parent: SpanContext{TraceID: sampledRoot, SpanID: "01"}}, false},
+		{"remote sampled parent", SamplingParameters{TraceID: unsampledRoot, Parent: SpanContext{TraceID: unsampledRoot, SpanID: "01", Sampled: true}, ParentRemote: true}, true},
+		{"root asks the root sampler", SamplingParameters{TraceID: sampledRoot}, true},
+		{"root not sampled", SamplingParameters{TraceID: unsampledRoot}, false},
+		{"invalid parent counts as none", SamplingParameters{TraceID: sampledRoot, Parent: SpanContext{TraceID: sampledRoot}}, true},
+	}
+	for _, tt := range tests {
+		if got := s.ShouldSample(tt.p); got != tt.want {
+			t.Errorf("%s: ShouldSample = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestPerProcedure(t *testing.T) {
+	s := PerProcedure(map[string]Sampler{
+		"/user.v1.UserService/CreateUser": AlwaysSample(),
+		"user.v1.UserService/GetUser":     NeverSample(),
+	}, TraceIDRatio(0))
+	tests := []struct {
+		name string
+		want bool
+	}{
+		// Server spans are named without the leading "/", procedures with it
+		{"user.v1.UserService/CreateUser", true},
+		{"/user.v1.UserService/CreateUser", true},
+		{"user.v1.UserService/GetUser", false},
+		{"/user.v1.UserService/GetUser", false},
+		{"user.v1.UserService/DeleteUser", false}, // the fallback
+	}
+	for _, tt := range tests {
+		if got := s.ShouldSample(SamplingParameters{TraceID: newTraceID(), Name: tt.name}); got != tt.want {
+			t.Errorf("%s: ShouldSample = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !PerProcedure(nil, AlwaysSample()).ShouldSample(SamplingParameters{Name: "anything"}) {
+		t.Error("the fallback was not asked")
+	}
+}
+
+func TestRateLimitedBurst(t *testing.T) {
+	// A negligible refill rate leaves only the burst
+	s := RateLimited(1e-9, 3, AlwaysSample())
+	sampled := 0
+	for range 10 {
+		if s.ShouldSample(SamplingParameters{TraceID: newTraceID()}) {
+			sampled++
+		}
+	}
+	if sampled != 3 {
+		t.Errorf("sampled %d traces, want the burst of 3", sampled)
+	}
+
+	// Traces the inner sampler rejects take no token
+	rejected := RateLimited(1e-9, 1, NeverSample())
+	for range 5 {
+		if rejected.ShouldSample(SamplingParameters{}) {
+			t.Fatal("sampled a trace the inner sampler rejected")
+		}
+	}
+}
+
+func TestTokenBucketRefills(t *testing.T) {
+	now := time.Unix(1000, 0)
+	b := &tokenBucket{rate: 2, burst: 4, tokens: 4, now: func() time.Time { return now }}
+	take := func(n int) int {
+		got := 0
+		for range n {
+			if b.take() {
+				got++
+			}
+		}
+		return got
+	}
+	if got := take(10); got != 4 {
+		t.Fatalf("took %d tokens from a full bucket of 4", got)
+	}
+	now = now.Add(time.Second)
+	if got := take(10); got != 2 {
+		t.Errorf("took %d tokens after a second at 2/s", got)
+	}
+	// Refills stop at the burst
+	now = now.Add(time.Hour)
+	if got := take(10); got != 4 {
+		t.Errorf("took %d tokens after an hour, want the burst of 4", got)
+	}
+}
@@ -0,0 +1,256 @@
+package tracing
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// SpanData is a finished span, as handed to a SpanExporter
+type SpanData struct {
+	TraceID  string
+	SpanID   string
+	ParentID string // empty for a root span
+	Name     string
+	Start    time.Time
+	End      time.Time
+	Tags     map[string]string
+	Error    string // message of the error set on the span, if any
+	Sampled  bool
+}
+
+// Duration returns how long the span ran
+func (d SpanData) Duration() time.Duration { return d.End.Sub(d.Start) }
+
+// SpanExporter receives the finished spans of sampled traces. ExportSpans is
+// called as spans finish and must not block for long.
+type SpanExporter interface {
+	ExportSpans(spans []SpanData)
+}
+
+// TracerOption configures a StandardTracer
+type TracerOption func(*tracerOptions)
+
+type tracerOptions struct {
+	sampler     Sampler
+	exporter    SpanExporter
+	tail        *TailPolicy
+	baggageTags []string
+}
+
+// WithSampler sets the sampler deciding which traces are sampled,
+// ParentBased(AlwaysSample()) by default
+func WithSampler(s Sampler) TracerOption {
+	return func(o *tracerOptions) {
+		o.sampler = s
+	}
+}
+
+// WithSpanExporter sets where the spans of sampled traces go once finished
+func WithSpanExporter(e SpanExporter) TracerOption {
+	return func(o *tracerOptions) {
+		o.exporter = e
+	}
+}
+
+// WithTailSampling buffers the spans of traces the sampler did not sample and
+// exports those that match p once their local root span finishes
+func WithTailSampling(p TailPolicy) TracerOption {
+	return func(o *tracerOptions) {
+		o.tail = &p
+	}
+}
+
+// WithBaggageTags copies the baggage members under keys into the tags of
+// every span carrying them
+func WithBaggageTags(keys ...string) TracerOption {
+	return func(o *tracerOptions) {
+		o.baggageTags = keys
+	}
+}
+
+// StandardTracer is a Tracer that generates W3C trace and span IDs, samples
+// traces with a Sampler and hands finished spans to a SpanExporter.
+// Spans of traces that are not sampled record nothing, unless tail sampling
+// is enabled.
+type StandardTracer struct {
+	name        string
+	sampler     Sampler
+	exporter    SpanExporter
+	tail        *tailBuffer
+	baggageTags []string
+}
+
+// NewTracer creates a StandardTracer named name
+func NewTracer(name string, opts ...TracerOption) *StandardTracer {
+	o := tracerOptions{sampler: ParentBased(AlwaysSample())}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	t := &StandardTracer{name: name, sampler: o.sampler, exporter: o.exporter, baggageTags: o.baggageTags}
+	if o.tail != nil {
+		t.tail = newTailBuffer(*o.tail, t.export)
+	}
+	return t
+}
+
+func (t *StandardTracer) Name() string { return t.name }
+
+// StartSpan starts a span. Options run before the sampling decision, so a
+// parent set with ChildOf is taken into account.
+func (t *StandardTracer) StartSpan(name string, opts ...SpanOption) Span {
+	s := &span{tracer: t, data: SpanData{TraceID: newTraceID(), SpanID: newSpanID(), Name: name}}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.data.Sampled = t.sampler.ShouldSample(SamplingParameters{
+		TraceID:      s.data.TraceID,
+		Name:         name,
+		Parent:       s.parent,
+		ParentRemote: s.parentRemote,
+	})
+	s.recording = s.data.Sampled || t.tail != nil
+	if s.recording {
+		s.data.Start = time.Now()
+		s.data.Tags = make(map[string]string)
+		for _, key := range t.baggageTags {
+			if v, ok := s.baggage.Get(key); ok {
+				s.data.Tags[key] = v
+			}
+		}
+	}
+	return s
+}
+
+// Shutdown stops the exporter, if it can be stopped, after it has sent the
+// spans already finished
+func (t *StandardTracer) Shutdown(ctx context.Context) error {
+	if e, ok := t.exporter.(interface{ Shutdown(context.Context) error }); ok {
+		return e.Shutdown(ctx)
+	}
+	return nil
+}
+
+func (t *StandardTracer) export(spans []SpanData) {
+	if t.exporter != nil {
+		t.exporter.ExportSpans(spans)
+	}
+}
+
+// span is a span of a StandardTracer
+type span struct {
+	tracer *StandardTracer
+
+	mu           sync.Mutex
+	data         SpanData
+	parent       SpanContext
+	parentRemote bool
+	baggage      Baggage
+	recording    bool
+	finished     bool
+	remote       bool // extracted from another process, never finished here
+}
+
+// SetParent makes the span a child of parent, which may be a span of any
+// tracer carrying a valid span context. The span inherits the parent's baggage.
+func (s *span) SetParent(parent Span) {
+	for {
+		u, ok := parent.(interface{ Unwrap() Span })
+		if !ok {
+			break
+		}
+		parent = u.Unwrap()
+	}
+	var sc SpanContext
+	var baggage Baggage
+	remote := false
+	if p, ok := parent.(*span); ok {
+		sc, baggage, remote = p.spanContext(), p.baggage, p.remote
+	} else if parent != nil {
+		ctx := parent.Context()
+		sc, baggage, remote = SpanContextFromContext(ctx), BaggageFromContext(ctx), true
+	}
+	if !sc.IsValid() {
+		return
+	}
+	s.parent, s.parentRemote, s.baggage = sc, remote, baggage
+	s.data.TraceID, s.data.ParentID = sc.TraceID, sc.SpanID
+}
+
+// SetBaggage replaces the baggage the span carries
+func (s *span) SetBaggage(b Baggage) {
+	s.baggage = b
+}
+
+func (s *span) SetTag(key, value string) {
+	if !s.recording {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Tags[key] = value
+}
+
+func (s *span) SetError(err error) {
+	if !s.recording || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data.Error = err.Error()
+}
+
+func (s *span) Finish() {
+	if !s.recording || s.remote {
+		return
+	}
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	s.data.End = time.Now()
+	data := s.data
+	data.Tags = make(map[string]string, len(s.data.Tags))
+	for k, v := range s.data.Tags {
+		data.Tags[k] = v
+	}
+	s.mu.Unlock()
+
+	if data.Sampled {
+		s.tracer.export([]SpanData{data})
+	} else {
+		// A span without a parent in this process ends the local part of its trace
+		s.tracer.tail.add(data, !s.parent.IsValid() || s.parentRemote)
+	}
+}
+
+// Context returns a context carrying the span, its span context and its baggage
+func (s *span) Context() context.Context {
+	ctx := ContextWithSpanContext(context.Background(), s.spanContext())
+	if s.baggage.Len() > 0 {
+		ctx = ContextWithBaggage(ctx, s.baggage)
+	}
+	return ContextWithSpan(ctx, s)
+}
+
+func (s *span) spanContext() SpanContext {
+	return SpanContext{TraceID: s.data.TraceID, SpanID: s.data.SpanID, Sampled: s.data.Sampled}
+}
+
+func newTraceID() string {
+	var b [16]byte
+	binary.BigEndian.PutUint64(b[:8], rand.Uint64())
+	binary.BigEndian.PutUint64(b[8:], rand.Uint64())
+	return hex.EncodeToString(b[:])
+}
+
+func newSpanID() string {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], rand.Uint64())
+	return hex.EncodeToString(b[:])
+}
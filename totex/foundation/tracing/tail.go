@@ -0,0 +1,109 @@
+package tracing
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	defaultTailMaxTraces = 1000
+	defaultTailMaxSpans  = 1000
+)
+
+// TailPolicy selects the traces kept by tail sampling. A trace is kept if any
+// of its spans has an error, when Errors is set, or if its local root span
+// ran longer than Latency, when Latency is positive.
+type TailPolicy struct {
+	Errors  bool
+	Latency time.Duration
+	// MaxTraces bounds the traces buffered at once, 1000 by default; the
+	// oldest trace is dropped to make room
+	MaxTraces int
+	// MaxSpans bounds the spans buffered per trace, 1000 by default; further
+	// spans are dropped
+	MaxSpans int
+}
+
+// tailBuffer holds the spans of unsampled traces until their local root
+// span finishes, then exports them if the trace matches the policy. Other
+// services in the trace made their own decision, so a kept trace may only
+// contain the spans of this process.
+type tailBuffer struct {
+	policy TailPolicy
+	export func([]SpanData)
+
+	mu     sync.Mutex
+	traces map[string]*tailTrace
+	order  []string // trace IDs in the order they were first buffered
+}
+
+type tailTrace struct {
+	spans []SpanData
+	keep  bool
+}
+
+func newTailBuffer(p TailPolicy, export func([]SpanData)) *tailBuffer {
+	if p.MaxTraces <= 0 {
+		p.MaxTraces = defaultTailMaxTraces
+	}
+	if p.MaxSpans <= 0 {
+		p.MaxSpans = defaultTailMaxSpans
+	}
+	return &tailBuffer{policy: p, export: export, traces: make(map[string]*tailTrace)}
+}
+
+// add buffers a finished span and decides on its trace when root is set
+func (b *tailBuffer) add(s SpanData, root bool) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	t, ok := b.traces[s.TraceID]
+	if !ok {
+		b.evict()
+		t = &tailTrace{}
+		b.traces[s.TraceID] = t
+		b.order = append(b.order, s.TraceID)
+	}
+	if len(t.spans) < b.policy.MaxSpans {
+		t.spans = append(t.spans, s)
+	}
+	if b.policy.Errors && s.Error != "" {
+		t.keep = true
+	}
+	if root && b.policy.Latency > 0 && s.Duration() > b.policy.Latency {
+		t.keep = true
+	}
+	if !root {
+		b.mu.Unlock()
+		return
+	}
+	delete(b.traces, s.TraceID)
+	b.mu.Unlock()
+
+	if t.keep {
+		for i := range t.spans {
+			t.spans[i].Sampled = true
+		}
+		b.export(t.spans)
+	}
+}
+
+// evict drops the oldest buffered traces while the buffer is full. Spans
+// finishing after their local root start a new trace that is evicted in turn.
+func (b *tailBuffer) evict() {
+	for len(b.traces) >= b.policy.MaxTraces && len(b.order) > 0 {
+		delete(b.traces, b.order[0])
+		b.order = b.order[1:]
+	}
+	// order still lists the traces decided since; drop them once they dominate
+	if len(b.order) >= 2*b.policy.MaxTraces {
+		live := make([]string, 0, len(b.traces))
+		for _, id := range b.order {
+			if _, ok := b.traces[id]; ok {
+				live = append(live, id)
+			}
+		}
+		b.order = live
+	}
+}
@@ -0,0 +1,130 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// exportRecorder collects the batches a tail buffer exports
+type exportRecorder struct {
+	mu      sync.Mutex
+	batches [][]SpanData
+}
+
+func (r *exportRecorder) ExportSpans(spans []SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.batches = append(r.batches, spans)
+}
+
+func (r *exportRecorder) get() [][]SpanData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([][]SpanData(nil), r.batches...)
+}
+
+// tailSpan is a finished span of trace that ran for d
+func tailSpan(trace, name string, d time.Duration, err string) SpanData {
+	return SpanData{TraceID: trace, SpanID: name, Name: name, Start: testStart, End: testStart.Add(d), Error: err}
+}
+
+func TestTailKeepsErrors(t *testing.T) {
+	var rec exportRecorder
+	b := newTailBuffer(TailPolicy{Errors: true}, rec.ExportSpans)
+
+	b.add(tailSpan("a", "db.query", time.Millisecond, "connection reset"), false)
+	b.add(tailSpan("a", "GET /users", 2*time.Millisecond, ""), true)
+	b.add(tailSpan("b", "db.query", time.Millisecond, ""), false)
+	b.add(tailSpan("b", "GET /users", 2*time.Millisecond, ""), true)
+
+	got := rec.get()
+	if len(got) != 1 || len(got[0]) != 2 || got[0][0].TraceID != "a" {
+		t.Fatalf("exported %v, want only the failed trace", got)
+	}
+	for _, s := range got[0] {
+		if !s.Sampled {
+			t.Errorf("kept span %s not marked sampled", s.Name)
+		}
+	}
+	if len(b.traces) != 0 {
+		t.Errorf("%d traces still buffered after their roots finished", len(b.traces))
+	}
+}
+
+func TestTailKeepsSlowRoots(t *testing.T) {
+	var rec exportRecorder
+	b := newTailBuffer(TailPolicy{Latency: 100 * time.Millisecond}, rec.ExportSpans)
+
+	// Only the local root's duration counts, and errors are ignored without Errors
+	b.add(tailSpan("a", "db.query", time.Second, "timeout"), false)
+	b.add(tailSpan("a", "GET /users", 50*time.Millisecond, ""), true)
+	b.add(tailSpan("b", "GET /users", 150*time.Millisecond, ""), true)
+
+	got := rec.get()
+	if len(got) != 1 || got[0][0].TraceID != "b" {
+		t.Fatalf("exported %v, want only the slow trace", got)
+	}
+}
+
+func TestTailEvictsOldestTrace(t *testing.T) {
+	var rec exportRecorder
+	b := newTailBuffer(TailPolicy{Errors: true, MaxTraces: 2, MaxSpans: 2}, rec.ExportSpans)
+
+	b.add(tailSpan("a", "child", 0, "failed"), false)
+	b.add(tailSpan("b", "child", 0, "failed"), false)
+	b.add(tailSpan("c", "child", 0, "failed"), false) // evicts a
+	if _, ok := b.traces["a"]; ok || len(b.traces) != 2 {
+		t.Fatalf("buffered %d traces, want a evicted", len(b.traces))
+	}
+	b.add(tailSpan("a", "root", 0, ""), true) // a started over, without its error
+	b.add(tailSpan("c", "child2", 0, ""), false)
+	b.add(tailSpan("c", "child3", 0, ""), false) // over MaxSpans
+	b.add(tailSpan("c", "root", 0, ""), true)
+
+	got := rec.get()
+	if len(got) != 1 || got[0][0].TraceID != "c" || len(got[0]) != 2 {
+		t.Fatalf("exported %v, want c with its first two spans", got)
+	}
+}
+
+func TestTailOrderStaysBounded(t *testing.T) {
+	b := newTailBuffer(TailPolicy{Errors: true, MaxTraces: 10}, func([]SpanData) {})
+	for i := range 10000 {
+		id := fmt.Sprint(i)
+		b.add(tailSpan(id, "child", 0, ""), false)
+		b.add(tailSpan(id, "root", 0, ""), true)
+		if len(b.order) > 2*b.policy.MaxTraces {
+			t.Fatalf("order holds %d trace IDs after %d traces", len(b.order), i+1)
+		}
+	}
+	if len(b.traces) != 0 {
+		t.Errorf("%d traces still buffered", len(b.traces))
+	}
+}
+
+func TestTracerTailSampling(t *testing.T) {
+	var rec exportRecorder
+	tracer := NewTracer("users", WithSampler(NeverSample()), WithSpanExporter(&rec), WithTailSampling(TailPolicy{Errors: true}))
+
+	root := tracer.StartSpan("GET /users")
+	child, _ := StartSpanFromContext(root.Context(), tracer, "db.query")
+	child.SetError(errors.New("connection reset"))
+	child.Finish()
+	if len(rec.get()) != 0 {
+		t.Fatal("exported before the local root finished")
+	}
+	root.Finish()
+
+	ok := tracer.StartSpan("GET /health")
+	ok.Finish()
+
+	got := rec.get()
+	if len(got) != 1 || len(got[0]) != 2 || got[0][1].Name != "GET /users" {
+		t.Fatalf("exported %v, want the failed trace", got)
+	}
+	tracer.Shutdown(context.Background())
+}
@@ -0,0 +1,128 @@
+package tracing
+
+import "encoding/binary"
+
+// thriftType is a Thrift field or element type
+type thriftType int
+
+const (
+	thriftBool thriftType = iota
+	thriftI32
+	thriftI64
+	thriftString
+	thriftStruct
+	thriftList
+)
+
+// Type ids of the binary and compact protocols, indexed by thriftType
+var (
+	binaryTypes  = [...]byte{2, 8, 10, 11, 12, 15}
+	compactTypes = [...]byte{1, 5, 6, 8, 12, 9}
+)
+
+// thriftWriter encodes Thrift structs in the binary or the compact protocol,
+// enough of both for the Jaeger collector and agent
+type thriftWriter struct {
+	buf     []byte
+	compact bool
+	last    []int16 // last field id per open struct, for compact field deltas
+}
+
+func (w *thriftWriter) structBegin() {
+	w.last = append(w.last, 0)
+}
+
+func (w *thriftWriter) structEnd() {
+	w.buf = append(w.buf, 0) // field stop
+	w.last = w.last[:len(w.last)-1]
+}
+
+func (w *thriftWriter) field(id int16, t thriftType) {
+	if !w.compact {
+		w.buf = append(w.buf, binaryTypes[t])
+		w.buf = binary.BigEndian.AppendUint16(w.buf, uint16(id))
+		return
+	}
+	w.fieldHeader(id, compactTypes[t])
+}
+
+func (w *thriftWriter) fieldHeader(id int16, typ byte) {
+	last := &w.last[len(w.last)-1]
+	if delta := id - *last; delta > 0 && delta <= 15 {
+		w.buf = append(w.buf, byte(delta)<<4|typ)
+	} else {
+		w.buf = append(w.buf, typ)
+		w.buf = binary.AppendUvarint(w.buf, zigzag(int64(id)))
+	}
+	*last = id
+}
+
+// boolField writes a bool field; the compact protocol folds the value into the header
+func (w *thriftWriter) boolField(id int16, v bool) {
+	if !w.compact {
+		w.field(id, thriftBool)
+		w.buf = append(w.buf, boolByte(v))
+		return
+	}
+	typ := byte(2)
+	if v {
+		typ = 1
+	}
+	w.fieldHeader(id, typ)
+}
+
+func (w *thriftWriter) i32(v int32) {
+	if w.compact {
+		w.buf = binary.AppendUvarint(w.buf, zigzag(int64(v)))
+	} else {
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(v))
+	}
+}
+
+func (w *thriftWriter) i64(v int64) {
+	if w.compact {
+		w.buf = binary.AppendUvarint(w.buf, zigzag(v))
+	} else {
+		w.buf = binary.BigEndian.AppendUint64(w.buf, uint64(v))
+	}
+}
+
+func (w *thriftWriter) string(s string) {
+	if w.compact {
+		w.buf = binary.AppendUvarint(w.buf, uint64(len(s)))
+	} else {
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(len(s)))
+	}
+	w.buf = append(w.buf, s...)
+}
+
+func (w *thriftWriter) listBegin(elem thriftType, n int) {
+	if !w.compact {
+		w.buf = append(w.buf, binaryTypes[elem])
+		w.buf = binary.BigEndian.AppendUint32(w.buf, uint32(n))
+		return
+	}
+	if n < 15 {
+		w.buf = append(w.buf, byte(n)<<4|compactTypes[elem])
+	} else {
+		w.buf = append(w.buf, 0xf0|compactTypes[elem])
+		w.buf = binary.AppendUvarint(w.buf, uint64(n))
+	}
+}
+
+// onewayMessageBegin starts a compact protocol oneway call of method
+func (w *thriftWriter) onewayMessageBegin(method string, seq int32) {
+	const protocolID, version, oneway = 0x82, 1, 4
+	w.buf = append(w.buf, protocolID, version|oneway<<5)
+	w.buf = binary.AppendUvarint(w.buf, uint64(uint32(seq)))
+	w.string(method)
+}
+
+func zigzag(v int64) uint64 { return uint64(v<<1) ^ uint64(v>>63) }
+
+func boolByte(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,218 @@
+package tracing
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// thriftReader decodes the binary and compact protocols independently of
+// thriftWriter, into structs of map[int16]any holding bool, int32, int64,
+// string, []any and nested structs
+type thriftReader struct {
+	t       *testing.T
+	buf     []byte
+	compact bool
+}
+
+func (r *thriftReader) byte() byte {
+	r.t.Helper()
+	if len(r.buf) == 0 {
+		r.t.Fatal("thrift: unexpected end of input")
+	}
+	b := r.buf[0]
+	r.buf = r.buf[1:]
+	return b
+}
+
+func (r *thriftReader) bytes(n int) []byte {
+	r.t.Helper()
+	if n < 0 || n > len(r.buf) {
+		r.t.Fatalf("thrift: %d bytes wanted, %d left", n, len(r.buf))
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *thriftReader) uvarint() uint64 {
+	r.t.Helper()
+	v, n := binary.Uvarint(r.buf)
+	if n <= 0 {
+		r.t.Fatal("thrift: bad varint")
+	}
+	r.buf = r.buf[n:]
+	return v
+}
+
+func (r *thriftReader) zigzag() int64 {
+	v := r.uvarint()
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// messageBegin reads a compact protocol message header
+func (r *thriftReader) messageBegin() (method string, typ byte, seq int32) {
+	r.t.Helper()
+	if id := r.byte(); id != 0x82 {
+		r.t.Fatalf("thrift: protocol id %#x, want 0x82", id)
+	}
+	vt := r.byte()
+	if vt&0x1f != 1 {
+		r.t.Fatalf("thrift: version %d, want 1", vt&0x1f)
+	}
+	seq = int32(r.uvarint())
+	method = string(r.bytes(int(r.uvarint())))
+	return method, vt >> 5, seq
+}
+
+func (r *thriftReader) readStruct() map[int16]any {
+	r.t.Helper()
+	fields := make(map[int16]any)
+	var last int16
+	for {
+		typ := r.byte()
+		if typ == 0 {
+			return fields
+		}
+		var id int16
+		if !r.compact {
+			id = int16(binary.BigEndian.Uint16(r.bytes(2)))
+		} else {
+			delta := int16(typ >> 4)
+			typ &= 0x0f
+			if delta == 0 {
+				id = int16(r.zigzag())
+			} else {
+				id = last + delta
+			}
+			last = id
+			// Compact bools carry their value in the type
+			if typ == 1 || typ == 2 {
+				fields[id] = typ == 1
+				continue
+			}
+		}
+		if _, dup := fields[id]; dup {
+			r.t.Fatalf("thrift: field %d repeated", id)
+		}
+		fields[id] = r.value(typ)
+	}
+}
+
+func (r *thriftReader) value(typ byte) any {
+	r.t.Helper()
+	if r.compact {
+		switch typ {
+		case 5:
+			return int32(r.zigzag())
+		case 6:
+			return r.zigzag()
+		case 8:
+			return string(r.bytes(int(r.uvarint())))
+		case 9:
+			header := r.byte()
+			n := int(header >> 4)
+			if n == 15 {
+				n = int(r.uvarint())
+			}
+			return r.list(header&0x0f, n)
+		case 12:
+			return r.readStruct()
+		}
+	} else {
+		switch typ {
+		case 2:
+			return r.byte() == 1
+		case 8:
+			return int32(binary.BigEndian.Uint32(r.bytes(4)))
+		case 10:
+			return int64(binary.BigEndian.Uint64(r.bytes(8)))
+		case 11:
+			return string(r.bytes(int(binary.BigEndian.Uint32(r.bytes(4)))))
+		case 12:
+			return r.readStruct()
+		case 15:
+			elem := r.byte()
+			return r.list(elem, int(binary.BigEndian.Uint32(r.bytes(4))))
+		}
+	}
+	r.t.Fatalf("thrift: unknown type %d", typ)
+	return nil
+}
+
+func (r *thriftReader) list(elem byte, n int) []any {
+	r.t.Helper()
+	out := make([]any, n)
+	for i := range out {
+		out[i] = r.value(elem)
+	}
+	return out
+}
+
+// encodeSample writes a struct exercising every encoding branch: small and
+// large field deltas, ids going down, bools, and short and long lists
+func encodeSample(w *thriftWriter) {
+	w.structBegin()
+	w.field(1, thriftI32)
+	w.i32(-7)
+	w.field(2, thriftI64)
+	w.i64(-1 << 40)
+	w.field(20, thriftString) // delta above 15
+	w.string("héllo")
+	w.field(3, thriftI64) // lower id than the previous field
+	w.i64(1<<62 + 5)
+	w.boolField(4, true)
+	w.boolField(5, false)
+	w.field(6, thriftList)
+	w.listBegin(thriftI32, 3)
+	for i := range 3 {
+		w.i32(int32(i))
+	}
+	w.field(7, thriftList)
+	w.listBegin(thriftStruct, 20)
+	for i := range 20 {
+		w.structBegin()
+		w.field(1, thriftString)
+		w.string(string(rune('a' + i)))
+		w.structEnd()
+	}
+	w.structEnd()
+}
+
+func TestThriftRoundTrip(t *testing.T) {
+	for _, compact := range []bool{false, true} {
+		w := &thriftWriter{compact: compact}
+		encodeSample(w)
+		r := &thriftReader{t: t, buf: w.buf, compact: compact}
+		got := r.readStruct()
+		if len(r.buf) != 0 {
+			t.Errorf("compact=%v: %d trailing bytes", compact, len(r.buf))
+		}
+
+		if got[1] != int32(-7) || got[2] != int64(-1<<40) || got[3] != int64(1<<62+5) {
+			t.Errorf("compact=%v: integers = %v %v %v", compact, got[1], got[2], got[3])
+		}
+		if got[20] != "héllo" {
+			t.Errorf("compact=%v: string = %v", compact, got[20])
+		}
+		if got[4] != true || got[5] != false {
+			t.Errorf("compact=%v: bools = %v %v", compact, got[4], got[5])
+		}
+		if ints := got[6].([]any); len(ints) != 3 || ints[2] != int32(2) {
+			t.Errorf("compact=%v: short list = %v", compact, ints)
+		}
+		structs := got[7].([]any)
+		if len(structs) != 20 || structs[19].(map[int16]any)[1] != "t" {
+			t.Errorf("compact=%v: long list = %v", compact, structs)
+		}
+	}
+}
+
+func TestThriftOnewayMessage(t *testing.T) {
+	w := &thriftWriter{compact: true}
+	w.onewayMessageBegin("emitBatch", 3)
+	r := &thriftReader{t: t, buf: w.buf, compact: true}
+	method, typ, seq := r.messageBegin()
+	if method != "emitBatch" || typ != 4 || seq != 3 {
+		t.Errorf("message = %s, type %d, seq %d; want emitBatch, oneway, 3", method, typ, seq)
+	}
+}
@@ -0,0 +1,136 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// zipkinPath is where Zipkin accepts spans in the v2 API
+const zipkinPath = "/api/v2/spans"
+
+// ZipkinExporter sends spans to Zipkin as v2 JSON over HTTP. Spans are queued,
+// sent in batches and retried when Zipkin is unreachable or overloaded.
+type ZipkinExporter struct {
+	*batcher
+	url     string
+	service string
+	client  *http.Client
+}
+
+// NewZipkinExporter creates an exporter sending the spans of service to the
+// Zipkin at endpoint; an endpoint without a path gets /api/v2/spans
+func NewZipkinExporter(endpoint, service string, opts ...ExporterOption) *ZipkinExporter {
+	e := &ZipkinExporter{url: endpointURL(endpoint, zipkinPath), service: service, client: &http.Client{}}
+	e.batcher = newBatcher(e.send, opts)
+	return e
+}
+
+// zipkinSpan is a span in the Zipkin v2 JSON model
+type zipkinSpan struct {
+	TraceID       string            `json:"traceId"`
+	ID            string            `json:"id"`
+	ParentID      string            `json:"parentId,omitempty"`
+	Name          string            `json:"name,omitempty"`
+	Kind          string            `json:"kind,omitempty"`
+	Timestamp     int64             `json:"timestamp"`
+	Duration      int64             `json:"duration"`
+	LocalEndpoint zipkinEndpoint    `json:"localEndpoint"`
+	Tags          map[string]string `json:"tags,omitempty"`
+}
+
+type zipkinEndpoint struct {
+	ServiceName string `json:"serviceName"`
+}
+
+func (e *ZipkinExporter) send(ctx context.Context, spans []SpanData) error {
+	out := make([]zipkinSpan, len(spans))
+	for i, s := range spans {
+		tags, kind := splitKind(s)
+		if s.Error != "" {
+			tags["error"] = s.Error
+		}
+		out[i] = zipkinSpan{
+			TraceID:       s.TraceID,
+			ID:            s.SpanID,
+			ParentID:      s.ParentID,
+			Name:          s.Name,
+			Kind:          strings.ToUpper(kind),
+			Timestamp:     s.Start.UnixMicro(),
+			Duration:      max(s.Duration().Microseconds(), 1),
+			LocalEndpoint: zipkinEndpoint{ServiceName: e.service},
+			Tags:          tags,
+		}
+	}
+	body, err := json.Marshal(out)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return doExport(e.client, req)
+}
+
+// Shutdown sends the queued spans, stops the exporter and closes its connections
+func (e *ZipkinExporter) Shutdown(ctx context.Context) error {
+	defer e.client.CloseIdleConnections()
+	return e.batcher.Shutdown(ctx)
+}
+
+// SpanKindTag is the span tag holding the kind of a span: "server",
+// "client", "producer" or "consumer". Exporters map it to the kind of their
+// span model instead of sending it as a tag.
+const SpanKindTag = "span.kind"
+
+// splitKind returns a copy of the tags of s without the kind, and the kind
+func splitKind(s SpanData) (map[string]string, string) {
+	tags := make(map[string]string, len(s.Tags)+1)
+	for k, v := range s.Tags {
+		tags[k] = v
+	}
+	kind := tags[SpanKindTag]
+	delete(tags, SpanKindTag)
+	return tags, kind
+}
+
+// endpointURL adds http:// to an endpoint without a scheme and path to one without a path
+func endpointURL(endpoint, path string) string {
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "http://" + endpoint
+	}
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return endpoint
+	}
+	if u.Path == "" || u.Path == "/" {
+		u.Path = path
+	}
+	return u.String()
+}
+
+// doExport sends req, treating network errors, 429 and 5xx responses as
+// retryable and other non-2xx responses as permanent failures
+func doExport(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return retryable(err)
+	}
+	defer resp.Body.Close()
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	err = fmt.Errorf("%s: %s: %s", req.URL.Redacted(), resp.Status, bytes.TrimSpace(msg))
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return retryable(err)
+	}
+	return err
+}
@@ -0,0 +1,66 @@
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestZipkinExporter(t *testing.T) {
+	received := make(chan []zipkinSpan, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != zipkinPath || r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("request to %s with %s", r.URL.Path, r.Header.Get("Content-Type"))
+		}
+		var spans []zipkinSpan
+		if err := json.NewDecoder(r.Body).Decode(&spans); err != nil {
+			t.Errorf("decode spans: %v", err)
+		}
+		received <- spans
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer srv.Close()
+
+	e := NewZipkinExporter(srv.URL, "users")
+	e.ExportSpans(testSpans)
+	if err := e.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := <-received
+	if len(spans) != 2 {
+		t.Fatalf("received %d spans, want 2", len(spans))
+	}
+	root, child := spans[0], spans[1]
+	if root.TraceID != testSpans[0].TraceID || root.ID != "00000000000000aa" || root.ParentID != "" ||
+		root.Name != "GET /users" || root.Kind != "SERVER" || root.LocalEndpoint.ServiceName != "users" {
+		t.Errorf("root = %+v", root)
+	}
+	if root.Timestamp != testStart.UnixMicro() || root.Duration != 1500 {
+		t.Errorf("root timing = %d+%d", root.Timestamp, root.Duration)
+	}
+	if _, ok := root.Tags[SpanKindTag]; ok || root.Tags["http.method"] != "GET" {
+		t.Errorf("root tags = %v, want the kind moved out of the tags", root.Tags)
+	}
+	if testSpans[0].Tags[SpanKindTag] != "server" {
+		t.Error("exporting modified the span's tags")
+	}
+	if child.ParentID != "00000000000000aa" || child.Kind != "" || child.Tags["error"] != "connection reset" {
+		t.Errorf("child = %+v", child)
+	}
+}
+
+func TestEndpointURL(t *testing.T) {
+	tests := []struct{ endpoint, want string }{
+		{"localhost:9411", "http://localhost:9411/api/v2/spans"},
+		{"http://zipkin:9411/", "http://zipkin:9411/api/v2/spans"},
+		{"https://zipkin.example.com/custom/path", "https://zipkin.example.com/custom/path"},
+	}
+	for _, tt := range tests {
+		if got := endpointURL(tt.endpoint, zipkinPath); got != tt.want {
+			t.Errorf("endpointURL(%q) = %q, want %q", tt.endpoint, got, tt.want)
+		}
+	}
+}
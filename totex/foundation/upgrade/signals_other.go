@@ -0,0 +1,8 @@
+//go:build !unix
+
+package upgrade
+
+import "os"
+
+// Signals are the signals that trigger an upgrade; none on this platform
+var Signals = []os.Signal{}
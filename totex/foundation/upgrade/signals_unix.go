@@ -0,0 +1,11 @@
+//go:build unix
+
+package upgrade
+
+import (
+	"os"
+	"syscall"
+)
+
+// Signals are the signals that trigger an upgrade
+var Signals = []os.Signal{syscall.SIGHUP, syscall.SIGUSR2}
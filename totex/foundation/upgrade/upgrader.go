@@ -0,0 +1,288 @@
+package upgrade
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/yourusername/foundation/logging"
+)
+
+const (
+	// envListeners lists the inherited listeners as "network|address=fd" pairs separated by ";"
+	envListeners = "FOUNDATION_UPGRADE_LISTENERS"
+	// envReadyFD is the descriptor the child writes to once it is serving
+	envReadyFD = "FOUNDATION_UPGRADE_READY_FD"
+)
+
+// ErrUpgradeInProgress is returned when Upgrade is called while another upgrade is running
+var ErrUpgradeInProgress = errors.New("upgrade already in progress")
+
+// ErrAlreadyUpgraded is returned when Upgrade is called after a successful upgrade
+var ErrAlreadyUpgraded = errors.New("process has already been upgraded")
+
+// Upgrader hands listening sockets to a freshly exec'd copy of the binary.
+// The old process keeps serving until the new one reports that it is ready,
+// after which Exit is closed and the old process should drain and stop.
+type Upgrader struct {
+	logger  logging.Logger
+	timeout time.Duration
+
+	mu        sync.Mutex
+	inherited map[string]*os.File
+	listeners map[string]net.Listener
+	ready     *os.File
+	upgrading bool
+	exit      chan struct{}
+	exitOnce  sync.Once
+}
+
+// DefaultTimeout is how long Upgrade waits for the new process when New is given no timeout
+const DefaultTimeout = 30 * time.Second
+
+// New creates an Upgrader, picking up any listeners passed down by a parent
+// process. A timeout <= 0 means DefaultTimeout.
+func New(logger logging.Logger, timeout time.Duration) (*Upgrader, error) {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	u := &Upgrader{
+		logger:    logger,
+		timeout:   timeout,
+		inherited: make(map[string]*os.File),
+		listeners: make(map[string]net.Listener),
+		exit:      make(chan struct{}),
+	}
+
+	if spec := os.Getenv(envListeners); spec != "" {
+		for _, entry := range strings.Split(spec, ";") {
+			key, fdStr, ok := strings.Cut(entry, "=")
+			if !ok {
+				return nil, fmt.Errorf("malformed inherited listener %q", entry)
+			}
+			fd, err := strconv.Atoi(fdStr)
+			if err != nil {
+				return nil, fmt.Errorf("malformed inherited listener %q: %w", entry, err)
+			}
+			u.inherited[key] = os.NewFile(uintptr(fd), key)
+		}
+	}
+	if fdStr := os.Getenv(envReadyFD); fdStr != "" {
+		fd, err := strconv.Atoi(fdStr)
+		if err != nil {
+			return nil, fmt.Errorf("malformed ready descriptor %q: %w", fdStr, err)
+		}
+		u.ready = os.NewFile(uintptr(fd), "upgrade-ready")
+	}
+
+	// Don't leak the handoff state into processes we spawn ourselves
+	os.Unsetenv(envListeners)
+	os.Unsetenv(envReadyFD)
+
+	return u, nil
+}
+
+// HasParent reports whether this process was started by an upgrade
+func (u *Upgrader) HasParent() bool {
+	return u.ready != nil
+}
+
+// Listen returns a listener for the address, reusing one inherited from
+// the parent process when available
+func (u *Upgrader) Listen(network, address string) (net.Listener, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	key := network + "|" + address
+	if f, ok := u.inherited[key]; ok {
+		delete(u.inherited, key)
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("inherit listener %s: %w", key, err)
+		}
+		u.logger.Info("Inherited listener from parent process", "network", network, "address", address)
+		u.listeners[key] = ln
+		return ln, nil
+	}
+
+	ln, err := net.Listen(network, address)
+	if err != nil {
+		return nil, err
+	}
+	u.listeners[key] = ln
+	return ln, nil
+}
+
+// Ready tells the parent process, if any, that this process is serving.
+// Inherited listeners that were not claimed by then are closed.
+func (u *Upgrader) Ready() error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for key, f := range u.inherited {
+		u.logger.Warn("Closing unused inherited listener", "listener", key)
+		f.Close()
+		delete(u.inherited, key)
+	}
+	if u.ready == nil {
+		return nil
+	}
+	defer func() {
+		u.ready.Close()
+		u.ready = nil
+	}()
+	if _, err := u.ready.Write([]byte{1}); err != nil {
+		return fmt.Errorf("notify parent process: %w", err)
+	}
+	return nil
+}
+
+// Exit is closed once a new process has taken over the listeners
+func (u *Upgrader) Exit() <-chan struct{} {
+	return u.exit
+}
+
+// Upgrade starts a new copy of the binary with the current listeners and
+// waits until it reports ready, or fails, or the timeout elapses
+func (u *Upgrader) Upgrade() error {
+	u.mu.Lock()
+	select {
+	case <-u.exit:
+		u.mu.Unlock()
+		return ErrAlreadyUpgraded
+	default:
+	}
+	if u.upgrading {
+		u.mu.Unlock()
+		return ErrUpgradeInProgress
+	}
+	u.upgrading = true
+
+	files, spec, err := u.listenerFiles()
+	u.mu.Unlock()
+	defer func() {
+		u.mu.Lock()
+		u.upgrading = false
+		u.mu.Unlock()
+	}()
+	if err != nil {
+		return err
+	}
+	defer closeFiles(files)
+
+	readR, readW, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("create ready pipe: %w", err)
+	}
+	defer readR.Close()
+
+	exe, err := os.Executable()
+	if err != nil {
+		readW.Close()
+		return fmt.Errorf("resolve executable: %w", err)
+	}
+
+	cmd := exec.Command(exe, os.Args[1:]...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.ExtraFiles = append(files, readW)
+	cmd.Env = append(os.Environ(),
+		envListeners+"="+spec,
+		envReadyFD+"="+strconv.Itoa(3+len(files)),
+	)
+
+	u.logger.Info("Starting upgraded process", "executable", exe)
+	err = cmd.Start()
+	readW.Close()
+	if err != nil {
+		return fmt.Errorf("start upgraded process: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	ready := make(chan error, 1)
+	go func() {
+		buf := make([]byte, 1)
+		if _, err := readR.Read(buf); err != nil {
+			if err == io.EOF {
+				err = errors.New("upgraded process closed the ready pipe without signalling")
+			}
+			ready <- err
+			return
+		}
+		ready <- nil
+	}()
+
+	timer := time.NewTimer(u.timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-ready:
+		if err != nil {
+			cmd.Process.Kill()
+			return err
+		}
+	case err := <-exited:
+		return fmt.Errorf("upgraded process exited before becoming ready: %v", err)
+	case <-timer.C:
+		cmd.Process.Kill()
+		return fmt.Errorf("upgraded process not ready after %s", u.timeout)
+	}
+
+	u.logger.Info("Upgraded process is ready, handing over", "pid", cmd.Process.Pid)
+	u.exitOnce.Do(func() { close(u.exit) })
+	return nil
+}
+
+// Stop closes any inherited listeners that were never claimed
+func (u *Upgrader) Stop() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	for key, f := range u.inherited {
+		f.Close()
+		delete(u.inherited, key)
+	}
+}
+
+// listenerFiles duplicates the active listeners so they can be passed to a child
+func (u *Upgrader) listenerFiles() ([]*os.File, string, error) {
+	type filer interface {
+		File() (*os.File, error)
+	}
+
+	var (
+		files   []*os.File
+		entries []string
+	)
+	for key, ln := range u.listeners {
+		fl, ok := ln.(filer)
+		if !ok {
+			closeFiles(files)
+			return nil, "", fmt.Errorf("listener %s cannot be handed over", key)
+		}
+		f, err := fl.File()
+		if err != nil {
+			closeFiles(files)
+			return nil, "", fmt.Errorf("duplicate listener %s: %w", key, err)
+		}
+		entries = append(entries, key+"="+strconv.Itoa(3+len(files)))
+		files = append(files, f)
+	}
+	return files, strings.Join(entries, ";"), nil
+}
+
+func closeFiles(files []*os.File) {
+	for _, f := range files {
+		f.Close()
+	}
+}
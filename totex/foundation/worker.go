@@ -0,0 +1,305 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/yourusername/foundation/leader"
+)
+
+// RestartMode decides whether a worker is run again after it returns
+type RestartMode int
+
+const (
+	// RestartNever runs the worker once
+	RestartNever RestartMode = iota
+	// RestartOnFailure restarts the worker when it returns an error or panics
+	RestartOnFailure
+	// RestartAlways restarts the worker whenever it returns
+	RestartAlways
+)
+
+// RestartPolicy configures how a supervised worker is restarted. Restarts are
+// delayed by an exponential backoff between MinBackoff and MaxBackoff, which
+// resets once a run has lasted longer than MaxBackoff.
+type RestartPolicy struct {
+	Mode       RestartMode
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+const (
+	defaultMinBackoff = time.Second
+	defaultMaxBackoff = time.Minute
+)
+
+var (
+	// NeverRestart runs a worker once
+	NeverRestart = RestartPolicy{Mode: RestartNever}
+	// RestartOnFailurePolicy restarts a failed worker with the default backoff
+	RestartOnFailurePolicy = RestartPolicy{Mode: RestartOnFailure, MinBackoff: defaultMinBackoff, MaxBackoff: defaultMaxBackoff}
+	// AlwaysRestart restarts a worker whenever it returns, with the default backoff
+	AlwaysRestart = RestartPolicy{Mode: RestartAlways, MinBackoff: defaultMinBackoff, MaxBackoff: defaultMaxBackoff}
+)
+
+// WorkerState is the current state of a supervised worker
+type WorkerState string
+
+const (
+	WorkerPending  WorkerState = "pending"
+	WorkerRunning  WorkerState = "running"
+	WorkerBackoff  WorkerState = "backoff"
+	WorkerFinished WorkerState = "finished"
+	WorkerFailed   WorkerState = "failed"
+)
+
+// WorkerStatus is a snapshot of a supervised worker
+type WorkerStatus struct {
+	Name      string
+	State     WorkerState
+	Restarts  int
+	LastError string
+}
+
+// worker is a background function supervised by the App
+type worker struct {
+	name   string
+	fn     func(ctx context.Context) error
+	policy RestartPolicy
+	done   chan struct{}
+	// launched is guarded by App.workersMu
+	launched bool
+
+	mu      sync.Mutex
+	status  WorkerStatus
+	failing bool
+}
+
+// Go runs fn as a supervised background worker tied to the App's context.
+// Workers added before Start are launched once the servers are up; the App
+// waits for all workers to return during Stop. Names must be unique, as each
+// worker has its own health check.
+func (a *App) Go(name string, fn func(ctx context.Context) error, policy RestartPolicy) error {
+	if policy.MinBackoff <= 0 {
+		policy.MinBackoff = defaultMinBackoff
+	}
+	if policy.MaxBackoff < policy.MinBackoff {
+		policy.MaxBackoff = max(defaultMaxBackoff, policy.MinBackoff)
+	}
+
+	w := &worker{
+		name:   name,
+		fn:     fn,
+		policy: policy,
+		done:   make(chan struct{}),
+		status: WorkerStatus{Name: name, State: WorkerPending},
+	}
+
+	a.workersMu.Lock()
+	defer a.workersMu.Unlock()
+
+	for _, existing := range a.workers {
+		if existing.name == name {
+			return fmt.Errorf("duplicate worker %s", name)
+		}
+	}
+	switch state := a.State(); state {
+	case StateCreated, StateStarting:
+		a.workers = append(a.workers, w)
+	case StateRunning:
+		a.workers = append(a.workers, w)
+		a.launch(w)
+	default:
+		return &StateError{Op: "add worker to", State: state}
+	}
+	a.health.Register("worker:"+name, w.check)
+	return nil
+}
+
+// Workers returns the status of every supervised worker
+func (a *App) Workers() []WorkerStatus {
+	a.workersMu.Lock()
+	defer a.workersMu.Unlock()
+	statuses := make([]WorkerStatus, len(a.workers))
+	for i, w := range a.workers {
+		statuses[i] = w.snapshot()
+	}
+	return statuses
+}
+
+// markRunning launches the workers queued before Start and moves the App to
+// StateRunning without letting a concurrent Go call slip in between
+func (a *App) markRunning() {
+	a.workersMu.Lock()
+	defer a.workersMu.Unlock()
+	for _, w := range a.workers {
+		a.launch(w)
+	}
+	a.transition(StateRunning, nil)
+}
+
+// waitWorkers waits for every worker to return after the App's context is cancelled
+func (a *App) waitWorkers(ctx context.Context) error {
+	a.workersMu.Lock()
+	var workers []*worker
+	for _, w := range a.workers {
+		if w.launched {
+			workers = append(workers, w)
+		}
+	}
+	a.workersMu.Unlock()
+
+	var pending []string
+	for _, w := range workers {
+		select {
+		case <-w.done:
+		case <-ctx.Done():
+			pending = append(pending, w.name)
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("workers did not stop: %v: %w", pending, ctx.Err())
+	}
+	return nil
+}
+
+// launch starts the supervision loop for w; the caller holds workersMu
+func (a *App) launch(w *worker) {
+	w.launched = true
+	go func() {
+		defer close(w.done)
+		a.supervise(w)
+	}()
+}
+
+// supervise runs w until the App's context is cancelled or the policy says stop
+func (a *App) supervise(w *worker) {
+	backoff := w.policy.MinBackoff
+	for {
+		w.mu.Lock()
+		w.status.State = WorkerRunning
+		w.mu.Unlock()
+		a.logger.Info("Starting worker", "worker", w.name)
+
+		began := time.Now()
+		err := a.runWorker(w)
+
+		if a.ctx.Err() != nil {
+			a.logger.Info("Worker stopped", "worker", w.name)
+			w.setState(WorkerFinished, nil)
+			return
+		}
+		if err != nil {
+			a.logger.Error("Worker failed", "worker", w.name, "error", err)
+			a.metrics.Counter("worker_failures_total", 1, "worker", w.name)
+		} else {
+			a.logger.Info("Worker returned", "worker", w.name)
+		}
+
+		restart := w.policy.Mode == RestartAlways || (w.policy.Mode == RestartOnFailure && err != nil)
+		if !restart {
+			if err != nil {
+				w.setState(WorkerFailed, err)
+			} else {
+				w.setState(WorkerFinished, nil)
+			}
+			return
+		}
+
+		if time.Since(began) > w.policy.MaxBackoff {
+			backoff = w.policy.MinBackoff
+		}
+		w.setState(WorkerBackoff, err)
+		a.logger.Info("Restarting worker", "worker", w.name, "backoff", backoff)
+		select {
+		case <-a.ctx.Done():
+			w.setState(WorkerFinished, nil)
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(backoff*2, w.policy.MaxBackoff)
+
+		w.mu.Lock()
+		w.status.Restarts++
+		w.mu.Unlock()
+		a.metrics.Counter("worker_restarts_total", 1, "worker", w.name)
+	}
+}
+
+// runWorker calls the worker function, turning a panic into an error
+func (a *App) runWorker(w *worker) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			a.logger.Error("Worker panicked", "worker", w.name, "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return w.fn(a.ctx)
+}
+
+// setState records the worker state; err is the outcome of the last run, if any
+func (w *worker) setState(state WorkerState, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.status.State = state
+	w.failing = err != nil
+	if err != nil {
+		w.status.LastError = err.Error()
+	}
+}
+
+func (w *worker) snapshot() WorkerStatus {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.status
+}
+
+// check reports the worker as unhealthy while it backs off after a failure or once it has failed for good
+func (w *worker) check(ctx context.Context) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	switch {
+	case w.status.State == WorkerBackoff && w.failing:
+		return fmt.Errorf("restarting after failure (%d restarts): %s", w.status.Restarts, w.status.LastError)
+	case w.status.State == WorkerFailed:
+		return errors.New(w.status.LastError)
+	default:
+		return nil
+	}
+}
+
+// SetLeaderBackend replaces the leader election backend used by GoLeader,
+// e.g. with a leader.SQLBackend shared by all replicas
+func (a *App) SetLeaderBackend(backend leader.Backend) error {
+	a.workersMu.Lock()
+	defer a.workersMu.Unlock()
+	if err := a.requireState("set leader backend on", StateCreated); err != nil {
+		return err
+	}
+	var opts []leader.Option
+	if a.leaderTTL > 0 {
+		opts = append(opts, leader.WithTTL(a.leaderTTL))
+	}
+	a.elector = leader.NewElector(backend, a.logger, opts...)
+	return nil
+}
+
+// GoLeader runs fn as a supervised worker that only runs while this replica
+// holds leadership for name; fn's context is cancelled when leadership is lost
+func (a *App) GoLeader(name string, fn func(ctx context.Context) error) error {
+	a.workersMu.Lock()
+	elector := a.elector
+	a.workersMu.Unlock()
+	if elector == nil {
+		return fmt.Errorf("leader election is not configured")
+	}
+
+	key := a.name + "." + name
+	return a.Go(name, func(ctx context.Context) error {
+		return elector.Run(ctx, key, fn)
+	}, RestartOnFailurePolicy)
+}
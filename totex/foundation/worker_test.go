@@ -0,0 +1,237 @@
+package foundation
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/yourusername/foundation/health"
+)
+
+// fastRestarts restarts workers quickly enough for tests
+func fastRestarts(mode RestartMode) RestartPolicy {
+	return RestartPolicy{Mode: mode, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+// waitFor polls cond until it holds, failing the test after a few seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// workerStatus returns the status of the worker called name
+func workerStatus(app *App, name string) WorkerStatus {
+	for _, s := range app.Workers() {
+		if s.Name == name {
+			return s
+		}
+	}
+	return WorkerStatus{}
+}
+
+// startApp starts app, which is stopped when the test ends
+func startApp(t *testing.T, app *App) {
+	t.Helper()
+	if err := app.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWorkerRestartPolicies(t *testing.T) {
+	failure := errors.New("poll failed")
+	tests := []struct {
+		name     string
+		mode     RestartMode
+		results  []error // returned by successive runs; later runs block until Stop
+		runs     int
+		state    WorkerState
+		restarts int
+	}{
+		{"never after failure", RestartNever, []error{failure}, 1, WorkerFailed, 0},
+		{"never after success", RestartNever, []error{nil}, 1, WorkerFinished, 0},
+		{"on failure until success", RestartOnFailure, []error{failure, failure, nil}, 3, WorkerFinished, 2},
+		{"on failure after success", RestartOnFailure, []error{nil}, 1, WorkerFinished, 0},
+		{"always", RestartAlways, []error{nil, failure, nil}, 4, WorkerRunning, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newTestApp(t)
+			var runs atomic.Int32
+			err := app.Go("poller", func(ctx context.Context) error {
+				run := int(runs.Add(1))
+				if run <= len(tt.results) {
+					return tt.results[run-1]
+				}
+				<-ctx.Done()
+				return nil
+			}, fastRestarts(tt.mode))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if s := workerStatus(app, "poller"); s.State != WorkerPending {
+				t.Errorf("state before Start = %s", s.State)
+			}
+			startApp(t, app)
+
+			waitFor(t, tt.name, func() bool {
+				s := workerStatus(app, "poller")
+				return int(runs.Load()) == tt.runs && s.State == tt.state
+			})
+			if s := workerStatus(app, "poller"); s.Restarts != tt.restarts {
+				t.Errorf("restarts = %d, want %d", s.Restarts, tt.restarts)
+			}
+			// Give a worker that should not be restarted the chance to be
+			time.Sleep(20 * time.Millisecond)
+			if n := int(runs.Load()); n != tt.runs {
+				t.Errorf("ran %d times, want %d", n, tt.runs)
+			}
+		})
+	}
+}
+
+func TestWorkerPanic(t *testing.T) {
+	app := newTestApp(t)
+	app.Go("panicky", func(ctx context.Context) error { panic("boom") }, NeverRestart)
+	startApp(t, app)
+
+	waitFor(t, "the worker to fail", func() bool { return workerStatus(app, "panicky").State == WorkerFailed })
+	if s := workerStatus(app, "panicky"); s.LastError != "panic: boom" {
+		t.Errorf("last error = %q", s.LastError)
+	}
+	report := app.Health().Check(context.Background())
+	if r := report.Checks["worker:panicky"]; r.Status != health.StatusDown || r.Error != "panic: boom" {
+		t.Errorf("health check = %+v", r)
+	}
+	// The app keeps running
+	if app.State() != StateRunning {
+		t.Errorf("state = %s", app.State())
+	}
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	app := newTestApp(t)
+	var (
+		mu    sync.Mutex
+		began []time.Time
+	)
+	policy := RestartPolicy{Mode: RestartOnFailure, MinBackoff: 10 * time.Millisecond, MaxBackoff: 200 * time.Millisecond}
+	app.Go("flaky", func(ctx context.Context) error {
+		mu.Lock()
+		began = append(began, time.Now())
+		run := len(began)
+		mu.Unlock()
+		switch {
+		case run == 4:
+			// A run longer than MaxBackoff resets the backoff
+			time.Sleep(policy.MaxBackoff + 50*time.Millisecond)
+		case run > 5:
+			<-ctx.Done()
+			return nil
+		}
+		return errors.New("failed")
+	}, policy)
+	startApp(t, app)
+
+	waitFor(t, "six runs", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(began) == 6
+	})
+	mu.Lock()
+	defer mu.Unlock()
+	// Delays double from MinBackoff between runs
+	for i, want := range []time.Duration{10, 20, 40} {
+		if gap := began[i+1].Sub(began[i]); gap < want*time.Millisecond {
+			t.Errorf("restart %d after %s, want at least %dms", i+1, gap, want)
+		}
+	}
+	end4 := began[3].Add(policy.MaxBackoff + 50*time.Millisecond)
+	if gap := began[4].Sub(end4); gap >= 80*time.Millisecond {
+		t.Errorf("restart after a long run waited %s, want the backoff reset to 10ms", gap)
+	}
+	if gap := began[5].Sub(began[4]); gap < 20*time.Millisecond {
+		t.Errorf("restart after the reset waited %s, want at least 20ms", gap)
+	}
+
+	// Every failed run was restarted
+	if s := workerStatus(app, "flaky"); s.Restarts != 5 || s.State != WorkerRunning {
+		t.Errorf("status = %+v", s)
+	}
+}
+
+func TestWorkerUnhealthyWhileBackingOff(t *testing.T) {
+	app := newTestApp(t)
+	app.Go("flaky", func(ctx context.Context) error { return errors.New("broker down") },
+		RestartPolicy{Mode: RestartOnFailure, MinBackoff: time.Hour})
+	startApp(t, app)
+
+	waitFor(t, "the backoff", func() bool { return workerStatus(app, "flaky").State == WorkerBackoff })
+	r := app.Health().Check(context.Background()).Checks["worker:flaky"]
+	if r.Status != health.StatusDown || !strings.Contains(r.Error, "broker down") {
+		t.Errorf("health check = %+v", r)
+	}
+
+	// Stop interrupts the backoff
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s := workerStatus(app, "flaky"); s.State != WorkerFinished {
+		t.Errorf("state after Stop = %s", s.State)
+	}
+}
+
+func TestGo(t *testing.T) {
+	app := newTestApp(t)
+	block := func(ctx context.Context) error { <-ctx.Done(); return nil }
+	if err := app.Go("consumer", block, NeverRestart); err != nil {
+		t.Fatal(err)
+	}
+	if err := app.Go("consumer", block, AlwaysRestart); err == nil || err.Error() != "duplicate worker consumer" {
+		t.Errorf("Go with a duplicate name = %v", err)
+	}
+	startApp(t, app)
+
+	// Workers added while running start at once
+	started := make(chan struct{})
+	app.Go("late", func(ctx context.Context) error { close(started); return block(ctx) }, NeverRestart)
+	<-started
+	if err := app.Go("late", block, NeverRestart); err == nil {
+		t.Error("Go accepted a duplicate name while running")
+	}
+
+	if err := app.Stop(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range app.Workers() {
+		if s.State != WorkerFinished {
+			t.Errorf("%s is %s after Stop", s.Name, s.State)
+		}
+	}
+	assertStateError(t, app.Go("after", block, NeverRestart), "add worker to", StateStopped)
+}
+
+func TestStopWaitsForWorkers(t *testing.T) {
+	app := newTestApp(t)
+	release := make(chan struct{})
+	app.Go("stuck", func(ctx context.Context) error { <-release; return nil }, NeverRestart)
+	startApp(t, app)
+	waitFor(t, "the worker", func() bool { return workerStatus(app, "stuck").State == WorkerRunning })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := app.Stop(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "workers did not stop: [stuck]") {
+		t.Errorf("Stop = %v", err)
+	}
+	close(release)
+}
@@ -0,0 +1,87 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: foundation/v1/options.proto
+
+package foundationv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+var file_foundation_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*bool)(nil),
+		Field:         50000,
+		Name:          "foundation.v1.sensitive",
+		Tag:           "varint,50000,opt,name=sensitive",
+		Filename:      "foundation/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// sensitive marks a field whose value must never appear in logs, span
+	// attributes or error messages. Foundation's redaction replaces it with a
+	// mask wherever a message is logged or recorded.
+	//
+	// optional bool sensitive = 50000;
+	E_Sensitive = &file_foundation_v1_options_proto_extTypes[0]
+)
+
+var File_foundation_v1_options_proto protoreflect.FileDescriptor
+
+const file_foundation_v1_options_proto_rawDesc = "" +
+	"\n" +
+	"\x1bfoundation/v1/options.proto\x12\rfoundation.v1\x1a google/protobuf/descriptor.proto:=\n" +
+	"\tsensitive\x12\x1d.google.protobuf.FieldOptions\x18\xd0\x86\x03 \x01(\bR\tsensitiveB\xb5\x01\n" +
+	"\x11com.foundation.v1B\fOptionsProtoP\x01Z=github.com/yourusername/schema/gen/foundation/v1;foundationv1\xa2\x02\x03FXX\xaa\x02\rFoundation.V1\xca\x02\rFoundation\\V1\xe2\x02\x19Foundation\\V1\\GPBMetadata\xea\x02\x0eFoundation::V1b\x06proto3"
+
+var file_foundation_v1_options_proto_goTypes = []any{
+	(*descriptorpb.FieldOptions)(nil), // 0: google.protobuf.FieldOptions
+}
+var file_foundation_v1_options_proto_depIdxs = []int32{
+	0, // 0: foundation.v1.sensitive:extendee -> google.protobuf.FieldOptions
+	1, // [1:1] is the sub-list for method output_type
+	1, // [1:1] is the sub-list for method input_type
+	1, // [1:1] is the sub-list for extension type_name
+	0, // [0:1] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_foundation_v1_options_proto_init() }
+func file_foundation_v1_options_proto_init() {
+	if File_foundation_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_foundation_v1_options_proto_rawDesc), len(file_foundation_v1_options_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   0,
+			NumExtensions: 1,
+			NumServices:   0,
+		},
+		GoTypes:           file_foundation_v1_options_proto_goTypes,
+		DependencyIndexes: file_foundation_v1_options_proto_depIdxs,
+		ExtensionInfos:    file_foundation_v1_options_proto_extTypes,
+	}.Build()
+	File_foundation_v1_options_proto = out.File
+	file_foundation_v1_options_proto_goTypes = nil
+	file_foundation_v1_options_proto_depIdxs = nil
+}
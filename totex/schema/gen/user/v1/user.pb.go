@@ -7,6 +7,7 @@
 package userv1
 
 import (
+	_ "github.com/yourusername/schema/gen/foundation/v1"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	reflect "reflect"
@@ -155,11 +156,11 @@ var File_user_v1_user_proto protoreflect.FileDescriptor
 
 const file_user_v1_user_proto_rawDesc = "" +
 	"\n" +
-	"\x12user/v1/user.proto\x12\auser.v1\"Y\n" +
+	"\x12user/v1/user.proto\x12\auser.v1\x1a\x1bfoundation/v1/options.proto\"_\n" +
 	"\x11CreateUserRequest\x12\x14\n" +
 	"\x05email\x18\x01 \x01(\tR\x05email\x12\x12\n" +
-	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1a\n" +
-	"\bpassword\x18\x03 \x01(\tR\bpassword\"v\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\bpassword\x18\x03 \x01(\tB\x04\x80\xb5\x18\x01R\bpassword\"v\n" +
 	"\x12CreateUserResponse\x12\x17\n" +
 	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x14\n" +
 	"\x05email\x18\x02 \x01(\tR\x05email\x12\x12\n" +
@@ -6,6 +6,7 @@ import (
 	"os/signal"
 	"syscall"
 
+	connect "github.com/bufbuild/connect-go"
 	foundation "github.com/yourusername/foundation"
 	userv1connect "github.com/yourusername/schema/gen/user/v1/userv1connect"
 	"github.com/yourusername/user-service/internal/user"
@@ -20,10 +21,6 @@ func main() {
 	app := foundation.New("user-service", "1.0.0")
 	logger := app.Logger()
 
-	// User service implementation (no DB)
-	userSvc := user.NewService()
-	path, handler := userv1connect.NewUserServiceHandler(userSvc)
-
 	// Get the automatically created ConnectRPC server
 	connectServer := app.ConnectRPC()
 	if connectServer == nil {
@@ -31,6 +28,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// User service implementation (no DB), reporting its error codes to the instrumentation
+	userSvc := user.NewService()
+	path, handler := userv1connect.NewUserServiceHandler(userSvc,
+		connect.WithInterceptors(connectServer.Interceptor()))
+
 	// Register handler with the auto-created server
 	if err := connectServer.RegisterHandler(path, handler); err != nil {
 		logger.Error("Failed to register ConnectRPC handler", "error", err)
@@ -45,11 +47,15 @@ func main() {
 
 	logger.Info("User service started successfully")
 
-	// Graceful shutdown on SIGINT/SIGTERM
+	// Graceful shutdown on SIGINT/SIGTERM, or once an upgraded process has taken over
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
-	sig := <-sigChan
-	logger.Info("Shutdown signal received", "signal", sig)
+	select {
+	case sig := <-sigChan:
+		logger.Info("Shutdown signal received", "signal", sig)
+	case <-app.Upgraded():
+		logger.Info("Upgraded process took over, draining")
+	}
 	if err := app.Stop(context.Background()); err != nil {
 		logger.Error("Error during shutdown", "error", err)
 		os.Exit(1)